@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+type AccountParticipation struct {
+	WorkspaceId   int           `json:"workspaceId" db:"workspace_id"`
+	WorkspaceName string        `json:"workspaceName" db:"workspace_name"`
+	Role          WorkspaceRole `json:"role" db:"role"`
+	JoinedAt      time.Time     `json:"joinedAt" db:"joined_at"`
+}
+
+type AccountSubmission struct {
+	Id             int              `json:"id" db:"id"`
+	WorkspaceId    int              `json:"workspaceId" db:"workspace_id"`
+	AssignmentId   int              `json:"assignmentId" db:"assignment_id"`
+	AssignmentName string           `json:"assignmentName" db:"assignment_name"`
+	Language       string           `json:"language" db:"language"`
+	Status         AssignmentStatus `json:"status" db:"status"`
+	Score          float64          `json:"score" db:"score"`
+	FileUrl        string           `json:"fileUrl" db:"file_url"`
+	SubmittedAt    time.Time        `json:"submittedAt" db:"submitted_at"`
+	IsLate         bool             `json:"isLate" db:"is_late"`
+}
+
+type AccountRepository interface {
+	ListParticipation(userId string) ([]AccountParticipation, error)
+	ListSubmission(userId string) ([]AccountSubmission, error)
+	Anonymize(user *User, deletedAt time.Time) error
+}
+
+type AccountUsecase interface {
+	Export(userId string, w io.Writer) error
+	Delete(userId string, password string) error
+	Anonymize(userId string) error
+}
@@ -0,0 +1,25 @@
+package domain
+
+// AdminWorkspace is a workspace as seen by a site admin, including its soft-deleted assignments
+type AdminWorkspace struct {
+	RawWorkspace
+	IsDeleted    bool                   `json:"isDeleted"`
+	Participants []WorkspaceParticipant `json:"participants"`
+	Assignments  []AdminAssignment      `json:"assignments"`
+}
+
+type AdminAssignment struct {
+	Assignment
+	IsDeleted bool `json:"isDeleted"`
+}
+
+type AdminUsecase interface {
+	SearchUser(query string, limit int, offset int) ([]User, error)
+	DisableUser(adminId string, userId string) error
+	EnableUser(userId string) error
+	SignOutUser(userId string) error
+	DeleteUser(adminId string, userId string) error
+	GetWorkspace(id int) (*AdminWorkspace, error)
+	RestoreWorkspace(id int) error
+	RestoreAssignment(workspaceId int, assignmentId int) error
+}
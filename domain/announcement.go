@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// Announcement is posted to every participant of a workspace once its
+// publish date is reached, the body is written in markdown
+type Announcement struct {
+	Id               int        `json:"id" db:"id"`
+	WorkspaceId      int        `json:"workspaceId" db:"workspace_id"`
+	AuthorId         string     `json:"authorId" db:"author_id"`
+	AuthorName       string     `json:"authorName" db:"author_name"`
+	AuthorProfileUrl string     `json:"authorProfileUrl" db:"author_profile_url"`
+	Title            string     `json:"title" db:"title"`
+	Body             string     `json:"body" db:"body"`
+	IsPinned         bool       `json:"isPinned" db:"is_pinned"`
+	PublishDate      time.Time  `json:"publishDate" db:"publish_date"`
+	NotifiedAt       *time.Time `json:"-" db:"notified_at"`
+	CreatedAt        time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updatedAt" db:"updated_at"`
+
+	// Depends on the user reading the announcement
+	IsRead bool `json:"isRead" db:"is_read"`
+}
+
+func (a *Announcement) IsPublished() bool {
+	return !a.PublishDate.After(time.Now())
+}
+
+type CreateAnnouncement struct {
+	Title       string
+	Body        string
+	IsPinned    bool
+	PublishDate *time.Time // Published right away when nil
+}
+
+type UpdateAnnouncement struct {
+	Title       *string
+	Body        *string
+	PublishDate *time.Time
+}
+
+type AnnouncementRepository interface {
+	Create(announcement *Announcement) error
+	Get(id int, userId string) (*Announcement, error)
+	List(workspaceId int, userId string, includeScheduled bool, limit int, offset int) ([]Announcement, error)
+	ListDue(before time.Time) ([]Announcement, error)
+	CountUnread(workspaceId int, userId string) (int, error)
+	Update(announcement *Announcement) error
+	MarkNotified(id int, notifiedAt time.Time) (bool, error)
+	MarkRead(id int, userId string) error
+	Delete(id int) error
+}
+
+type AnnouncementUsecase interface {
+	Create(userId string, workspaceId int, ca *CreateAnnouncement) (*Announcement, error)
+	Get(userId string, workspaceId int, id int) (*Announcement, error)
+	List(userId string, workspaceId int, limit int, offset int) ([]Announcement, error)
+	CountUnread(userId string, workspaceId int) (int, error)
+	Update(userId string, workspaceId int, id int, ua *UpdateAnnouncement) error
+	Pin(userId string, workspaceId int, id int, isPinned bool) error
+	Delete(userId string, workspaceId int, id int) error
+	MarkRead(userId string, workspaceId int, id int) error
+	PublishDue() (int, error)
+}
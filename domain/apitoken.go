@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+type ApiTokenScope string
+
+const (
+	ReadScope  ApiTokenScope = "READ"
+	WriteScope ApiTokenScope = "WRITE" // Includes read
+)
+
+type ApiToken struct {
+	Id         int           `json:"id" db:"id"`
+	UserId     string        `json:"-" db:"user_id"`
+	Name       string        `json:"name" db:"name"`
+	Hash       string        `json:"-" db:"token_hash"`
+	Hint       string        `json:"hint" db:"hint"`
+	Scope      ApiTokenScope `json:"scope" db:"scope"`
+	CreatedAt  time.Time     `json:"createdAt" db:"created_at"`
+	ExpiredAt  *time.Time    `json:"expiredAt" db:"expired_at"`
+	LastUsedAt *time.Time    `json:"lastUsedAt" db:"last_used_at"`
+
+	// Plain token only available right after the token is created
+	Token string `json:"token,omitempty" db:"-"`
+}
+
+type ApiTokenRepository interface {
+	Create(token *ApiToken) error
+	Get(id int) (*ApiToken, error)
+	GetByHash(hash string) (*ApiToken, error)
+	ListByUserId(userId string) ([]ApiToken, error)
+	CountByUserId(userId string) (int, error)
+	UpdateLastUsed(id int, usedAt time.Time) error
+	Delete(id int) error
+}
+
+type ApiTokenUsecase interface {
+	Create(userId string, name string, scope ApiTokenScope, expiredAt *time.Time) (*ApiToken, error)
+	List(userId string) ([]ApiToken, error)
+	Revoke(userId string, id int) error
+	Validate(token string) (*ApiToken, error)
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// WorkspaceArchiveVersion is bumped whenever the manifest changes in a way
+// older instances cannot read, archives of a newer version are refused
+const WorkspaceArchiveVersion = 1
+
+const WorkspaceArchiveManifest = "manifest.json"
+
+// WorkspaceArchive is the manifest of an exported workspace, the files it
+// points to are stored next to it in the zip archive
+type WorkspaceArchive struct {
+	Version     int                 `json:"version"`
+	ExportedAt  time.Time           `json:"exportedAt"`
+	Workspace   ArchiveWorkspace    `json:"workspace"`
+	Assignments []ArchiveAssignment `json:"assignments"`
+}
+
+type ArchiveWorkspace struct {
+	Name             string  `json:"name"`
+	ProfileFile      *string `json:"profileFile"`
+	IsOpenScoreboard bool    `json:"isOpenScoreboard"`
+	IsJoinApproval   bool    `json:"isJoinApproval"`
+	IsDiscoverable   bool    `json:"isDiscoverable"`
+}
+
+type ArchiveAssignment struct {
+	Name              string              `json:"name"`
+	Description       string              `json:"description"`
+	DetailFile        string              `json:"detailFile"`
+	MemoryLimit       int                 `json:"memoryLimit"`
+	TimeLimit         int                 `json:"timeLimit"`
+	Level             AssignmentLevel     `json:"level"`
+	PublishDate       time.Time           `json:"publishDate"`
+	DueDate           *time.Time          `json:"dueDate"`
+	IsAutoTrimEnabled bool                `json:"isAutoTrimEnabled"`
+	Testcases         []ArchiveTestcase   `json:"testcases"`
+	Submissions       []ArchiveSubmission `json:"submissions,omitempty"`
+}
+
+type ArchiveTestcase struct {
+	InputFile  string `json:"inputFile"`
+	OutputFile string `json:"outputFile"`
+}
+
+// ArchiveSubmission is kept for the record only, the submitters belong to
+// the instance the archive was exported from so they are not imported
+type ArchiveSubmission struct {
+	SubmitterId   string           `json:"submitterId"`
+	SubmitterName string           `json:"submitterName"`
+	Language      string           `json:"language"`
+	Status        AssignmentStatus `json:"status"`
+	Score         float64          `json:"score"`
+	SubmittedAt   time.Time        `json:"submittedAt"`
+	File          string           `json:"file"`
+}
+
+// ImportReport describes what an import creates, or would create on a dry run
+type ImportReport struct {
+	Version            int           `json:"version"`
+	DryRun             bool          `json:"dryRun"`
+	WorkspaceName      string        `json:"workspaceName"`
+	Assignments        int           `json:"assignments"`
+	Testcases          int           `json:"testcases"`
+	TestcaseBytes      int64         `json:"testcaseBytes"`
+	SkippedSubmissions int           `json:"skippedSubmissions"`
+	Problems           []string      `json:"problems"`
+	Workspace          *RawWorkspace `json:"workspace"`
+}
+
+type ArchiveUsecase interface {
+	Export(userId string, workspaceId int, includeSubmissions bool, w io.Writer) error
+	Import(userId string, name string, archive io.ReaderAt, size int64, dryRun bool) (*ImportReport, error)
+}
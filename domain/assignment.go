@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"bytes"
 	"io"
 	"mime/multipart"
 	"time"
@@ -20,6 +21,11 @@ const (
 	AssignmentHardLevel   AssignmentLevel = "HARD"
 )
 
+func (l AssignmentLevel) IsValid() bool {
+	_, ok := assignmentScoreMap[l]
+	return ok
+}
+
 type AssignmentStatus string
 
 const (
@@ -44,6 +50,7 @@ type Assignment struct {
 	IsAutoTrimEnabled bool            `json:"isAutoTrimEnabled" db:"is_auto_trim_enabled"`
 	DueDate           *time.Time      `json:"dueDate" db:"due_date"`
 	IsDeleted         bool            `json:"-" db:"is_deleted"`
+	DeletedAt         *time.Time      `json:"-" db:"deleted_at"`
 
 	// Always aggregation
 	Testcases []Testcase `json:"testcases"`
@@ -63,6 +70,8 @@ type CreateAssignment struct {
 	DueDate       *time.Time
 	DetailFile    *File
 	TestcaseFiles []TestcaseFile
+
+	IsAutoTrimEnabled bool
 }
 
 type UpdateAssignment struct {
@@ -119,11 +128,57 @@ type Testcase struct {
 	Revision      int    `json:"-" db:"revision"`
 	InputFileUrl  string `json:"inputFileUrl" db:"input_file_url"`
 	OutputFileUrl string `json:"outputFileUrl" db:"output_file_url"`
+	Size          int64  `json:"-" db:"size"` // Input and output in byte unit
 }
 
 type TestcaseFile struct {
 	Input  io.Reader
 	Output io.Reader
+	Size   int64 // Set by Measure
+}
+
+// SizedReader is a reader that knows its size up front, such as an archive entry,
+// so it can be measured without being read
+type SizedReader struct {
+	io.Reader
+	Size int64
+}
+
+// Measure sets the total size of the input and output, readers that cannot seek
+// and do not know their size are buffered in memory so they can still be read afterward
+func (f *TestcaseFile) Measure() error {
+	inputSize, input, err := measureReader(f.Input)
+	if err != nil {
+		return err
+	}
+	outputSize, output, err := measureReader(f.Output)
+	if err != nil {
+		return err
+	}
+	f.Input, f.Output, f.Size = input, output, inputSize+outputSize
+	return nil
+}
+
+func measureReader(reader io.Reader) (int64, io.Reader, error) {
+	if sized, ok := reader.(*SizedReader); ok {
+		return sized.Size, sized, nil
+	}
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		size, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, nil, err
+		}
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return 0, nil, err
+		}
+		return size, seeker, nil
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	return int64(len(data)), bytes.NewReader(data), nil
 }
 
 func CreateTestcaseFiles(inputs []multipart.File, outputs []multipart.File) []TestcaseFile {
@@ -138,18 +193,20 @@ func CreateTestcaseFiles(inputs []multipart.File, outputs []multipart.File) []Te
 }
 
 type AssignmentRepository interface {
-	Create(assignment *Assignment) error
+	Create(assignment *Assignment, maxAssignments int) (bool, error)
 	Update(assignment *Assignment) error
 	Delete(id int) error
-	CreateTestcases(testcases []Testcase) error
+	Restore(id int, maxAssignments int) (bool, error)
+	CreateTestcases(testcases []Testcase, maxBytes int64) (bool, error)
 	DeleteTestcases(assignmentId int) error
-	CreateSubmission(submission *Submission, testcases []Testcase) error
+	CreateSubmission(submission *Submission, testcases []Testcase, maxSubmissions int, since time.Time) (bool, error)
 	CreateSubmissionResults(submissionId int, compilationLog string, status AssignmentStatus, score float64, results []SubmissionResult) error
 	Get(id int) (*Assignment, error)
 	GetWithStatus(id int, userId string) (*AssignmentWithStatus, error)
 	GetSubmission(id int) (*Submission, error)
 	List(userId string, workspaceId int) ([]AssignmentWithStatus, error)
-	ListSubmission(userId *string, assignmentId *int) ([]Submission, error)
+	ListSubmission(userId *string, assignmentId *int, groupIds []int) ([]Submission, error)
+	ListWithDeleted(workspaceId int) ([]Assignment, error)
 }
 
 type AssignmentUsecase interface {
@@ -165,5 +222,6 @@ type AssignmentUsecase interface {
 	GetSubmission(id int) (*Submission, error)
 	List(userId string, workspaceId int) ([]AssignmentWithStatus, error)
 	ListSubmission(userId string, assignmentId int) ([]Submission, error)
-	ListAllSubmission(userId string, workspaceId int, assignmentId int) ([]Submission, error)
+	ListAllSubmission(userId string, workspaceId int, assignmentId int, groupId *int) ([]Submission, error)
+	CanViewSubmission(userId string, workspaceId int, assignmentId int, submissionId int) (bool, error)
 }
@@ -4,7 +4,11 @@ import "github.com/gofiber/fiber/v2"
 
 type AuthUsecase interface {
 	Authenticate(header string) (*User, error)
-	SignIn(email string, password string, ipAddress string, userAgent string) (*fiber.Cookie, error)
+	AuthenticateApiToken(token string) (*User, *ApiToken, error)
+	SignIn(email string, password string, ipAddress string, userAgent string) (*fiber.Cookie, *TwoFactorChallenge, error)
+	SignInWithTwoFactor(token string, code string, ipAddress string, userAgent string) (*fiber.Cookie, error)
+	SignUp(email string, password string, ipAddress string, userAgent string) (*fiber.Cookie, error)
 	SignInWithGoogle(code string, ipAddress string, userAgent string) (*fiber.Cookie, error)
+	SignInWithOidc(provider AuthProvider, code string, state string, ipAddress string, userAgent string) (*fiber.Cookie, error)
 	SignOut(header string) (*fiber.Cookie, error)
 }
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// CloneWorkspace describes a new term of an existing workspace
+type CloneWorkspace struct {
+	Name          string
+	DateOffset    time.Duration // Added to the publish and due date of every assignment
+	IncludeAdmins bool
+}
+
+type CloneUsecase interface {
+	Clone(userId string, workspaceId int, clone *CloneWorkspace) (*RawWorkspace, error)
+}
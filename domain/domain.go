@@ -13,6 +13,7 @@ type Platform struct {
 	SeaweedFs    *platform.SeaweedFs
 	RabbitMq     *platform.RabbitMq
 	WebSocketHub *platform.WebSocketHub
+	MailSender   platform.MailSender
 }
 
 type Repository struct {
@@ -21,6 +22,24 @@ type Repository struct {
 	Workspace  WorkspaceRepository
 	Assignment AssignmentRepository
 	Survey     SurveyRepository
+
+	EmailVerification EmailVerificationRepository
+	PasswordReset     PasswordResetRepository
+	TwoFactor         TwoFactorRepository
+	ApiToken          ApiTokenRepository
+	Oidc              OidcRepository
+	Identity          IdentityRepository
+	SignInAttempt     SignInAttemptRepository
+	Quota             QuotaRepository
+	Impersonation     ImpersonationRepository
+	Account           AccountRepository
+	Roster            RosterRepository
+	Group             GroupRepository
+	CustomRole        CustomRoleRepository
+	JoinRequest       JoinRequestRepository
+	OwnershipTransfer OwnershipTransferRepository
+	Trash             TrashRepository
+	Announcement      AnnouncementRepository
 }
 
 type Usecase struct {
@@ -31,6 +50,27 @@ type Usecase struct {
 	Workspace  WorkspaceUsecase
 	Assignment AssignmentUsecase
 	Survey     SurveyUsecase
+
+	EmailVerification EmailVerificationUsecase
+	PasswordReset     PasswordResetUsecase
+	TwoFactor         TwoFactorUsecase
+	ApiToken          ApiTokenUsecase
+	Oidc              OidcUsecase
+	Identity          IdentityUsecase
+	SignInThrottle    SignInThrottleUsecase
+	Admin             AdminUsecase
+	Quota             QuotaUsecase
+	Impersonation     ImpersonationUsecase
+	Account           AccountUsecase
+	Roster            RosterUsecase
+	Group             GroupUsecase
+	CustomRole        CustomRoleUsecase
+	JoinRequest       JoinRequestUsecase
+	OwnershipTransfer OwnershipTransferUsecase
+	Clone             CloneUsecase
+	Archive           ArchiveUsecase
+	Trash             TrashUsecase
+	Announcement      AnnouncementUsecase
 }
 
 type Publisher struct {
@@ -21,18 +21,102 @@ const (
 	ErrCreateSession     = 2005
 	ErrGetSession        = 2006
 	ErrUnauthenticated   = 2007
+	ErrSessionNotFound   = 2008
+	ErrDeleteSession     = 2009
 	ErrInvalidEmail      = 2010
 	ErrDupEmail          = 2011
+	ErrEmailDomain       = 2012
+	ErrEmailNotVerified  = 2013
+	ErrEmailVerified     = 2014
+	ErrUpdateSession     = 2015
 	ErrUserPassword      = 2020
+	ErrWeakPassword      = 2021
 	ErrUserNotFound      = 2030
 	ErrGetUser           = 2031
 	ErrCreateUser        = 2032
 	ErrUpdateUser        = 2033
 	ErrGoogleAuth        = 2040
+	ErrOidcAuth          = 2041
+	ErrOidcProvider      = 2042
+	ErrOidcState         = 2043
+	ErrOidcClaim         = 2044
+
+	ErrVerificationToken   = 2050
+	ErrVerificationExpired = 2051
+	ErrCreateVerification  = 2052
+	ErrVerifyEmail         = 2053
+
+	ErrPasswordResetLimit  = 2060
+	ErrCreatePasswordReset = 2061
+	ErrResetPassword       = 2062
+
+	ErrTwoFactorCode       = 2070
+	ErrTwoFactorEnabled    = 2071
+	ErrTwoFactorNotEnabled = 2072
+	ErrTwoFactorChallenge  = 2073
+	ErrTwoFactorProvider   = 2074
+	ErrUpdateTwoFactor     = 2075
+	ErrCreateChallenge     = 2076
+	ErrGetChallenge        = 2077
+	ErrUpdateChallenge     = 2078
+	ErrDeleteChallenge     = 2079
+
+	ErrApiTokenInvalid       = 2080
+	ErrApiTokenExpired       = 2081
+	ErrApiTokenScope         = 2082
+	ErrApiTokenNotFound      = 2083
+	ErrApiTokenLimit         = 2084
+	ErrInvalidApiTokenScope  = 2085
+	ErrInvalidApiTokenExpiry = 2086
+	ErrCreateApiToken        = 2087
+	ErrGetApiToken           = 2088
+	ErrDeleteApiToken        = 2089
+	ErrSessionRequired       = 2090
+
+	ErrIdentityLinked     = 2100
+	ErrIdentityNotFound   = 2101
+	ErrIdentityPrimary    = 2102
+	ErrIdentityProvider   = 2103
+	ErrCreateIdentity     = 2104
+	ErrGetIdentity        = 2105
+	ErrDeleteIdentity     = 2106
+	ErrIdentityUnverified = 2107
+
+	ErrSignInLocked       = 2110
+	ErrCheckSignInAttempt = 2111
+	ErrListSignInAttempt  = 2112
+
+	ErrAdminRequired   = 2120
+	ErrUserDisabled    = 2121
+	ErrAdminSelf       = 2122
+	ErrSearchUser      = 2123
+	ErrUpdateUserState = 2124
+
+	ErrImpersonationInvalid  = 2130
+	ErrImpersonationReadOnly = 2131
+	ErrImpersonationScope    = 2132
+	ErrImpersonationTarget   = 2133
+	ErrCreateImpersonation   = 2134
+	ErrGetImpersonation      = 2135
+
+	ErrAccountOwnsWorkspace = 2140
+	ErrDeleteAccount        = 2141
+	ErrExportAccount        = 2142
 
 	ErrGradingRequest = 4000
 
-	ErrFilePerm = 5000
+	ErrFilePerm     = 5000
+	ErrInvalidImage = 5001
+	ErrProcessImage = 5002
+
+	ErrSendMail = 6000
+
+	ErrWorkspaceQuota   = 7000
+	ErrParticipantQuota = 7001
+	ErrAssignmentQuota  = 7002
+	ErrTestcaseQuota    = 7003
+	ErrSubmissionQuota  = 7004
+	ErrGetUsage         = 7005
 
 	ErrCreateUrlPath = 9000
 
@@ -53,6 +137,11 @@ const (
 	ErrUpdateWorkspace            = 30014
 	ErrDeleteWorkspace            = 30015
 	ErrWorkspaceAlreadyJoin       = 30016
+	ErrRestoreWorkspace           = 30017
+	ErrCloneWorkspace             = 30018
+	ErrExportWorkspace            = 30019
+	ErrImportWorkspace            = 30020
+	ErrInvalidArchive             = 30021
 
 	ErrCreateInvitation      = 31000
 	ErrGetInvitation         = 31001
@@ -60,6 +149,48 @@ const (
 	ErrInvitationNotFound    = 31003
 	ErrInvitationNoPerm      = 31004
 	ErrInvitationInvalidDate = 31005
+	ErrRosterFormat          = 31006
+	ErrRosterTooLarge        = 31007
+	ErrPendingInvitation     = 31008
+	ErrPendingNotFound       = 31009
+	ErrInvitationExhausted   = 31010
+	ErrInvitationEmail       = 31011
+	ErrInvitationRedemption  = 31012
+
+	ErrGroupNotFound    = 32000
+	ErrGetGroup         = 32001
+	ErrCreateGroup      = 32002
+	ErrUpdateGroup      = 32003
+	ErrDeleteGroup      = 32004
+	ErrGroupMember      = 32005
+	ErrGroupInvalidRole = 32006
+	ErrGroupSchedule    = 32007
+	ErrGroupInvalidDate = 32008
+
+	ErrCustomRoleNotFound = 33000
+	ErrCustomRole         = 33001
+	ErrCustomRoleInUse    = 33002
+	ErrInvalidPermission  = 33003
+	ErrCustomRoleTarget   = 33004
+
+	ErrJoinRequestNotFound      = 34000
+	ErrJoinRequest              = 34001
+	ErrJoinRequestPending       = 34002
+	ErrJoinRequestReviewed      = 34003
+	ErrWorkspaceNotDiscoverable = 34004
+
+	ErrOwnershipTransferNotFound = 35000
+	ErrOwnershipTransfer         = 35001
+	ErrOwnershipTransferPending  = 35002
+	ErrOwnershipTransferExpired  = 35003
+	ErrOwnershipTransferTarget   = 35004
+
+	ErrTrashNotFound = 36000
+	ErrTrash         = 36001
+	ErrTrashExpired  = 36002
+
+	ErrAnnouncementNotFound = 37000
+	ErrAnnouncement         = 37001
 
 	ErrGetAssignment        = 40000
 	ErrListAssignment       = 40001
@@ -67,6 +198,7 @@ const (
 	ErrAssignmentNoTestcase = 40003
 	ErrCreateAssignment     = 40004
 	ErrUpdateAssignment     = 40005
+	ErrRestoreAssignment    = 40006
 
 	ErrCreateSubmission       = 41000
 	ErrCreateSubmissionResult = 41001
@@ -16,7 +16,8 @@ type GoogleUserResponse struct {
 }
 
 type GoogleUsecase interface {
-	GetOAuthUrl() string
+	GetAuthRequest() (*OidcAuthRequest, error)
+	VerifyState(state string) error
 	GetToken(code string) (string, error)
 	GetUser(accessToken string) (*GoogleUserResponse, error)
 }
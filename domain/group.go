@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// WorkspaceGroup splits the participants of a workspace into sections,
+// each with its own schedule and managing admins
+type WorkspaceGroup struct {
+	Id          int       `json:"id" db:"id"`
+	WorkspaceId int       `json:"workspaceId" db:"workspace_id"`
+	Name        string    `json:"name" db:"name"`
+	MemberCount int       `json:"memberCount" db:"member_count"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+type GroupMember struct {
+	UserId     string        `json:"userId" db:"user_id"`
+	Name       string        `json:"name" db:"name"`
+	ProfileUrl string        `json:"profileUrl" db:"profile_url"`
+	Role       WorkspaceRole `json:"role" db:"role"`
+	IsManager  bool          `json:"isManager" db:"is_manager"`
+}
+
+// GroupSchedule overrides the dates of an assignment for the members of a group
+type GroupSchedule struct {
+	AssignmentId int        `json:"assignmentId" db:"assignment_id"`
+	GroupId      int        `json:"groupId" db:"group_id"`
+	PublishDate  *time.Time `json:"publishDate" db:"publish_date"`
+	DueDate      *time.Time `json:"dueDate" db:"due_date"`
+}
+
+// Apply replaces the dates of the assignment with the ones set by the schedule
+func (s *GroupSchedule) Apply(assignment *Assignment) {
+	if s.PublishDate != nil {
+		assignment.PublishDate = *s.PublishDate
+	}
+	if s.DueDate != nil {
+		assignment.DueDate = s.DueDate
+	}
+}
+
+type GroupRepository interface {
+	Create(group *WorkspaceGroup) error
+	Get(id int) (*WorkspaceGroup, error)
+	List(workspaceId int) ([]WorkspaceGroup, error)
+	Update(group *WorkspaceGroup) error
+	Delete(id int) error
+	ListMember(groupId int) ([]GroupMember, error)
+	SetMember(workspaceId int, groupId int, userId string) error
+	DeleteMember(groupId int, userId string) error
+	CreateManager(groupId int, userId string) error
+	DeleteManager(groupId int, userId string) error
+	ListManagedGroupId(userId string, workspaceId int) ([]int, error)
+	SetSchedule(schedule *GroupSchedule) error
+	DeleteSchedule(groupId int, assignmentId int) error
+	ListSchedule(groupId int) ([]GroupSchedule, error)
+	ListScheduleByUser(userId string, workspaceId int) ([]GroupSchedule, error)
+}
+
+type GroupUsecase interface {
+	Create(userId string, workspaceId int, name string) (*WorkspaceGroup, error)
+	List(userId string, workspaceId int) ([]WorkspaceGroup, error)
+	Update(userId string, workspaceId int, groupId int, name string) error
+	Delete(userId string, workspaceId int, groupId int) error
+	ListMember(userId string, workspaceId int, groupId int) ([]GroupMember, error)
+	AddMember(userId string, workspaceId int, groupId int, memberId string) error
+	RemoveMember(userId string, workspaceId int, groupId int, memberId string) error
+	AddManager(userId string, workspaceId int, groupId int, managerId string) error
+	RemoveManager(userId string, workspaceId int, groupId int, managerId string) error
+	SetSchedule(userId string, workspaceId int, schedule *GroupSchedule) error
+	DeleteSchedule(userId string, workspaceId int, groupId int, assignmentId int) error
+	ListSchedule(userId string, workspaceId int, groupId int) ([]GroupSchedule, error)
+	GetScheduleByUser(userId string, workspaceId int) (map[int]GroupSchedule, error)
+	GetManagedScope(userId string, workspaceId int) ([]int, error)
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// UserIdentity is an external provider account that can be used to sign in to a user,
+// the provider the user signed up with is the primary identity
+type UserIdentity struct {
+	Provider        AuthProvider `json:"provider" db:"provider"`
+	Subject         *string      `json:"-" db:"subject"` // Nil for identities stored before subjects were recorded
+	Email           string       `json:"email" db:"email"`
+	IsEmailVerified bool         `json:"isEmailVerified" db:"is_email_verified"`
+	UserId          string       `json:"-" db:"user_id"`
+	CreatedAt       time.Time    `json:"createdAt" db:"created_at"`
+	IsPrimary       bool         `json:"isPrimary" db:"-"`
+}
+
+type IdentityRepository interface {
+	Create(identity *UserIdentity) error
+	Get(provider AuthProvider, subject string) (*UserIdentity, error)
+	GetWithoutSubject(provider AuthProvider, email string) (*UserIdentity, error)
+	ListByUserId(userId string) ([]UserIdentity, error)
+	UpdateSubject(provider AuthProvider, email string, subject string) error
+	UpdateEmail(provider AuthProvider, subject string, email string, isEmailVerified bool) error
+	Delete(userId string, provider AuthProvider, email string) error
+}
+
+type IdentityUsecase interface {
+	GetUser(provider AuthProvider, subject string, email string) (*User, error)
+	List(userId string) ([]UserIdentity, error)
+	Link(userId string, provider AuthProvider, subject string, email string, isEmailVerified bool) error
+	LinkGoogle(userId string, code string, state string) error
+	LinkOidc(userId string, provider AuthProvider, code string, state string) error
+	Unlink(userId string, provider AuthProvider, email string) error
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Impersonation lets a workspace owner or admin view the workspace as one of its members,
+// the impersonated requests are read-only and scoped to the workspace
+type Impersonation struct {
+	Id             string     `json:"-" db:"id"`
+	ImpersonatorId string     `json:"impersonatorId" db:"impersonator_id"`
+	UserId         string     `json:"userId" db:"user_id"`
+	WorkspaceId    int        `json:"workspaceId" db:"workspace_id"`
+	IpAddress      string     `json:"ipAddress" db:"ip_address"`
+	UserAgent      string     `json:"userAgent" db:"user_agent"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	ExpiredAt      time.Time  `json:"expiredAt" db:"expired_at"`
+	EndedAt        *time.Time `json:"endedAt" db:"ended_at"`
+}
+
+type ImpersonationLog struct {
+	Id              int       `json:"id" db:"id"`
+	ImpersonationId string    `json:"-" db:"impersonation_id"`
+	Method          string    `json:"method" db:"method"`
+	Path            string    `json:"path" db:"path"`
+	IsBlocked       bool      `json:"isBlocked" db:"is_blocked"`
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+}
+
+type ImpersonationRepository interface {
+	Create(impersonation *Impersonation) error
+	CreateLog(log *ImpersonationLog) error
+	Get(id string) (*Impersonation, error)
+	ListByWorkspaceId(workspaceId int) ([]Impersonation, error)
+	End(id string, endedAt time.Time) error
+}
+
+type ImpersonationUsecase interface {
+	Start(
+		impersonatorId string, workspaceId int, userId string, ipAddress string, userAgent string,
+	) (*fiber.Cookie, error)
+	Authenticate(header string, impersonatorId string) (*User, *Impersonation, error)
+	Stop(header string) (*fiber.Cookie, error)
+	Log(impersonationId string, method string, path string, isBlocked bool) error
+	List(userId string, workspaceId int) ([]Impersonation, error)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+type JoinRequestStatus string
+
+const (
+	PendingJoinRequest  JoinRequestStatus = "PENDING"
+	ApprovedJoinRequest JoinRequestStatus = "APPROVED"
+	RejectedJoinRequest JoinRequestStatus = "REJECTED"
+)
+
+// JoinRequest is created instead of a participant when a workspace requires
+// its joins to be approved, the invitation is redeemed once approved
+type JoinRequest struct {
+	Id              int               `json:"id" db:"id"`
+	WorkspaceId     int               `json:"workspaceId" db:"workspace_id"`
+	UserId          string            `json:"userId" db:"user_id"`
+	UserDisplayName string            `json:"userDisplayName" db:"user_display_name"`
+	UserProfileUrl  string            `json:"userProfileUrl" db:"user_profile_url"`
+	InvitationId    *string           `json:"invitationId" db:"invitation_id"`
+	Role            WorkspaceRole     `json:"role" db:"role"`
+	Status          JoinRequestStatus `json:"status" db:"status"`
+	CreatedAt       time.Time         `json:"createdAt" db:"created_at"`
+	ReviewerId      *string           `json:"reviewerId" db:"reviewer_id"`
+	ReviewedAt      *time.Time        `json:"reviewedAt" db:"reviewed_at"`
+}
+
+type JoinRequestRepository interface {
+	Create(request *JoinRequest) error
+	Get(id int) (*JoinRequest, error)
+	GetPending(userId string, workspaceId int) (*JoinRequest, error)
+	List(workspaceId int, status *JoinRequestStatus) ([]JoinRequest, error)
+	Review(request *JoinRequest) (bool, error)
+	Reopen(id int) error
+}
+
+type JoinRequestUsecase interface {
+	Join(userId string, invitationCode string) (*Workspace, *JoinRequest, error)
+	Request(userId string, workspaceId int) (*JoinRequest, error)
+	List(userId string, workspaceId int, status *JoinRequestStatus) ([]JoinRequest, error)
+	Approve(userId string, workspaceId int, id int) error
+	Reject(userId string, workspaceId int, id int) error
+}
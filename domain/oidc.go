@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+type OidcProvider struct {
+	Provider AuthProvider `json:"provider"`
+	Name     string       `json:"name"`
+}
+
+type OidcAuthRequest struct {
+	Url       string    `json:"url"`
+	State     string    `json:"-"`
+	ExpiredAt time.Time `json:"-"`
+}
+
+// OidcState keeps what is needed to complete the authorization code flow
+// between redirecting to the identity provider and its callback
+type OidcState struct {
+	Id           string       `db:"id"`
+	Provider     AuthProvider `db:"provider"`
+	Nonce        string       `db:"nonce"`
+	CodeVerifier string       `db:"code_verifier"`
+	CreatedAt    time.Time    `db:"created_at"`
+	ExpiredAt    time.Time    `db:"expired_at"`
+}
+
+type OidcUser struct {
+	Id              string
+	Email           string
+	Name            string
+	IsEmailVerified bool
+}
+
+type OidcRepository interface {
+	CreateState(state *OidcState) error
+	GetState(id string) (*OidcState, error)
+	DeleteState(id string) error
+}
+
+type OidcUsecase interface {
+	ListProviders() []OidcProvider
+	GetAuthRequest(provider AuthProvider) (*OidcAuthRequest, error)
+	GetUser(provider AuthProvider, code string, state string) (*OidcUser, error)
+}
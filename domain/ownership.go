@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+type OwnershipTransferStatus string
+
+const (
+	PendingOwnershipTransfer   OwnershipTransferStatus = "PENDING"
+	AcceptedOwnershipTransfer  OwnershipTransferStatus = "ACCEPTED"
+	DeclinedOwnershipTransfer  OwnershipTransferStatus = "DECLINED"
+	CancelledOwnershipTransfer OwnershipTransferStatus = "CANCELLED"
+)
+
+// OwnershipTransfer is offered by the OWNER to another participant,
+// once accepted the previous owner stays in the workspace as an ADMIN
+type OwnershipTransfer struct {
+	Id          int                     `json:"id" db:"id"`
+	WorkspaceId int                     `json:"workspaceId" db:"workspace_id"`
+	FromUserId  string                  `json:"fromUserId" db:"from_user_id"`
+	FromName    string                  `json:"fromName" db:"from_name"`
+	ToUserId    string                  `json:"toUserId" db:"to_user_id"`
+	ToName      string                  `json:"toName" db:"to_name"`
+	Status      OwnershipTransferStatus `json:"status" db:"status"`
+	CreatedAt   time.Time               `json:"createdAt" db:"created_at"`
+	ExpiredAt   time.Time               `json:"expiredAt" db:"expired_at"`
+	RespondedAt *time.Time              `json:"respondedAt" db:"responded_at"`
+}
+
+type OwnershipTransferRepository interface {
+	Create(transfer *OwnershipTransfer) error
+	Get(id int) (*OwnershipTransfer, error)
+	GetPending(workspaceId int) (*OwnershipTransfer, error)
+	List(workspaceId int) ([]OwnershipTransfer, error)
+	Respond(transfer *OwnershipTransfer) (bool, error)
+	Accept(transfer *OwnershipTransfer, maxOwned int) (bool, error)
+}
+
+type OwnershipTransferUsecase interface {
+	Offer(userId string, workspaceId int, targetId string) (*OwnershipTransfer, error)
+	Accept(userId string, workspaceId int, id int) error
+	Decline(userId string, workspaceId int, id int) error
+	Cancel(userId string, workspaceId int, id int) error
+	List(userId string, workspaceId int) ([]OwnershipTransfer, error)
+}
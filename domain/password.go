@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+type PasswordReset struct {
+	Id        string     `json:"-" db:"id"`
+	UserId    string     `json:"userId" db:"user_id"`
+	Email     string     `json:"email" db:"email"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	ExpiredAt time.Time  `json:"expiredAt" db:"expired_at"`
+	UsedAt    *time.Time `json:"usedAt" db:"used_at"`
+}
+
+type PasswordResetRepository interface {
+	Create(reset *PasswordReset) error
+	Get(id string) (*PasswordReset, error)
+	CountByEmail(email string, since time.Time) (int, error)
+	Use(id string, usedAt time.Time) (bool, error)
+}
+
+type PasswordResetUsecase interface {
+	Request(email string) error
+	Reset(token string, newPassword string) error
+}
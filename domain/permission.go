@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type WorkspacePermission string
+
+const (
+	ManageWorkspacePerm    WorkspacePermission = "manage_workspace"
+	ManageAssignmentPerm   WorkspacePermission = "manage_assignments"
+	ViewAllSubmissionPerm  WorkspacePermission = "view_all_submissions"
+	ManageParticipantPerm  WorkspacePermission = "manage_participants"
+	ManageInvitationPerm   WorkspacePermission = "manage_invitations"
+	GradePerm              WorkspacePermission = "grade"
+	ManageAnnouncementPerm WorkspacePermission = "manage_announcements"
+)
+
+// AllWorkspacePermissions are granted to the OWNER and to ADMINs without a custom role
+var AllWorkspacePermissions = WorkspacePermissions{
+	ManageWorkspacePerm,
+	ManageAssignmentPerm,
+	ViewAllSubmissionPerm,
+	ManageParticipantPerm,
+	ManageInvitationPerm,
+	GradePerm,
+	ManageAnnouncementPerm,
+}
+
+var WorkspacePermissionMap = map[WorkspacePermission]bool{
+	ManageWorkspacePerm:    true,
+	ManageAssignmentPerm:   true,
+	ViewAllSubmissionPerm:  true,
+	ManageParticipantPerm:  true,
+	ManageInvitationPerm:   true,
+	GradePerm:              true,
+	ManageAnnouncementPerm: true,
+}
+
+// WorkspacePermissions is stored as a comma separated list
+type WorkspacePermissions []WorkspacePermission
+
+func (p WorkspacePermissions) Has(permission WorkspacePermission) bool {
+	for _, granted := range p {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *WorkspacePermissions) Scan(src interface{}) error {
+	var value string
+	switch src := src.(type) {
+	case []byte:
+		value = string(src)
+	case string:
+		value = src
+	default:
+		return fmt.Errorf("cannot scan %T into workspace permissions", src)
+	}
+
+	*p = make(WorkspacePermissions, 0)
+	for _, permission := range strings.Split(value, ",") {
+		if permission != "" {
+			*p = append(*p, WorkspacePermission(permission))
+		}
+	}
+	return nil
+}
+
+func (p WorkspacePermissions) Value() (driver.Value, error) {
+	permissions := make([]string, len(p))
+	for i := range p {
+		permissions[i] = string(p[i])
+	}
+	return strings.Join(permissions, ","), nil
+}
+
+// WorkspaceCustomRole narrows down what an ADMIN of the workspace can do
+type WorkspaceCustomRole struct {
+	Id          int                  `json:"id" db:"id"`
+	WorkspaceId int                  `json:"workspaceId" db:"workspace_id"`
+	Name        string               `json:"name" db:"name"`
+	Permissions WorkspacePermissions `json:"permissions" db:"permissions"`
+	CreatedAt   time.Time            `json:"createdAt" db:"created_at"`
+}
+
+type ParticipantRole struct {
+	Role              WorkspaceRole         `db:"role"`
+	CustomRoleId      *int                  `db:"custom_role_id"`
+	CustomPermissions *WorkspacePermissions `db:"permissions"`
+}
+
+// Permissions of a participant, a custom role only applies to an ADMIN
+func (r *ParticipantRole) Permissions() WorkspacePermissions {
+	switch r.Role {
+	case OwnerRole:
+		return AllWorkspacePermissions
+	case AdminRole:
+		if r.CustomPermissions != nil {
+			return *r.CustomPermissions
+		}
+		return AllWorkspacePermissions
+	default:
+		return WorkspacePermissions{}
+	}
+}
+
+type CustomRoleRepository interface {
+	Create(role *WorkspaceCustomRole) error
+	Get(id int) (*WorkspaceCustomRole, error)
+	List(workspaceId int) ([]WorkspaceCustomRole, error)
+	Update(role *WorkspaceCustomRole) error
+	Delete(id int) error
+	CountParticipant(id int) (int, error)
+	SetParticipant(workspaceId int, userId string, id *int) error
+	GetParticipantRole(userId string, workspaceId int) (*ParticipantRole, error)
+}
+
+type CustomRoleUsecase interface {
+	Create(userId string, workspaceId int, name string, permissions WorkspacePermissions) (*WorkspaceCustomRole, error)
+	List(userId string, workspaceId int) ([]WorkspaceCustomRole, error)
+	Update(userId string, workspaceId int, id int, name string, permissions WorkspacePermissions) error
+	Delete(userId string, workspaceId int, id int) error
+	Assign(userId string, workspaceId int, participantId string, id *int) error
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// UnlimitedQuota disables a limit of a quota
+const UnlimitedQuota = -1
+
+type Quota struct {
+	OwnedWorkspaces          int   `json:"ownedWorkspaces"`
+	ParticipantsPerWorkspace int   `json:"participantsPerWorkspace"`
+	AssignmentsPerWorkspace  int   `json:"assignmentsPerWorkspace"`
+	TestcaseBytes            int64 `json:"testcaseBytes"`
+	DailySubmissions         int   `json:"dailySubmissions"`
+}
+
+type WorkspaceUsage struct {
+	WorkspaceId  int    `json:"workspaceId" db:"workspace_id"`
+	Name         string `json:"name" db:"name"`
+	Participants int    `json:"participants" db:"participants"`
+	Assignments  int    `json:"assignments" db:"assignments"`
+}
+
+type Usage struct {
+	AccountType      AccountType      `json:"accountType"`
+	Quota            Quota            `json:"quota"`
+	OwnedWorkspaces  int              `json:"ownedWorkspaces"`
+	TestcaseBytes    int64            `json:"testcaseBytes"`
+	DailySubmissions int              `json:"dailySubmissions"`
+	Workspaces       []WorkspaceUsage `json:"workspaces"`
+}
+
+type QuotaRepository interface {
+	GetWorkspaceOwner(workspaceId int) (*User, error)
+	CountOwnedWorkspace(userId string) (int, error)
+	CountParticipant(workspaceId int) (int, error)
+	CountAssignment(workspaceId int) (int, error)
+	CountSubmission(userId string, since time.Time) (int, error)
+	SumTestcaseSize(ownerId string, excludedAssignmentId int) (int64, error)
+	ListWorkspaceUsage(ownerId string) ([]WorkspaceUsage, error)
+}
+
+type QuotaUsecase interface {
+	Get(accountType AccountType) Quota
+	GetUsage(userId string) (*Usage, error)
+	CheckOwnedWorkspace(userId string) (int, error)
+	CheckParticipant(workspaceId int) (int, error)
+	CheckAssignment(workspaceId int) (int, error)
+	CheckTestcaseBytes(workspaceId int, assignmentId int, size int64) (int64, error)
+	CheckDailySubmission(userId string) (int, error)
+}
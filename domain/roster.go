@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// PendingInvitation reserves a seat in a workspace for an email without an account yet,
+// it is claimed once a user proves to own the email
+type PendingInvitation struct {
+	Id          int           `json:"id" db:"id"`
+	WorkspaceId int           `json:"workspaceId" db:"workspace_id"`
+	Email       string        `json:"email" db:"email"`
+	Role        WorkspaceRole `json:"role" db:"role"`
+	InviterId   string        `json:"inviterId" db:"inviter_id"`
+	CreatedAt   time.Time     `json:"createdAt" db:"created_at"`
+}
+
+type RosterRowStatus string
+
+const (
+	RosterAdded          RosterRowStatus = "ADDED"
+	RosterInvited        RosterRowStatus = "INVITED"
+	RosterAlreadyJoined  RosterRowStatus = "ALREADY_JOINED"
+	RosterAlreadyInvited RosterRowStatus = "ALREADY_INVITED"
+	RosterDuplicated     RosterRowStatus = "DUPLICATED"
+	RosterInvalid        RosterRowStatus = "INVALID"
+	RosterFailed         RosterRowStatus = "FAILED"
+)
+
+type RosterRow struct {
+	Line   int             `json:"line"`
+	Email  string          `json:"email"`
+	Role   WorkspaceRole   `json:"role,omitempty"`
+	Status RosterRowStatus `json:"status"`
+	Reason string          `json:"reason,omitempty"`
+}
+
+type RosterReport struct {
+	Added   int         `json:"added"`
+	Invited int         `json:"invited"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Rows    []RosterRow `json:"rows"`
+}
+
+type RosterRepository interface {
+	CreatePendingInvitation(invitation *PendingInvitation) error
+	HasPendingInvitation(workspaceId int, email string) (bool, error)
+	GetPendingInvitation(id int) (*PendingInvitation, error)
+	GetVerifiedUserIdByEmail(email string) (*string, error)
+	ListPendingInvitation(workspaceId int) ([]PendingInvitation, error)
+	ListPendingInvitationByEmails(emails []string) ([]PendingInvitation, error)
+	DeletePendingInvitation(id int) error
+}
+
+type RosterUsecase interface {
+	Import(importerId string, workspaceId int, roster io.Reader) (*RosterReport, error)
+	ListPendingInvitation(userId string, workspaceId int) ([]PendingInvitation, error)
+	DeletePendingInvitation(userId string, workspaceId int, id int) error
+	Claim(userId string) error
+}
@@ -9,17 +9,23 @@ import (
 type Session struct {
 	Id        string    `json:"id" db:"id"`
 	UserId    string    `json:"userId" db:"user_id"`
-	IpAddress string    `json:"-" db:"ip_address"`
+	IpAddress string    `json:"ipAddress" db:"ip_address"`
 	UserAgent string    `json:"userAgent" db:"user_agent"`
 	ExpiredAt time.Time `json:"expiredAt" db:"expired_at"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+
+	// Whether the session is the one making the request
+	IsCurrent bool `json:"isCurrent" db:"-"`
 }
 
 type SessionRepository interface {
 	Create(session *Session) error
 	Get(id string) (*Session, error)
+	ListByUserId(userId string) ([]Session, error)
+	UpdateExpiredAt(id string, expiredAt time.Time) error
 	Delete(id string) error
 	DeleteByUserId(userId string) error
+	DeleteByUserIdExcept(userId string, exceptId string) error
 	DeleteDuplicates(userId string, ipAddress string, userAgent string) error
 }
 
@@ -31,4 +37,7 @@ type SessionUsecase interface {
 	Destroy(id string) (*fiber.Cookie, error)
 	DestroyByUserId(userId string) (*fiber.Cookie, error)
 	Validate(header string) (*Session, error)
+	List(userId string, currentId string) ([]Session, error)
+	Revoke(userId string, id string) error
+	RevokeOthers(userId string, currentId string) error
 }
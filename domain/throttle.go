@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+type SignInFailureReason string
+
+const (
+	UserNotFoundFailure   SignInFailureReason = "USER_NOT_FOUND"
+	WrongPasswordFailure  SignInFailureReason = "WRONG_PASSWORD"
+	WrongTwoFactorFailure SignInFailureReason = "WRONG_TWO_FACTOR"
+	LockedFailure         SignInFailureReason = "LOCKED"
+)
+
+type SignInAttempt struct {
+	Id        int                  `json:"id" db:"id"`
+	Email     string               `json:"email" db:"email"`
+	IpAddress string               `json:"ipAddress" db:"ip_address"`
+	UserAgent string               `json:"userAgent" db:"user_agent"`
+	IsSuccess bool                 `json:"isSuccess" db:"is_success"`
+	Reason    *SignInFailureReason `json:"reason" db:"reason"`
+	CreatedAt time.Time            `json:"createdAt" db:"created_at"`
+}
+
+type SignInFailureStat struct {
+	Count        int        `db:"count"`
+	LastFailedAt *time.Time `db:"last_failed_at"`
+}
+
+type SignInAttemptFilter struct {
+	Email     *string
+	IpAddress *string
+	Since     *time.Time
+	Limit     int
+	Offset    int
+}
+
+type SignInAttemptRepository interface {
+	Create(attempt *SignInAttempt) error
+	GetFailureStatByEmail(email string, since time.Time) (*SignInFailureStat, error)
+	GetFailureStatByIp(ipAddress string, since time.Time) (*SignInFailureStat, error)
+	ListFailure(filter *SignInAttemptFilter) ([]SignInAttempt, error)
+}
+
+type SignInThrottleUsecase interface {
+	Check(email string, ipAddress string, userAgent string) error
+	RecordFailure(email string, ipAddress string, userAgent string, reason SignInFailureReason) error
+	RecordSuccess(email string, ipAddress string, userAgent string) error
+	ListFailure(filter *SignInAttemptFilter) ([]SignInAttempt, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+type TrashedWorkspace struct {
+	Id         int       `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	ProfileUrl string    `json:"profileUrl" db:"profile_url"`
+	DeletedAt  time.Time `json:"deletedAt" db:"deleted_at"`
+	PurgeAt    time.Time `json:"purgeAt" db:"-"`
+}
+
+type TrashedAssignment struct {
+	Id            int       `json:"id" db:"id"`
+	WorkspaceId   int       `json:"workspaceId" db:"workspace_id"`
+	WorkspaceName string    `json:"workspaceName" db:"workspace_name"`
+	Name          string    `json:"name" db:"name"`
+	DeletedAt     time.Time `json:"deletedAt" db:"deleted_at"`
+	PurgeAt       time.Time `json:"purgeAt" db:"-"`
+}
+
+// Trash holds the deleted items an owner can still restore, the assignments
+// of a deleted workspace come back with it so they are not listed
+type Trash struct {
+	Workspaces  []TrashedWorkspace  `json:"workspaces"`
+	Assignments []TrashedAssignment `json:"assignments"`
+}
+
+type TrashRepository interface {
+	GetWorkspace(id int) (*TrashedWorkspace, error)
+	GetAssignment(id int) (*TrashedAssignment, error)
+	ListWorkspace(ownerId string, since time.Time) ([]TrashedWorkspace, error)
+	ListAssignment(ownerId string, since time.Time) ([]TrashedAssignment, error)
+	ListExpiredWorkspace(before time.Time) ([]TrashedWorkspace, error)
+	ListExpiredAssignment(before time.Time) ([]TrashedAssignment, error)
+	PurgeWorkspace(id int, before time.Time) (bool, error)
+	PurgeAssignment(id int, before time.Time) (bool, error)
+	DeleteWorkspace(id int) error
+}
+
+type TrashUsecase interface {
+	List(userId string) (*Trash, error)
+	RestoreWorkspace(userId string, workspaceId int) error
+	RestoreAssignment(userId string, workspaceId int, assignmentId int) error
+	Purge() (int, error)
+}
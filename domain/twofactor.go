@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningUri string `json:"provisioningUri"`
+}
+
+type TwoFactorChallenge struct {
+	Id        string    `json:"-" db:"id"`
+	UserId    string    `json:"-" db:"user_id"`
+	Attempt   int       `json:"-" db:"attempt"`
+	CreatedAt time.Time `json:"-" db:"created_at"`
+	ExpiredAt time.Time `json:"expiredAt" db:"expired_at"`
+
+	// Plain token only available right after the challenge is created
+	Token string `json:"token" db:"-"`
+}
+
+type RecoveryCode struct {
+	Id        string     `db:"id"`
+	UserId    string     `db:"user_id"`
+	CreatedAt time.Time  `db:"created_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+type TwoFactorRepository interface {
+	CreateChallenge(challenge *TwoFactorChallenge) error
+	GetChallenge(id string) (*TwoFactorChallenge, error)
+	CountChallenge(userId string, since time.Time) (int, error)
+	IncreaseChallengeAttempt(id string, maxAttempt int, now time.Time) (bool, error)
+	DeleteChallenge(id string) (bool, error)
+	CreateRecoveryCodes(userId string, codes []RecoveryCode) error
+	UseRecoveryCode(userId string, id string, usedAt time.Time) (bool, error)
+	UseTotpStep(userId string, step int64) (bool, error)
+	DeleteRecoveryCodes(userId string) error
+}
+
+type TwoFactorUsecase interface {
+	Enroll(userId string) (*TwoFactorEnrollment, error)
+	Activate(userId string, code string) ([]string, error)
+	Disable(userId string, password string, code string) error
+	RegenerateRecoveryCodes(userId string, code string) ([]string, error)
+	CreateChallenge(userId string) (*TwoFactorChallenge, error)
+	GetChallenge(token string) (*TwoFactorChallenge, error)
+	VerifyChallenge(token string, code string) (string, error)
+}
@@ -20,14 +20,21 @@ const (
 )
 
 type User struct {
-	Id          string       `json:"id" db:"id"`
-	Email       string       `json:"email" db:"email"`
-	Password    string       `json:"-" db:"password"`
-	DisplayName string       `json:"displayName" db:"display_name"`
-	ProfileUrl  string       `json:"profileUrl" db:"profile_url"`
-	Type        AccountType  `json:"accountType" db:"account_type"`
-	Provider    AuthProvider `json:"provider" db:"provider"`
-	CreatedAt   time.Time    `json:"createdAt" db:"created_at"`
+	Id                 string       `json:"id" db:"id"`
+	Email              string       `json:"email" db:"email"`
+	Password           string       `json:"-" db:"password"`
+	TotpSecret         *string      `json:"-" db:"totp_secret"`
+	TotpLastStep       *int64       `json:"-" db:"totp_last_step"`
+	IsTwoFactorEnabled bool         `json:"isTwoFactorEnabled" db:"is_two_factor_enabled"`
+	DisplayName        string       `json:"displayName" db:"display_name"`
+	ProfileUrl         string       `json:"profileUrl" db:"profile_url"`
+	Type               AccountType  `json:"accountType" db:"account_type"`
+	Provider           AuthProvider `json:"provider" db:"provider"`
+	IsEmailVerified    bool         `json:"isEmailVerified" db:"is_email_verified"`
+	IsAdmin            bool         `json:"isAdmin" db:"is_admin"`
+	IsDisabled         bool         `json:"isDisabled" db:"is_disabled"`
+	CreatedAt          time.Time    `json:"createdAt" db:"created_at"`
+	DeletedAt          *time.Time   `json:"deletedAt,omitempty" db:"deleted_at"`
 }
 
 type UpdateUser struct {
@@ -40,15 +47,23 @@ type UserRepository interface {
 	Get(id string) (*User, error)
 	GetBySessionId(id string) (*User, error)
 	GetByEmail(email string, provider AuthProvider) (*User, error)
+	Search(query string, limit int, offset int) ([]User, error)
 	Update(user *User) error
+	UpdateAdmin(id string, isAdmin bool) error
+	UpdateDisabled(id string, isDisabled bool) error
+	Merge(fromId string, intoId string) error
 }
 
 type UserUsecase interface {
 	Create(email string, password string) (*User, error)
-	CreateFromGoogle(id string, email string, name string) (*User, error)
+	CreateFromGoogle(id string, email string, name string, isEmailVerified bool) (*User, error)
+	CreateFromOidc(provider AuthProvider, email string, name string, isEmailVerified bool) (*User, error)
 	Get(id string) (*User, error)
 	GetBySessionId(id string) (*User, error)
 	GetByEmail(email string, provider AuthProvider) (*User, error)
+	IsEmailVerified(id string) (bool, error)
 	Update(id string, user *UpdateUser) error
+	VerifyEmail(id string) error
 	UpdatePassword(id string, oldPlainPassword string, newPlainPassword string) error
+	ResetPassword(id string, newPlainPassword string) error
 }
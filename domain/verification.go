@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+type EmailVerification struct {
+	Id        string    `json:"-" db:"id"`
+	UserId    string    `json:"userId" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	ExpiredAt time.Time `json:"expiredAt" db:"expired_at"`
+}
+
+type EmailVerificationRepository interface {
+	Create(verification *EmailVerification) error
+	Get(id string) (*EmailVerification, error)
+	DeleteByUserId(userId string) error
+}
+
+type EmailVerificationUsecase interface {
+	Send(userId string) error
+	Verify(token string) error
+}
@@ -2,21 +2,25 @@ package domain
 
 import (
 	"io"
+	"strings"
 	"time"
 )
 
 type RawWorkspace struct {
-	Id               int       `json:"id" db:"id"`
-	Name             string    `json:"name" db:"name"`
-	ProfileUrl       string    `json:"profileUrl" db:"profile_url"`
-	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
-	OwnerName        string    `json:"ownerName" db:"owner_name"`
-	OwnerProfileUrl  string    `json:"ownerProfileUrl" db:"owner_profile_url"`
-	ParticipantCount int       `json:"participantCount" db:"participant_count"`
-	TotalAssignment  int       `json:"totalAssignment" db:"total_assignment"`
-	IsArchived       bool      `json:"isArchived" db:"is_archived"`
-	IsOpenScoreboard bool      `json:"-" db:"is_open_scoreboard"`
-	IsDeleted        bool      `json:"-" db:"is_deleted"`
+	Id               int        `json:"id" db:"id"`
+	Name             string     `json:"name" db:"name"`
+	ProfileUrl       string     `json:"profileUrl" db:"profile_url"`
+	CreatedAt        time.Time  `json:"createdAt" db:"created_at"`
+	OwnerName        string     `json:"ownerName" db:"owner_name"`
+	OwnerProfileUrl  string     `json:"ownerProfileUrl" db:"owner_profile_url"`
+	ParticipantCount int        `json:"participantCount" db:"participant_count"`
+	TotalAssignment  int        `json:"totalAssignment" db:"total_assignment"`
+	IsArchived       bool       `json:"isArchived" db:"is_archived"`
+	IsJoinApproval   bool       `json:"isJoinApproval" db:"is_join_approval"`
+	IsDiscoverable   bool       `json:"isDiscoverable" db:"is_discoverable"`
+	IsOpenScoreboard bool       `json:"-" db:"is_open_scoreboard"`
+	IsDeleted        bool       `json:"-" db:"is_deleted"`
+	DeletedAt        *time.Time `json:"-" db:"deleted_at"`
 }
 
 type Workspace struct {
@@ -32,12 +36,19 @@ type Workspace struct {
 type CreateWorkspace struct {
 	Name    string
 	Profile io.Reader
+
+	// Settings copied from another workspace, new workspaces start with all of them off
+	IsOpenScoreboard bool
+	IsJoinApproval   bool
+	IsDiscoverable   bool
 }
 
 type UpdateWorkspace struct {
-	Name    *string
-	Profile io.Reader
-	Archive *bool
+	Name         *string
+	Profile      io.Reader
+	Archive      *bool
+	JoinApproval *bool
+	Discoverable *bool
 }
 
 type UpdateParticipant struct {
@@ -63,6 +74,7 @@ type WorkspaceParticipant struct {
 	UserId            string        `json:"userId" db:"user_id"`
 	Name              string        `json:"name" db:"name"`
 	Role              WorkspaceRole `json:"role" db:"role"`
+	CustomRoleId      *int          `json:"customRoleId" db:"custom_role_id"`
 	ProfileUrl        string        `json:"profileUrl" db:"profile_url"`
 	Favorite          bool          `json:"-" db:"favorite"`
 	JoinedAt          time.Time     `json:"joinedAt" db:"joined_at"`
@@ -70,12 +82,55 @@ type WorkspaceParticipant struct {
 }
 
 type WorkspaceInvitation struct {
-	Id          string    `json:"id" db:"id"`
-	WorkspaceId int       `json:"workspaceId" db:"workspace_id"`
-	InviterId   string    `json:"inviterId" db:"inviter_id"`
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
-	ValidAt     time.Time `json:"validAt" db:"valid_at"`
-	ValidUntil  time.Time `json:"validUntil" db:"valid_until"`
+	Id            string        `json:"id" db:"id"`
+	WorkspaceId   int           `json:"workspaceId" db:"workspace_id"`
+	InviterId     string        `json:"inviterId" db:"inviter_id"`
+	CreatedAt     time.Time     `json:"createdAt" db:"created_at"`
+	ValidAt       time.Time     `json:"validAt" db:"valid_at"`
+	ValidUntil    time.Time     `json:"validUntil" db:"valid_until"`
+	Role          WorkspaceRole `json:"role" db:"role"`
+	MaxUses       *int          `json:"maxUses" db:"max_uses"`
+	Uses          int           `json:"uses" db:"uses"`
+	AllowedDomain *string       `json:"allowedDomain" db:"allowed_domain"`
+	Emails        []string      `json:"emails" db:"-"`
+}
+
+// IsAllowed tells whether the email can redeem the invitation, an invitation
+// restricted by both a domain and emails accepts either of them
+func (i *WorkspaceInvitation) IsAllowed(email string) bool {
+	if i.AllowedDomain == nil && len(i.Emails) == 0 {
+		return true
+	}
+
+	email = strings.ToLower(email)
+	if i.AllowedDomain != nil && strings.HasSuffix(email, "@"+strings.ToLower(*i.AllowedDomain)) {
+		return true
+	}
+	for _, allowed := range i.Emails {
+		if strings.ToLower(allowed) == email {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateInvitation struct {
+	ValidAt       time.Time
+	ValidUntil    time.Time
+	Role          WorkspaceRole
+	MaxUses       *int
+	AllowedDomain *string
+	Emails        []string
+}
+
+type InvitationRedemption struct {
+	Id              int           `json:"id" db:"id"`
+	InvitationId    string        `json:"invitationId" db:"invitation_id"`
+	WorkspaceId     int           `json:"-" db:"workspace_id"`
+	UserId          string        `json:"userId" db:"user_id"`
+	UserDisplayName string        `json:"userDisplayName" db:"user_display_name"`
+	Role            WorkspaceRole `json:"role" db:"role"`
+	RedeemedAt      time.Time     `json:"redeemedAt" db:"redeemed_at"`
 }
 
 type WorkspaceRank struct {
@@ -89,9 +144,9 @@ type WorkspaceRank struct {
 }
 
 type WorkspaceRepository interface {
-	Create(userId string, workspace *RawWorkspace) error
+	Create(userId string, workspace *RawWorkspace, maxOwned int) (bool, error)
 	CreateInvitation(invitation *WorkspaceInvitation) error
-	CreateParticipant(participant *WorkspaceParticipant) error
+	CreateParticipant(participant *WorkspaceParticipant, maxParticipants int) (bool, error)
 	HasUser(userId string, workspaceId int) (bool, error)
 	HasAssignment(assignmentId int, workspaceId int) (bool, error)
 	Get(id int, userId string) (*Workspace, error)
@@ -99,33 +154,44 @@ type WorkspaceRepository interface {
 	GetInvitations(workspaceId int) ([]WorkspaceInvitation, error)
 	GetRaw(id int) (*RawWorkspace, error)
 	GetRole(userId string, workspaceId int) (*WorkspaceRole, error)
-	GetScoreboard(workspaceId int) ([]WorkspaceRank, error)
+	GetScoreboard(workspaceId int, groupId *int) ([]WorkspaceRank, error)
+	UseInvitation(id string) (bool, error)
+	ReleaseInvitation(id string) error
+	CreateRedemption(redemption *InvitationRedemption) error
+	ListRedemption(workspaceId int) ([]InvitationRedemption, error)
 	List(userId string) ([]Workspace, error)
+	ListDiscoverable(userId string) ([]RawWorkspace, error)
 	ListParticipant(workspaceId int) ([]WorkspaceParticipant, error)
 	Update(userId string, workspace *Workspace) error
 	UpdateRecent(userId string, workspaceId int) error
 	UpdateParticipant(userId string, workspaceId int, participant *WorkspaceParticipant) error
 	Delete(workspaceId int) error
+	Restore(workspaceId int, maxOwned int) (bool, error)
 	DeleteInvitation(invitationId string) error
 	DeleteParticipant(workspaceId int, userId string) error
 }
 
 type WorkspaceUsecase interface {
 	Create(userId string, workspace *CreateWorkspace) (*RawWorkspace, error)
-	CreateInvitation(workspaceId int, inviterId string, validAt time.Time, validUntil time.Time) (string, error)
+	CreateInvitation(workspaceId int, inviterId string, invitation *CreateInvitation) (string, error)
 	CreateParticipant(workspaceId int, userId string, role WorkspaceRole) error
-	JoinByInvitation(userId string, invitationCode string) (*Workspace, error)
+	ValidateInvitation(userId string, invitationCode string) (*WorkspaceInvitation, error)
+	RedeemInvitation(userId string, invitation *WorkspaceInvitation) error
 	HasUser(userId string, workspaceId int) (bool, error)
 	HasAssignment(assignmentId int, workspaceId int) (bool, error)
 	Get(id int, userId string) (*Workspace, error)
 	GetInvitation(id string) (*WorkspaceInvitation, error)
-	GetInvitations(workspaceId int) ([]WorkspaceInvitation, error)
+	GetInvitations(userId string, workspaceId int) ([]WorkspaceInvitation, error)
+	ListRedemption(userId string, workspaceId int) ([]InvitationRedemption, error)
 	GetRaw(id int) (*RawWorkspace, error)
 	GetRole(userId string, workspaceId int) (*WorkspaceRole, error)
-	GetScoreboard(workspaceId int) ([]WorkspaceRank, error)
+	GetPermissions(userId string, workspaceId int) (WorkspacePermissions, error)
+	GetScoreboard(workspaceId int, groupId *int) ([]WorkspaceRank, error)
 	CheckPerm(userId string, workspaceId int) (bool, error)
+	CheckPermission(userId string, workspaceId int, permission WorkspacePermission) (bool, error)
 	CheckPermRole(userId string, workspaceId int, roles []WorkspaceRole) (bool, error)
 	List(userId string) ([]Workspace, error)
+	ListDiscoverable(userId string) ([]RawWorkspace, error)
 	ListParticipant(workspaceId int) ([]WorkspaceParticipant, error)
 	Update(userId string, workspaceId int, workspace *UpdateWorkspace) error
 	Favorite(userId string, workspaceId int, favorite bool) error
@@ -0,0 +1,175 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	// Register the formats accepted for uploads
+	_ "image/gif"
+	_ "image/jpeg"
+)
+
+const (
+	MaxDimension = 4096 // Larger images are rejected before being decoded
+
+	gridSize = 5
+)
+
+// Sizes are the widths an avatar is stored in, the first one is stored at the profile url itself
+var Sizes = []int{256, 128, 64}
+
+var (
+	ErrFormat    = errors.New("unsupported image format")
+	ErrDimension = fmt.Errorf("image must not be larger than %dx%d pixels", MaxDimension, MaxDimension)
+
+	background = color.RGBA{R: 240, G: 240, B: 240, A: 255}
+)
+
+// Path returns where the avatar of the given size is stored
+func Path(profileUrl string, size int) string {
+	if size == Sizes[0] {
+		return profileUrl
+	}
+	return fmt.Sprintf("%s_%d", profileUrl, size)
+}
+
+// Decode reads a png, jpeg or gif image, only the first frame of a gif is kept
+func Decode(r io.Reader) (image.Image, error) {
+	var buffer bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(r, &buffer))
+	if err == image.ErrFormat {
+		return nil, ErrFormat
+	} else if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > MaxDimension || config.Height > MaxDimension {
+		return nil, ErrDimension
+	}
+
+	img, _, err := image.Decode(io.MultiReader(&buffer, r))
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// Resize crops the image to a centered square and scales it to size x size,
+// each pixel is the average of the source pixels it covers
+func Resize(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	src := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(src, src.Bounds(), img, crop.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := y*side/size, max((y+1)*side/size, y*side/size+1)
+		for x := 0; x < size; x++ {
+			x0, x1 := x*side/size, max((x+1)*side/size, x*side/size+1)
+
+			var r, g, b, a, count uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := src.PixOffset(sx, sy)
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					count++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / count)
+			dst.Pix[i+1] = uint8(g / count)
+			dst.Pix[i+2] = uint8(b / count)
+			dst.Pix[i+3] = uint8(a / count)
+		}
+	}
+	return dst
+}
+
+// Identicon draws a horizontally symmetric 5x5 pattern, the same seed always gives the same image
+func Identicon(seed string, size int) *image.RGBA {
+	hash := sha256.Sum256([]byte(seed))
+	foreground := hslToRgb(
+		float64(uint16(hash[0])<<8|uint16(hash[1]))/65536,
+		0.45+float64(hash[2])/255*0.2,
+		0.5+float64(hash[3])/255*0.15,
+	)
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	cell := size / (gridSize + 1)
+	margin := (size - cell*gridSize) / 2
+	for row := 0; row < gridSize; row++ {
+		for col := 0; col < (gridSize+1)/2; col++ {
+			if hash[4+row*3+col]%2 == 0 {
+				continue
+			}
+			for _, c := range []int{col, gridSize - 1 - col} {
+				rect := image.Rect(0, 0, cell, cell).Add(image.Pt(margin+c*cell, margin+row*cell))
+				draw.Draw(img, rect, image.NewUniform(foreground), image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img
+}
+
+// EncodePng re-encodes the image, none of the metadata of the original upload is carried over
+func EncodePng(img image.Image) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func hslToRgb(h float64, s float64, l float64) color.RGBA {
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+
+	return color.RGBA{
+		R: uint8(hueToRgb(p, q, h+1.0/3) * 255),
+		G: uint8(hueToRgb(p, q, h) * 255),
+		B: uint8(hueToRgb(p, q, h-1.0/3) * 255),
+		A: 255,
+	}
+}
+
+func hueToRgb(p float64, q float64, t float64) float64 {
+	if t < 0 {
+		t++
+	} else if t > 1 {
+		t--
+	}
+
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 1.0/2:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*(2.0/3-t)*6
+	default:
+		return p
+	}
+}
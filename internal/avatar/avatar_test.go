@@ -0,0 +1,177 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		size int
+		want string
+	}{
+		{256, "/profile/user"},
+		{128, "/profile/user_128"},
+		{64, "/profile/user_64"},
+	}
+
+	for _, test := range tests {
+		if got := Path("/profile/user", test.size); got != test.want {
+			t.Errorf("Path(%d) = %s, want %s", test.size, got, test.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	encode := func(width int, height int, encoder func(*bytes.Buffer, image.Image) error) []byte {
+		var buffer bytes.Buffer
+		if err := encoder(&buffer, image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black})); err != nil {
+			t.Fatalf("cannot encode image: %v", err)
+		}
+		return buffer.Bytes()
+	}
+	encodePng := func(b *bytes.Buffer, img image.Image) error { return png.Encode(b, img) }
+	encodeJpeg := func(b *bytes.Buffer, img image.Image) error { return jpeg.Encode(b, img, nil) }
+	encodeGif := func(b *bytes.Buffer, img image.Image) error { return gif.Encode(b, img, nil) }
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"png", encode(16, 8, encodePng), nil},
+		{"jpeg", encode(16, 8, encodeJpeg), nil},
+		{"gif", encode(16, 8, encodeGif), nil},
+		{"max dimension", encode(MaxDimension, 1, encodePng), nil},
+		{"too wide", encode(MaxDimension+1, 1, encodePng), ErrDimension},
+		{"too tall", encode(1, MaxDimension+1, encodePng), ErrDimension},
+		{"unknown format", []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"), ErrFormat},
+		{"empty", nil, ErrFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img, err := Decode(bytes.NewReader(test.data))
+			if err != test.err {
+				t.Fatalf("Decode error = %v, want %v", err, test.err)
+			}
+			if err == nil && img == nil {
+				t.Fatal("Decode returned no image")
+			}
+		})
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 16, 16))); err != nil {
+		t.Fatalf("cannot encode image: %v", err)
+	}
+
+	if _, err := Decode(bytes.NewReader(buffer.Bytes()[:buffer.Len()/2])); err == nil {
+		t.Error("Decode accepted a truncated image")
+	}
+}
+
+func TestResize(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	// A wide image with a red square in the middle and blue sides to be cropped away
+	wide := image.NewRGBA(image.Rect(0, 0, 12, 4))
+	draw.Draw(wide, wide.Bounds(), image.NewUniform(blue), image.Point{}, draw.Src)
+	draw.Draw(wide, image.Rect(4, 0, 8, 4), image.NewUniform(red), image.Point{}, draw.Src)
+
+	// A tall image with an offset origin and a red square in the middle
+	tall := image.NewRGBA(image.Rect(10, 10, 14, 22))
+	draw.Draw(tall, tall.Bounds(), image.NewUniform(blue), image.Point{}, draw.Src)
+	draw.Draw(tall, image.Rect(10, 14, 14, 18), image.NewUniform(red), image.Point{}, draw.Src)
+
+	// Half black and half white, each pixel of the result covers both halves
+	stripes := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	draw.Draw(stripes, stripes.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(stripes, image.Rect(0, 0, 1, 2), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	tests := []struct {
+		name string
+		img  image.Image
+		size int
+		want color.RGBA
+	}{
+		{"wide crop", wide, 2, red},
+		{"tall crop", tall, 2, red},
+		{"upscale", tall, 8, red},
+		{"average", stripes, 1, color.RGBA{R: 127, G: 127, B: 127, A: 255}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resized := Resize(test.img, test.size)
+			if bounds := resized.Bounds(); bounds != image.Rect(0, 0, test.size, test.size) {
+				t.Fatalf("Resize bounds = %v, want %dx%d", bounds, test.size, test.size)
+			}
+			for y := 0; y < test.size; y++ {
+				for x := 0; x < test.size; x++ {
+					if got := resized.RGBAAt(x, y); got != test.want {
+						t.Fatalf("Resize pixel (%d, %d) = %v, want %v", x, y, got, test.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIdenticon(t *testing.T) {
+	for _, size := range Sizes {
+		img := Identicon("user", size)
+		if bounds := img.Bounds(); bounds != image.Rect(0, 0, size, size) {
+			t.Fatalf("Identicon bounds = %v, want %dx%d", bounds, size, size)
+		}
+
+		if !bytes.Equal(img.Pix, Identicon("user", size).Pix) {
+			t.Errorf("Identicon of size %d differs for the same seed", size)
+		}
+		if bytes.Equal(img.Pix, Identicon("other", size).Pix) {
+			t.Errorf("Identicon of size %d is the same for different seeds", size)
+		}
+
+		// Compare the center of each cell with the one mirrored across the middle column
+		cell := size / (gridSize + 1)
+		margin := (size - cell*gridSize) / 2
+		for row := 0; row < gridSize; row++ {
+			for col := 0; col < gridSize/2; col++ {
+				y := margin + row*cell + cell/2
+				left := img.RGBAAt(margin+col*cell+cell/2, y)
+				right := img.RGBAAt(margin+(gridSize-1-col)*cell+cell/2, y)
+				if left != right {
+					t.Fatalf("Identicon of size %d is not symmetric at row %d column %d", size, row, col)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodePng(t *testing.T) {
+	img := Identicon("user", Sizes[len(Sizes)-1])
+
+	data, err := EncodePng(img)
+	if err != nil {
+		t.Fatalf("EncodePng returned error: %v", err)
+	}
+	decoded, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("cannot decode encoded png: %v", err)
+	}
+
+	rgba := image.NewRGBA(decoded.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), decoded, image.Point{}, draw.Src)
+	if !bytes.Equal(rgba.Pix, img.Pix) {
+		t.Error("EncodePng does not round trip the image")
+	}
+}
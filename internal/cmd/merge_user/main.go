@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/logger"
+	"github.com/codern-org/codern/platform"
+	"github.com/codern-org/codern/repository"
+	"go.uber.org/zap"
+)
+
+// Merge a duplicated user into another one, e.g. a student who signed up with
+// both email and Google before identities could be linked. Workspace participations,
+// submissions and linked identities of the merged user are moved and the user is deleted.
+func main() {
+	// Initialize logger
+	logger := logger.NewLogger()
+
+	// Load configuration file
+	var configPath string
+	var fromId string
+	var intoId string
+
+	flag.StringVar(&configPath, "config", "./config/config.yaml", "path to a config file")
+	flag.StringVar(&fromId, "from", "", "id of the user to be merged and deleted")
+	flag.StringVar(&intoId, "into", "", "id of the user to keep")
+	flag.Parse()
+
+	if fromId == "" || intoId == "" || fromId == intoId {
+		logger.Fatal("Both -from and -into must be provided and be different users")
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		logger.Fatal("Cannot load a config file", zap.Error(err))
+	}
+	logger.Info("Configuration file loaded successfully")
+
+	mysql, err := platform.NewMySql(cfg.Client.MySql.Uri)
+	if err != nil {
+		logger.Fatal("Cannot open MySQL database connection", zap.Error(err))
+	}
+	defer mysql.Close()
+
+	userRepository := repository.NewUserRepository(mysql)
+
+	fromUser, err := userRepository.Get(fromId)
+	if err != nil {
+		logger.Fatal("Cannot get the user to be merged", zap.Error(err))
+	} else if fromUser == nil {
+		logger.Fatal("User to be merged not found", zap.String("id", fromId))
+	}
+
+	intoUser, err := userRepository.Get(intoId)
+	if err != nil {
+		logger.Fatal("Cannot get the user to keep", zap.Error(err))
+	} else if intoUser == nil {
+		logger.Fatal("User to keep not found", zap.String("id", intoId))
+	}
+
+	if err := userRepository.Merge(fromId, intoId); err != nil {
+		logger.Fatal("Cannot merge users", zap.Error(err))
+	}
+
+	if fromUser.Provider == domain.SelfAuth {
+		logger.Warn(
+			"Password of the merged user cannot be used to sign in anymore",
+			zap.String("email", fromUser.Email),
+		)
+	}
+
+	logger.Info(
+		"Users merged",
+		zap.String("from", fromUser.Email),
+		zap.String("into", intoUser.Email),
+	)
+}
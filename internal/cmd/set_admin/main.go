@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/logger"
+	"github.com/codern-org/codern/platform"
+	"github.com/codern-org/codern/repository"
+	"go.uber.org/zap"
+)
+
+// Grant or revoke the site admin role of a user, the first admin can only be set from here
+func main() {
+	// Initialize logger
+	logger := logger.NewLogger()
+
+	// Load configuration file
+	var configPath string
+	var userId string
+	var revoke bool
+
+	flag.StringVar(&configPath, "config", "./config/config.yaml", "path to a config file")
+	flag.StringVar(&userId, "id", "", "id of the user")
+	flag.BoolVar(&revoke, "revoke", false, "revoke the site admin role instead of granting it")
+	flag.Parse()
+
+	if userId == "" {
+		logger.Fatal("-id must be provided")
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		logger.Fatal("Cannot load a config file", zap.Error(err))
+	}
+	logger.Info("Configuration file loaded successfully")
+
+	mysql, err := platform.NewMySql(cfg.Client.MySql.Uri)
+	if err != nil {
+		logger.Fatal("Cannot open MySQL database connection", zap.Error(err))
+	}
+	defer mysql.Close()
+
+	userRepository := repository.NewUserRepository(mysql)
+
+	user, err := userRepository.Get(userId)
+	if err != nil {
+		logger.Fatal("Cannot get the user", zap.Error(err))
+	} else if user == nil {
+		logger.Fatal("User not found", zap.String("id", userId))
+	}
+
+	if err := userRepository.UpdateAdmin(userId, !revoke); err != nil {
+		logger.Fatal("Cannot update site admin role", zap.Error(err))
+	}
+
+	logger.Info(
+		"Site admin role updated",
+		zap.String("email", user.Email),
+		zap.Bool("isAdmin", !revoke),
+	)
+}
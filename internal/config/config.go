@@ -14,6 +14,7 @@ type Config struct {
 	Client   ConfigClient   `yaml:"client" validate:"required"`
 	Google   ConfigGoogle   `yaml:"google" validate:"required"`
 	Auth     ConfigAuth     `yaml:"auth" validate:"required"`
+	Quota    ConfigQuota    `yaml:"quota" validate:"required"`
 }
 
 type ConfigMetadata struct {
@@ -28,6 +29,7 @@ type ConfigClient struct {
 	RabbitMq  ConfigRabbitMq  `yaml:"rabbitmq" validate:"required"`
 	Fiber     ConfigFiber     `yaml:"fiber" validate:"required"`
 	Frontend  ConfigFrontend  `yaml:"frontend" validate:"required"`
+	Mail      ConfigMail      `yaml:"mail" validate:"required"`
 }
 
 type ConfigInfluxDb struct {
@@ -61,13 +63,28 @@ type ConfigFiber struct {
 	ProxyHeader    string   `yaml:"proxyHeader"`
 }
 
+type ConfigMail struct {
+	Driver string         `yaml:"driver" validate:"oneof=smtp log"`
+	From   string         `yaml:"from" validate:"required"`
+	Smtp   ConfigMailSmtp `yaml:"smtp"`
+}
+
+type ConfigMailSmtp struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
 type ConfigFrontend struct {
 	BaseUrl string             `yaml:"baseUrl" vallidate:"url,required"`
 	Path    ConfigFrontendPath `yaml:"path" validate:"required"`
 }
 
 type ConfigFrontendPath struct {
-	SignIn string `yaml:"signIn" validate:"required"`
+	SignIn        string `yaml:"signIn" validate:"required"`
+	VerifyEmail   string `yaml:"verifyEmail" validate:"required"`
+	ResetPassword string `yaml:"resetPassword" validate:"required"`
 }
 
 type ConfigGoogle struct {
@@ -78,12 +95,58 @@ type ConfigGoogle struct {
 
 type ConfigAuth struct {
 	Session ConfigAuthSession `yaml:"session" validate:"required"`
+	SignUp  ConfigAuthSignUp  `yaml:"signUp"`
+	Oidc    []ConfigAuthOidc  `yaml:"oidc" validate:"unique=Provider,dive"`
 }
 
 type ConfigAuthSession struct {
 	Prefix string `yaml:"prefix" validate:"required"`
-	Secret string `yaml:"secret" validate:"required"`
-	MaxAge int    `yaml:"maxAge" validate:"number,required"`
+	// The first secret signs new sessions and all of them are accepted,
+	// so a new secret can be prepended before the old one is removed
+	Secrets []string `yaml:"secrets" validate:"required,min=1,dive,required"`
+	// A session expires after IdleAge seconds without activity
+	// and MaxAge seconds after sign in regardless of activity
+	IdleAge int `yaml:"idleAge" validate:"number,required"`
+	MaxAge  int `yaml:"maxAge" validate:"number,required,gtefield=IdleAge"`
+}
+
+type ConfigAuthSignUp struct {
+	// Leave empty to allow any email domain
+	AllowedEmailDomains []string `yaml:"allowedEmailDomains"`
+}
+
+type ConfigAuthOidc struct {
+	// Stored as the provider of the users signing in with it, must not be SELF or GOOGLE
+	Provider     string               `yaml:"provider" validate:"required,uppercase,alphanum,max=32,ne=SELF,ne=GOOGLE"`
+	Name         string               `yaml:"name" validate:"required"`
+	Issuer       string               `yaml:"issuer" validate:"required,url"`
+	ClientId     string               `yaml:"clientId" validate:"required"`
+	ClientSecret string               `yaml:"clientSecret" validate:"required"`
+	RedirectUri  string               `yaml:"redirectUri" validate:"required,url"`
+	Scopes       []string             `yaml:"scopes"`
+	Claims       ConfigAuthOidcClaims `yaml:"claims"`
+}
+
+// ConfigAuthOidcClaims maps user fields to claim names, empty fields fall back to the standard claims
+type ConfigAuthOidcClaims struct {
+	Id            string `yaml:"id"`
+	Email         string `yaml:"email"`
+	Name          string `yaml:"name"`
+	EmailVerified string `yaml:"emailVerified"`
+}
+
+type ConfigQuota struct {
+	Free ConfigQuotaLimit `yaml:"free" validate:"required"`
+	Pro  ConfigQuotaLimit `yaml:"pro" validate:"required"`
+}
+
+// ConfigQuotaLimit uses -1 for unlimited, limits of a workspace follow the account of its owner
+type ConfigQuotaLimit struct {
+	OwnedWorkspaces          int   `yaml:"ownedWorkspaces" validate:"min=-1"`
+	ParticipantsPerWorkspace int   `yaml:"participantsPerWorkspace" validate:"min=-1"`
+	AssignmentsPerWorkspace  int   `yaml:"assignmentsPerWorkspace" validate:"min=-1"`
+	TestcaseBytes            int64 `yaml:"testcaseBytes" validate:"min=-1"`
+	DailySubmissions         int   `yaml:"dailySubmissions" validate:"min=-1"`
 }
 
 func Load(path string) (*Config, error) {
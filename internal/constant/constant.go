@@ -1,23 +1,69 @@
 package constant
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 var (
 	Version       = "0.0.0" // Load from LDFLAGS for versioning
 	IsDevelopment = os.Getenv("ENVIRONMENT") == "development"
 
-	SessionCookieName = "sid"
+	SessionCookieName       = "sid"
+	OidcStateCookieName     = "oidc_state"
+	ImpersonationCookieName = "impersonation"
+
+	RequestIdCtxLocal     = "requestid"
+	PathTypeCtxLocal      = "pathType"
+	UserCtxLocal          = "user"
+	ApiTokenCtxLocal      = "apiToken"
+	ImpersonationCtxLocal = "impersonation"
+	WorkspaceIdCtxLocal   = "workspaceId"
+	AssignmentIdCtxLocal  = "assignmentId"
 
-	RequestIdCtxLocal    = "requestid"
-	PathTypeCtxLocal     = "pathType"
-	UserCtxLocal         = "user"
-	WorkspaceIdCtxLocal  = "workspaceId"
-	AssignmentIdCtxLocal = "assignmentId"
+	SessionRefreshInterval = 1 * time.Minute // Minimum extension before the expiry is written
 
 	MaxWebSocketConnPerUser = 4
 	SeaweedFsChunkSize      = 1048576 // 1 MiB
 
 	MaxInvitationCodeChar = 6
+	MaxRosterRow          = 1000
+
+	MaxArchiveFileSize = 64 * 1024 * 1024  // Uncompressed bytes of one workspace archive entry
+	MaxArchiveSize     = 256 * 1024 * 1024 // Uncompressed bytes of a whole workspace archive
+
+	DefaultPageSize = 50
+
+	EmailVerificationMaxAge = 24 * time.Hour
+
+	PasswordResetMaxAge     = 1 * time.Hour
+	PasswordResetRateLimit  = 3 // Maximum requests per email within the window
+	PasswordResetRateWindow = 1 * time.Hour
+
+	TwoFactorChallengeMaxAge     = 5 * time.Minute
+	TwoFactorChallengeMaxAttempt = 5
+	TwoFactorChallengeMaxPerUser = 5 // Challenges a user can be issued within TwoFactorChallengeMaxAge
+	RecoveryCodeCount            = 10
+	RecoveryCodeLength           = 10
+
+	ApiTokenPrefix           = "cdn_"
+	MaxApiTokenPerUser       = 20
+	ApiTokenLastUsedInterval = 1 * time.Minute
+
+	OidcStateMaxAge = 10 * time.Minute
+
+	ImpersonationMaxAge = 1 * time.Hour
+
+	OwnershipTransferMaxAge = 7 * 24 * time.Hour
+
+	TrashRetention     = 30 * 24 * time.Hour
+	TrashPurgeInterval = 1 * time.Hour
+
+	AnnouncementPublishInterval = 1 * time.Minute
 
-	DefaultProfileUrl = "/workspaces/1/profile"
+	SignInThrottleWindow  = 15 * time.Minute
+	SignInEmailMaxFailure = 5  // Failures per email before the lockout starts
+	SignInIpMaxFailure    = 20 // Failures per ip before the lockout starts
+	SignInLockoutBase     = 30 * time.Second
+	SignInLockoutMax      = 15 * time.Minute
 )
@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// RandToken generates a cryptographically secure url-safe token from n random bytes
+func RandToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns a hex-encoded SHA-256 digest of the token to be stored at rest
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RandSecureStr generates a cryptographically secure string of n lowercase alphanumeric characters
+func RandSecureStr(n int) (string, error) {
+	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		// 256 is not a multiple of 36, the slight bias is acceptable for one-time codes
+		b[i] = charset[int(b[i])%len(charset)]
+	}
+	return string(b), nil
+}
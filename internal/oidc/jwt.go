@@ -0,0 +1,237 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const clockSkew = 1 * time.Minute
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type Claims map[string]interface{}
+
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Bool also accepts "true" since some providers send boolean claims as strings
+func (c Claims) Bool(name string) bool {
+	switch value := c[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+func (c Claims) Time(name string) (time.Time, bool) {
+	value, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(value), 0), true
+}
+
+func (c Claims) HasAudience(audience string) bool {
+	switch value := c["aud"].(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for _, aud := range value {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseJwt(raw string) (*jwtHeader, Claims, string, []byte, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, nil, "", nil, fmt.Errorf("malformed jwt")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, nil, "", nil, fmt.Errorf("malformed jwt header: %w", err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, nil, "", nil, fmt.Errorf("malformed jwt claims: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, "", nil, fmt.Errorf("malformed jwt signature: %w", err)
+	}
+
+	return &header, claims, parts[0] + "." + parts[1], signature, nil
+}
+
+func decodeSegment(segment string, result interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+// verifySignature only accepts asymmetric algorithms, "none" and HMAC are rejected
+// because the client secret must not be usable to forge an ID token
+func verifySignature(alg string, key interface{}, signingInput string, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported jwt algorithm %s", alg)
+	}
+
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported jwt algorithm %s", alg)
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match jwt algorithm %s", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, signature); err != nil {
+			return fmt.Errorf("invalid jwt signature: %w", err)
+		}
+	case "PS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match jwt algorithm %s", alg)
+		}
+		if err := rsa.VerifyPSS(publicKey, hash, digest, signature, nil); err != nil {
+			return fmt.Errorf("invalid jwt signature: %w", err)
+		}
+	case "ES":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match jwt algorithm %s", alg)
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid jwt signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return fmt.Errorf("invalid jwt signature")
+		}
+	default:
+		return fmt.Errorf("unsupported jwt algorithm %s", alg)
+	}
+	return nil
+}
+
+type rawKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type rawKeySet struct {
+	Keys []rawKey `json:"keys"`
+}
+
+type keySet struct {
+	keys map[string]interface{}
+}
+
+func (s *rawKeySet) parse() (*keySet, error) {
+	keys := make(map[string]interface{})
+	for _, raw := range s.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+
+		switch raw.Kty {
+		case "RSA":
+			n, err := decodeBigInt(raw.N)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rsa key %s: %w", raw.Kid, err)
+			}
+			e, err := decodeBigInt(raw.E)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rsa key %s: %w", raw.Kid, err)
+			}
+			keys[raw.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch raw.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, err := decodeBigInt(raw.X)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ec key %s: %w", raw.Kid, err)
+			}
+			y, err := decodeBigInt(raw.Y)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ec key %s: %w", raw.Kid, err)
+			}
+			keys[raw.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+	return &keySet{keys: keys}, nil
+}
+
+// find falls back to the only key when the token has no key id
+func (s *keySet) find(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,277 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	testClientId = "client"
+	testNonce    = "nonce"
+)
+
+type testIssuer struct {
+	server     *httptest.Server
+	rsaKey     *rsa.PrivateKey
+	ecKey      *ecdsa.PrivateKey
+	otherKey   *rsa.PrivateKey
+	jwksCalled int
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate rsa key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate ec key: %v", err)
+	}
+
+	issuer := &testIssuer{rsaKey: rsaKey, ecKey: ecKey, otherKey: otherKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Discovery{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JwksUri:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		issuer.jwksCalled++
+		json.NewEncoder(w).Encode(rawKeySet{Keys: []rawKey{
+			{
+				Kty: "RSA",
+				Kid: "rsa",
+				Use: "sig",
+				N:   encodeBigInt(rsaKey.N),
+				E:   encodeBigInt(big.NewInt(int64(rsaKey.E))),
+			},
+			{
+				Kty: "EC",
+				Kid: "ec",
+				Crv: "P-256",
+				X:   encodeBigInt(ecKey.X),
+				Y:   encodeBigInt(ecKey.Y),
+			},
+			{
+				Kty: "RSA",
+				Kid: "enc",
+				Use: "enc",
+				N:   encodeBigInt(otherKey.N),
+				E:   encodeBigInt(big.NewInt(int64(otherKey.E))),
+			},
+		}})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) provider() *Provider {
+	return NewProvider(i.server.URL, testClientId, "secret", i.server.URL+"/callback", []string{"openid"})
+}
+
+func (i *testIssuer) claims() Claims {
+	now := time.Now()
+	return Claims{
+		"iss":   i.server.URL,
+		"sub":   "subject",
+		"aud":   testClientId,
+		"exp":   float64(now.Add(time.Hour).Unix()),
+		"iat":   float64(now.Unix()),
+		"nonce": testNonce,
+	}
+}
+
+func (i *testIssuer) sign(t *testing.T, alg string, kid string, claims Claims) string {
+	header, _ := json.Marshal(jwtHeader{Alg: alg, Kid: kid})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	var err error
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest[:])
+	case "PS256":
+		signature, err = rsa.SignPSS(rand.Reader, i.rsaKey, crypto.SHA256, digest[:], nil)
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, i.ecKey, digest[:])
+		if err == nil {
+			signature = make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+		}
+	case "HS256":
+		// Signed with the client secret, which must never be accepted
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case "none":
+	case "forged":
+		header, _ = json.Marshal(jwtHeader{Alg: "RS256", Kid: kid})
+		signingInput = base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		digest = sha256.Sum256([]byte(signingInput))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, i.otherKey, crypto.SHA256, digest[:])
+	}
+	if err != nil {
+		t.Fatalf("cannot sign jwt: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	with := func(name string, value interface{}) Claims {
+		claims := issuer.claims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		alg     string
+		kid     string
+		claims  Claims
+		isValid bool
+	}{
+		{"rs256", "RS256", "rsa", issuer.claims(), true},
+		{"ps256", "PS256", "rsa", issuer.claims(), true},
+		{"es256", "ES256", "ec", issuer.claims(), true},
+		{"audience list", "RS256", "rsa", with("aud", []interface{}{"other", testClientId}), true},
+		{"expired within clock skew", "RS256", "rsa", with("exp", float64(now.Add(-30*time.Second).Unix())), true},
+		{"signed by another key", "forged", "rsa", issuer.claims(), false},
+		{"algorithm none", "none", "rsa", issuer.claims(), false},
+		{"algorithm hs256", "HS256", "rsa", issuer.claims(), false},
+		{"key type mismatch", "ES256", "rsa", issuer.claims(), false},
+		{"unknown key id", "RS256", "unknown", issuer.claims(), false},
+		{"encryption key", "RS256", "enc", issuer.claims(), false},
+		{"expired", "RS256", "rsa", with("exp", float64(now.Add(-2*time.Minute).Unix())), false},
+		{"no expiry", "RS256", "rsa", with("exp", nil), false},
+		{"issued in the future", "RS256", "rsa", with("iat", float64(now.Add(2*time.Minute).Unix())), false},
+		{"other issuer", "RS256", "rsa", with("iss", "https://attacker.example"), false},
+		{"other audience", "RS256", "rsa", with("aud", "other"), false},
+		{"audience list without client", "RS256", "rsa", with("aud", []interface{}{"other"}), false},
+		{"other authorized party", "RS256", "rsa", with("azp", "other"), false},
+		{"other nonce", "RS256", "rsa", with("nonce", "other"), false},
+		{"no nonce", "RS256", "rsa", with("nonce", nil), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := issuer.sign(t, test.alg, test.kid, test.claims)
+			claims, err := issuer.provider().VerifyIdToken(token, testNonce)
+			if test.isValid && err != nil {
+				t.Fatalf("VerifyIdToken returned error: %v", err)
+			} else if !test.isValid && err == nil {
+				t.Fatal("VerifyIdToken accepted an invalid token")
+			}
+			if test.isValid && claims.String("sub") != "subject" {
+				t.Errorf("VerifyIdToken sub = %s, want subject", claims.String("sub"))
+			}
+		})
+	}
+}
+
+func TestVerifyIdTokenMalformed(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"two segments", "a.b"},
+		{"invalid header", "!.e30.e30"},
+		{"invalid claims", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256"}`)) + ".!.e30"},
+		{"invalid signature", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256"}`)) + ".e30.!"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := issuer.provider().VerifyIdToken(test.token, testNonce); err == nil {
+				t.Fatal("VerifyIdToken accepted a malformed token")
+			}
+		})
+	}
+}
+
+func TestVerifyIdTokenKeyRotation(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := issuer.provider()
+
+	token := issuer.sign(t, "RS256", "rsa", issuer.claims())
+	for i := 0; i < 2; i++ {
+		if _, err := provider.VerifyIdToken(token, testNonce); err != nil {
+			t.Fatalf("VerifyIdToken returned error: %v", err)
+		}
+	}
+	if issuer.jwksCalled != 1 {
+		t.Errorf("jwks fetched %d times for a known key, want 1", issuer.jwksCalled)
+	}
+
+	// An unknown key id refetches the key set once per verification
+	token = issuer.sign(t, "RS256", "rotated", issuer.claims())
+	if _, err := provider.VerifyIdToken(token, testNonce); err == nil {
+		t.Fatal("VerifyIdToken accepted a token of an unknown key")
+	}
+	if issuer.jwksCalled != 2 {
+		t.Errorf("jwks fetched %d times after an unknown key, want 2", issuer.jwksCalled)
+	}
+}
+
+func TestClaims(t *testing.T) {
+	claims := Claims{
+		"verified":        true,
+		"verified_string": "true",
+		"unverified":      "false",
+		"number":          float64(1),
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"verified", true},
+		{"verified_string", true},
+		{"unverified", false},
+		{"number", false},
+		{"missing", false},
+	}
+
+	for _, test := range tests {
+		if got := claims.Bool(test.name); got != test.want {
+			t.Errorf("Claims.Bool(%s) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Package oidc implements the subset of OpenID Connect needed to sign in with
+// the authorization code flow: discovery, PKCE and ID token verification.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	IdToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+}
+
+type Provider struct {
+	issuer       string
+	clientId     string
+	clientSecret string
+	redirectUri  string
+	scopes       []string
+	httpClient   *http.Client
+
+	mutex     sync.Mutex
+	discovery *Discovery
+	keys      *keySet
+}
+
+func NewProvider(
+	issuer string,
+	clientId string,
+	clientSecret string,
+	redirectUri string,
+	scopes []string,
+) *Provider {
+	return &Provider{
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		redirectUri:  redirectUri,
+		scopes:       scopes,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeUrl returns the url to redirect the user to the identity provider,
+// codeVerifier is kept by the caller and sent again on Exchange
+func (p *Provider) AuthCodeUrl(state string, nonce string, codeVerifier string) (string, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	authUrl, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	query := authUrl.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.clientId)
+	query.Set("redirect_uri", p.redirectUri)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	authUrl.RawQuery = query.Encode()
+
+	return authUrl.String(), nil
+}
+
+func (p *Provider) Exchange(code string, codeVerifier string) (*Token, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectUri)
+	form.Set("client_id", p.clientId)
+	form.Set("client_secret", p.clientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	request, err := http.NewRequest("POST", discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var token Token
+	if err := p.do(request, &token); err != nil {
+		return nil, fmt.Errorf("cannot exchange code for token: %w", err)
+	}
+	if token.IdToken == "" {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+	return &token, nil
+}
+
+// VerifyIdToken checks the signature and the standard claims of the ID token
+// and returns its claims
+func (p *Provider) VerifyIdToken(rawIdToken string, nonce string) (Claims, error) {
+	if _, err := p.getDiscovery(); err != nil {
+		return nil, err
+	}
+
+	header, claims, signingInput, signature, err := parseJwt(rawIdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := p.getKey(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, signingInput, signature); err != nil {
+		return nil, err
+	}
+
+	if iss := claims.String("iss"); strings.TrimSuffix(iss, "/") != p.issuer {
+		return nil, fmt.Errorf("id token issuer %s does not match %s", iss, p.issuer)
+	}
+	if !claims.HasAudience(p.clientId) {
+		return nil, fmt.Errorf("id token is not issued for client %s", p.clientId)
+	}
+	if azp := claims.String("azp"); azp != "" && azp != p.clientId {
+		return nil, fmt.Errorf("id token authorized party %s does not match", azp)
+	}
+
+	now := time.Now()
+	if exp, ok := claims.Time("exp"); !ok || !now.Before(exp.Add(clockSkew)) {
+		return nil, fmt.Errorf("id token expired")
+	}
+	if iat, ok := claims.Time("iat"); ok && iat.After(now.Add(clockSkew)) {
+		return nil, fmt.Errorf("id token is issued in the future")
+	}
+	if claims.String("nonce") != nonce {
+		return nil, fmt.Errorf("id token nonce mismatch")
+	}
+	return claims, nil
+}
+
+func (p *Provider) GetUserInfo(accessToken string) (Claims, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+	if discovery.UserinfoEndpoint == "" {
+		return nil, fmt.Errorf("provider has no userinfo endpoint")
+	}
+
+	request, err := http.NewRequest("GET", discovery.UserinfoEndpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create userinfo request: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	request.Header.Set("Accept", "application/json")
+
+	var claims Claims
+	if err := p.do(request, &claims); err != nil {
+		return nil, fmt.Errorf("cannot get userinfo: %w", err)
+	}
+	return claims, nil
+}
+
+func (p *Provider) getDiscovery() (*Discovery, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	request, err := http.NewRequest("GET", p.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create discovery request: %w", err)
+	}
+
+	var discovery Discovery
+	if err := p.do(request, &discovery); err != nil {
+		return nil, fmt.Errorf("cannot get discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("discovery issuer %s does not match %s", discovery.Issuer, p.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksUri == "" {
+		return nil, fmt.Errorf("discovery document is incomplete")
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// getKey looks up the signing key by id and refetches the key set once
+// when the id is unknown, as the provider may have rotated its keys
+func (p *Provider) getKey(kid string) (interface{}, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.keys != nil {
+		if key, ok := p.keys.find(kid); ok {
+			return key, nil
+		}
+	}
+
+	request, err := http.NewRequest("GET", p.discovery.JwksUri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create jwks request: %w", err)
+	}
+
+	var rawKeys rawKeySet
+	if err := p.do(request, &rawKeys); err != nil {
+		return nil, fmt.Errorf("cannot get jwks: %w", err)
+	}
+	keys, err := rawKeys.parse()
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	key, ok := p.keys.find(kid)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s not found", kid)
+	}
+	return key, nil
+}
+
+func (p *Provider) do(request *http.Request, result interface{}) error {
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s: %s", response.Status, string(data))
+	}
+	return json.Unmarshal(data, result)
+}
+
+// GenerateCodeVerifier returns a PKCE code verifier as defined in RFC 7636
+func GenerateCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge derives the S256 code challenge from the code verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameters follow the defaults of RFC 6238 which are supported by most authenticator apps
+const (
+	Digits = 6
+	Period = 30
+	Skew   = 1 // Number of periods before and after the current one to accept
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+func GenerateCode(secret string, t time.Time) (string, error) {
+	return generateCode(secret, uint64(t.Unix())/Period)
+}
+
+// Validate returns the time step the code belongs to, so the caller can reject
+// a code whose step was already accepted
+func Validate(secret string, code string, t time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	counter := int64(t.Unix()) / Period
+	for i := -Skew; i <= Skew; i++ {
+		step := counter + int64(i)
+		expectation, err := generateCode(secret, uint64(step))
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(expectation)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GetProvisioningUri returns an otpauth URI to be rendered as a QR code by the client
+func GetProvisioningUri(issuer string, account string, secret string) string {
+	query := url.Values{}
+	query.Add("secret", secret)
+	query.Add("issuer", issuer)
+	query.Add("algorithm", "SHA1")
+	query.Add("digits", fmt.Sprint(Digits))
+	query.Add("period", fmt.Sprint(Period))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// generateCode implements HOTP (RFC 4226) for the given counter
+func generateCode(secret string, counter uint64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("cannot decode totp secret: %w", err)
+	}
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
@@ -0,0 +1,105 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA1 secret of the RFC 6238 test vectors, the codes below are the
+// last six digits of the eight digit codes listed in the RFC
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		code, err := GenerateCode(rfcSecret, time.Unix(test.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode(%d) returned error: %v", test.unix, err)
+		}
+		if code != test.code {
+			t.Errorf("GenerateCode(%d) = %s, want %s", test.unix, code, test.code)
+		}
+	}
+}
+
+func TestGenerateCodeInvalidSecret(t *testing.T) {
+	if _, err := GenerateCode("not base32!", time.Unix(59, 0)); err == nil {
+		t.Error("GenerateCode with an invalid secret returned no error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / Period
+
+	codeAt := func(offset int64) string {
+		code, err := generateCode(rfcSecret, uint64(step+offset))
+		if err != nil {
+			t.Fatalf("cannot generate code: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name    string
+		code    string
+		isValid bool
+		step    int64
+	}{
+		{"current step", codeAt(0), true, step},
+		{"previous step", codeAt(-Skew), true, step - Skew},
+		{"next step", codeAt(Skew), true, step + Skew},
+		{"before the window", codeAt(-Skew - 1), false, 0},
+		{"after the window", codeAt(Skew + 1), false, 0},
+		{"too short", codeAt(0)[:Digits-1], false, 0},
+		{"too long", codeAt(0) + "0", false, 0},
+		{"empty", "", false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched, isValid := Validate(rfcSecret, test.code, now)
+			if isValid != test.isValid {
+				t.Fatalf("Validate(%q) valid = %v, want %v", test.code, isValid, test.isValid)
+			}
+			if matched != test.step {
+				t.Errorf("Validate(%q) step = %d, want %d", test.code, matched, test.step)
+			}
+		})
+	}
+}
+
+func TestValidateLowercaseSecret(t *testing.T) {
+	now := time.Unix(59, 0)
+	if _, isValid := Validate(strings.ToLower(rfcSecret), "287082", now); !isValid {
+		t.Error("Validate rejected a lowercase secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret returned error: %v", err)
+	}
+
+	code, err := GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf("cannot generate code from a generated secret: %v", err)
+	}
+	if _, isValid := Validate(secret, code, time.Now()); !isValid {
+		t.Error("Validate rejected a code of a generated secret")
+	}
+}
@@ -57,6 +57,8 @@ func main() {
 	usecase := initUsecase(cfg, logger, platform, repository, publisher)
 
 	startConsumer(logger, platform, usecase)
+	startTrashPurge(logger, usecase)
+	startAnnouncementPublish(logger, usecase)
 
 	// Initialize server with gracefully shutdown
 	signals := make(chan os.Signal, 1)
@@ -125,6 +127,20 @@ func initPlatform(cfg *config.Config, logger *zap.Logger) *domain.Platform {
 
 	webSocketHub := platform.NewWebSocketHub(prometheus)
 
+	var mailSender platform.MailSender
+	if cfg.Client.Mail.Driver == "smtp" {
+		mailSender = platform.NewSmtpMailSender(
+			cfg.Client.Mail.Smtp.Host,
+			cfg.Client.Mail.Smtp.Port,
+			cfg.Client.Mail.Smtp.Username,
+			cfg.Client.Mail.Smtp.Password,
+			cfg.Client.Mail.From,
+		)
+	} else {
+		mailSender = platform.NewLogMailSender(logger)
+		logger.Warn("Mails are written to the log instead of being delivered")
+	}
+
 	return &domain.Platform{
 		Prometheus:   prometheus,
 		InfluxDb:     influxdb,
@@ -132,6 +148,7 @@ func initPlatform(cfg *config.Config, logger *zap.Logger) *domain.Platform {
 		SeaweedFs:    seaweedfs,
 		RabbitMq:     rabbitmq,
 		WebSocketHub: webSocketHub,
+		MailSender:   mailSender,
 	}
 }
 
@@ -142,6 +159,24 @@ func initRepository(mysql *platform.MySql) *domain.Repository {
 		Workspace:  repository.NewWorkspaceRepository(mysql),
 		Assignment: repository.NewAssignmentRepository(mysql),
 		Survey:     repository.NewSurveyRepository(mysql),
+
+		EmailVerification: repository.NewEmailVerificationRepository(mysql),
+		PasswordReset:     repository.NewPasswordResetRepository(mysql),
+		TwoFactor:         repository.NewTwoFactorRepository(mysql),
+		ApiToken:          repository.NewApiTokenRepository(mysql),
+		Oidc:              repository.NewOidcRepository(mysql),
+		Identity:          repository.NewIdentityRepository(mysql),
+		SignInAttempt:     repository.NewSignInAttemptRepository(mysql),
+		Quota:             repository.NewQuotaRepository(mysql),
+		Impersonation:     repository.NewImpersonationRepository(mysql),
+		Account:           repository.NewAccountRepository(mysql),
+		Roster:            repository.NewRosterRepository(mysql),
+		Group:             repository.NewGroupRepository(mysql),
+		CustomRole:        repository.NewCustomRoleRepository(mysql),
+		JoinRequest:       repository.NewJoinRequestRepository(mysql),
+		OwnershipTransfer: repository.NewOwnershipTransferRepository(mysql),
+		Trash:             repository.NewTrashRepository(mysql),
+		Announcement:      repository.NewAnnouncementRepository(mysql),
 	}
 }
 
@@ -152,13 +187,62 @@ func initUsecase(
 	repository *domain.Repository,
 	publisher *domain.Publisher,
 ) *domain.Usecase {
-	googleUsecase := usecase.NewGoogleUsecase(cfg)
+	googleUsecase := usecase.NewGoogleUsecase(cfg, repository.Oidc)
 	sessionUsecase := usecase.NewSessionUsecase(cfg, repository.Session)
 	userUsecase := usecase.NewUserUsecase(platform.SeaweedFs, repository.User, sessionUsecase)
-	authUsecase := usecase.NewAuthUsecase(googleUsecase, sessionUsecase, userUsecase)
-	workspaceUsecase := usecase.NewWorkspaceUsecase(platform.SeaweedFs, repository.Workspace, repository.User, userUsecase)
-	assignmentUsecase := usecase.NewAssignmentUsecase(platform.SeaweedFs, repository.Assignment, publisher.Grading, workspaceUsecase)
+	quotaUsecase := usecase.NewQuotaUsecase(cfg, repository.Quota, repository.User)
+	workspaceUsecase := usecase.NewWorkspaceUsecase(
+		platform.SeaweedFs, repository.Workspace, repository.User, userUsecase, quotaUsecase, repository.CustomRole,
+	)
+	rosterUsecase := usecase.NewRosterUsecase(repository.Roster, repository.User, repository.Identity, workspaceUsecase)
+	groupUsecase := usecase.NewGroupUsecase(repository.Group, workspaceUsecase)
+	customRoleUsecase := usecase.NewCustomRoleUsecase(repository.CustomRole, workspaceUsecase)
+	joinRequestUsecase := usecase.NewJoinRequestUsecase(
+		platform.WebSocketHub, repository.JoinRequest, workspaceUsecase, userUsecase,
+	)
+	ownershipTransferUsecase := usecase.NewOwnershipTransferUsecase(
+		platform.WebSocketHub, repository.OwnershipTransfer, workspaceUsecase, quotaUsecase,
+	)
+	announcementUsecase := usecase.NewAnnouncementUsecase(
+		platform.WebSocketHub, repository.Announcement, workspaceUsecase,
+	)
+	emailVerificationUsecase := usecase.NewEmailVerificationUsecase(
+		cfg, logger, platform.MailSender, repository.EmailVerification, userUsecase, rosterUsecase,
+	)
+	passwordResetUsecase := usecase.NewPasswordResetUsecase(cfg, platform.MailSender, repository.PasswordReset, userUsecase)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(cfg, repository.TwoFactor, repository.User)
+	apiTokenUsecase := usecase.NewApiTokenUsecase(repository.ApiToken)
+	oidcUsecase := usecase.NewOidcUsecase(cfg, repository.Oidc)
+	identityUsecase := usecase.NewIdentityUsecase(repository.Identity, repository.User, googleUsecase, oidcUsecase)
+	signInThrottleUsecase := usecase.NewSignInThrottleUsecase(repository.SignInAttempt)
+	authUsecase := usecase.NewAuthUsecase(
+		cfg, logger, googleUsecase, sessionUsecase, userUsecase, emailVerificationUsecase,
+		twoFactorUsecase, apiTokenUsecase, oidcUsecase, identityUsecase, signInThrottleUsecase, rosterUsecase,
+	)
+	assignmentUsecase := usecase.NewAssignmentUsecase(
+		platform.SeaweedFs, repository.Assignment, publisher.Grading, workspaceUsecase, userUsecase, quotaUsecase,
+		groupUsecase,
+	)
+	cloneUsecase := usecase.NewCloneUsecase(
+		platform.SeaweedFs, repository.Trash, repository.Assignment, repository.CustomRole,
+		workspaceUsecase, assignmentUsecase,
+	)
+	archiveUsecase := usecase.NewArchiveUsecase(
+		platform.SeaweedFs, repository.Trash, repository.Assignment, workspaceUsecase, assignmentUsecase, quotaUsecase,
+	)
+	trashUsecase := usecase.NewTrashUsecase(
+		platform.SeaweedFs, repository.Trash, repository.Workspace, repository.Assignment, workspaceUsecase, quotaUsecase,
+	)
 	surveyUsecase := usecase.NewSurveyUsecase(repository.Survey)
+	accountUsecase := usecase.NewAccountUsecase(
+		platform.SeaweedFs, repository.Account, repository.User, repository.Session, repository.Identity, repository.Quota,
+	)
+	adminUsecase := usecase.NewAdminUsecase(
+		repository.User, repository.Workspace, repository.Assignment, sessionUsecase, accountUsecase,
+	)
+	impersonationUsecase := usecase.NewImpersonationUsecase(
+		repository.Impersonation, repository.User, workspaceUsecase, sessionUsecase,
+	)
 
 	return &domain.Usecase{
 		Google:     googleUsecase,
@@ -168,6 +252,27 @@ func initUsecase(
 		Workspace:  workspaceUsecase,
 		Assignment: assignmentUsecase,
 		Survey:     surveyUsecase,
+
+		EmailVerification: emailVerificationUsecase,
+		PasswordReset:     passwordResetUsecase,
+		TwoFactor:         twoFactorUsecase,
+		ApiToken:          apiTokenUsecase,
+		Oidc:              oidcUsecase,
+		Identity:          identityUsecase,
+		SignInThrottle:    signInThrottleUsecase,
+		Admin:             adminUsecase,
+		Quota:             quotaUsecase,
+		Impersonation:     impersonationUsecase,
+		Account:           accountUsecase,
+		Roster:            rosterUsecase,
+		Group:             groupUsecase,
+		CustomRole:        customRoleUsecase,
+		JoinRequest:       joinRequestUsecase,
+		OwnershipTransfer: ownershipTransferUsecase,
+		Clone:             cloneUsecase,
+		Archive:           archiveUsecase,
+		Trash:             trashUsecase,
+		Announcement:      announcementUsecase,
 	}
 }
 
@@ -195,3 +300,35 @@ func startConsumer(
 		logger.Fatal("Cannot start grading consumer", zap.Error(err))
 	}
 }
+
+// startTrashPurge hard deletes the trashed items past the retention period in the background
+func startTrashPurge(logger *zap.Logger, usecase *domain.Usecase) {
+	go func() {
+		ticker := time.NewTicker(constant.TrashPurgeInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purged, err := usecase.Trash.Purge()
+			if err != nil {
+				logger.Error("Cannot purge trash", zap.Error(err))
+			}
+			if purged > 0 {
+				logger.Info("Trash purged", zap.Int("count", purged))
+			}
+		}
+	}()
+}
+
+// startAnnouncementPublish pushes the scheduled announcements once their publish date is reached
+func startAnnouncementPublish(logger *zap.Logger, usecase *domain.Usecase) {
+	go func() {
+		ticker := time.NewTicker(constant.AnnouncementPublishInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := usecase.Announcement.PublishDue(); err != nil {
+				logger.Error("Cannot publish scheduled announcement", zap.Error(err))
+			}
+		}
+	}()
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `email_verification`;
+
+ALTER TABLE `user`
+DROP `is_email_verified`;
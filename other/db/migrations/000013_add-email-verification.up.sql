@@ -0,0 +1,14 @@
+ALTER TABLE `user`
+ADD `is_email_verified` BOOLEAN NOT NULL DEFAULT false AFTER `provider`;
+
+-- Existing accounts were created before verification existed
+UPDATE `user` SET is_email_verified = TRUE;
+
+CREATE TABLE IF NOT EXISTS `email_verification` (
+  `id` VARCHAR(64) PRIMARY KEY,
+  `user_id` VARCHAR(64) NOT NULL,
+  `email` VARCHAR(64) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  `expired_at` DATETIME NOT NULL,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
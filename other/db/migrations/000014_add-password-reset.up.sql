@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `password_reset` (
+  `id` VARCHAR(64) PRIMARY KEY,
+  `user_id` VARCHAR(64) NOT NULL,
+  `email` VARCHAR(64) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  `expired_at` DATETIME NOT NULL,
+  `used_at` DATETIME NULL,
+  INDEX (`email`, `created_at`),
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
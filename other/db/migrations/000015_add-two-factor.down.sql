@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `two_factor_challenge`;
+DROP TABLE IF EXISTS `recovery_code`;
+
+ALTER TABLE `user`
+DROP `is_two_factor_enabled`,
+DROP `totp_last_step`,
+DROP `totp_secret`;
@@ -0,0 +1,21 @@
+ALTER TABLE `user`
+ADD `totp_secret` VARCHAR(64) NULL AFTER `password`,
+ADD `totp_last_step` BIGINT NULL AFTER `totp_secret`,
+ADD `is_two_factor_enabled` BOOLEAN NOT NULL DEFAULT false AFTER `totp_last_step`;
+
+CREATE TABLE IF NOT EXISTS `recovery_code` (
+  `id` VARCHAR(64) PRIMARY KEY,
+  `user_id` VARCHAR(64) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  `used_at` DATETIME NULL,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `two_factor_challenge` (
+  `id` VARCHAR(64) PRIMARY KEY,
+  `user_id` VARCHAR(64) NOT NULL,
+  `attempt` INT NOT NULL DEFAULT 0,
+  `created_at` DATETIME NOT NULL,
+  `expired_at` DATETIME NOT NULL,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `api_token` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `user_id` VARCHAR(64) NOT NULL,
+  `name` VARCHAR(64) NOT NULL,
+  `token_hash` VARCHAR(64) NOT NULL UNIQUE,
+  `hint` VARCHAR(16) NOT NULL,
+  `scope` VARCHAR(32) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  `expired_at` DATETIME NULL,
+  `last_used_at` DATETIME NULL,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
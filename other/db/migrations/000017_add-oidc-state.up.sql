@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `oidc_state` (
+  `id` VARCHAR(64) PRIMARY KEY,
+  `provider` VARCHAR(32) NOT NULL,
+  `nonce` VARCHAR(64) NOT NULL,
+  `code_verifier` VARCHAR(128) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  `expired_at` DATETIME NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `user_merge`;
+DROP TABLE IF EXISTS `user_identity`;
@@ -0,0 +1,23 @@
+-- Identities are keyed by the subject of the provider since an email can change hands
+CREATE TABLE IF NOT EXISTS `user_identity` (
+  `provider` VARCHAR(32) NOT NULL,
+  `subject` VARCHAR(255) NULL,
+  `email` VARCHAR(64) NOT NULL,
+  `is_email_verified` BOOLEAN NOT NULL DEFAULT false,
+  `user_id` VARCHAR(64) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  UNIQUE KEY `provider_subject` (`provider`, `subject`),
+  INDEX `provider_email` (`provider`, `email`),
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
+
+-- The existing accounts become their primary identity and get their subject on the next sign in
+INSERT INTO `user_identity` (`provider`, `email`, `is_email_verified`, `user_id`, `created_at`)
+SELECT `provider`, `email`, `is_email_verified`, `id`, `created_at` FROM `user` WHERE `provider` != 'SELF';
+
+CREATE TABLE IF NOT EXISTS `user_merge` (
+  `from_user_id` VARCHAR(64) PRIMARY KEY,
+  `into_user_id` VARCHAR(64) NOT NULL,
+  `merged_at` DATETIME NOT NULL,
+  FOREIGN KEY (`into_user_id`) REFERENCES `user`(`id`)
+);
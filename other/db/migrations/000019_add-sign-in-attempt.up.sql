@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `sign_in_attempt` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `email` VARCHAR(64) NOT NULL,
+  `ip_address` VARCHAR(45) NOT NULL,
+  `user_agent` VARCHAR(256) NOT NULL,
+  `is_success` BOOLEAN NOT NULL,
+  `reason` VARCHAR(32) NULL,
+  `created_at` DATETIME NOT NULL,
+  INDEX (`email`, `created_at`),
+  INDEX (`ip_address`, `created_at`)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE `user`
+DROP `is_admin`,
+DROP `is_disabled`;
@@ -0,0 +1,3 @@
+ALTER TABLE `user`
+ADD `is_admin` TINYINT(1) NOT NULL DEFAULT '0',
+ADD `is_disabled` TINYINT(1) NOT NULL DEFAULT '0';
@@ -0,0 +1,2 @@
+ALTER TABLE `testcase`
+DROP `size`;
@@ -0,0 +1,2 @@
+ALTER TABLE `testcase`
+ADD `size` BIGINT UNSIGNED NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `impersonation_log`;
+DROP TABLE IF EXISTS `impersonation`;
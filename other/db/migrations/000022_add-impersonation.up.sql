@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS `impersonation` (
+  `id` VARCHAR(128) PRIMARY KEY,
+  `impersonator_id` VARCHAR(64) NOT NULL,
+  `user_id` VARCHAR(64) NOT NULL,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `ip_address` VARCHAR(45) NOT NULL,
+  `user_agent` VARCHAR(256) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  `expired_at` DATETIME NOT NULL,
+  `ended_at` DATETIME NULL,
+  INDEX (`workspace_id`, `created_at`),
+  FOREIGN KEY (`impersonator_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `impersonation_log` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `impersonation_id` VARCHAR(128) NOT NULL,
+  `method` VARCHAR(8) NOT NULL,
+  `path` VARCHAR(512) NOT NULL,
+  `is_blocked` BOOLEAN NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  FOREIGN KEY (`impersonation_id`) REFERENCES `impersonation`(`id`) ON DELETE CASCADE
+);
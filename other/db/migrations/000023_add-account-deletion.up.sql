@@ -0,0 +1,2 @@
+ALTER TABLE `user`
+ADD `deleted_at` DATETIME NULL;
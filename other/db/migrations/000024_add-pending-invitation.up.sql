@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `workspace_pending_invitation` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `email` VARCHAR(64) NOT NULL,
+  `role` VARCHAR(32) NOT NULL,
+  `inviter_id` VARCHAR(64) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+
+  UNIQUE (`workspace_id`, `email`),
+  INDEX (`email`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`),
+  FOREIGN KEY (`inviter_id`) REFERENCES `user`(`id`)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `assignment_group_schedule`;
+DROP TABLE IF EXISTS `workspace_group_manager`;
+DROP TABLE IF EXISTS `workspace_group_member`;
+DROP TABLE IF EXISTS `workspace_group`;
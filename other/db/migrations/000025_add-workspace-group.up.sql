@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS `workspace_group` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `name` VARCHAR(64) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`)
+);
+
+-- A member belongs to at most one group of a workspace
+CREATE TABLE IF NOT EXISTS `workspace_group_member` (
+  `group_id` BIGINT UNSIGNED NOT NULL,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `user_id` VARCHAR(64) NOT NULL,
+
+  PRIMARY KEY (`workspace_id`, `user_id`),
+  INDEX (`group_id`),
+  FOREIGN KEY (`group_id`) REFERENCES `workspace_group`(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`),
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
+
+-- An admin managing at least one group is restricted to the groups they manage
+CREATE TABLE IF NOT EXISTS `workspace_group_manager` (
+  `group_id` BIGINT UNSIGNED NOT NULL,
+  `user_id` VARCHAR(64) NOT NULL,
+
+  PRIMARY KEY (`group_id`, `user_id`),
+  FOREIGN KEY (`group_id`) REFERENCES `workspace_group`(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
+
+-- NULL dates fall back to the dates of the assignment
+CREATE TABLE IF NOT EXISTS `assignment_group_schedule` (
+  `assignment_id` BIGINT UNSIGNED NOT NULL,
+  `group_id` BIGINT UNSIGNED NOT NULL,
+  `publish_date` DATETIME NULL,
+  `due_date` DATETIME NULL,
+
+  PRIMARY KEY (`assignment_id`, `group_id`),
+  FOREIGN KEY (`assignment_id`) REFERENCES `assignment`(`id`),
+  FOREIGN KEY (`group_id`) REFERENCES `workspace_group`(`id`) ON DELETE CASCADE
+);
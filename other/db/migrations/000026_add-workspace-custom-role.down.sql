@@ -0,0 +1,3 @@
+ALTER TABLE `workspace_participant` DROP FOREIGN KEY `workspace_participant_custom_role_fk`;
+ALTER TABLE `workspace_participant` DROP COLUMN `custom_role_id`;
+DROP TABLE IF EXISTS `workspace_custom_role`;
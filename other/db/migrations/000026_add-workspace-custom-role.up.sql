@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `workspace_custom_role` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `name` VARCHAR(32) NOT NULL,
+  `permissions` VARCHAR(255) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+
+  UNIQUE (`workspace_id`, `name`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`)
+);
+
+-- Only applies to ADMIN participants, NULL keeps every permission of the role
+ALTER TABLE `workspace_participant`
+  ADD COLUMN `custom_role_id` BIGINT UNSIGNED NULL,
+  ADD CONSTRAINT `workspace_participant_custom_role_fk` FOREIGN KEY (`custom_role_id`) REFERENCES `workspace_custom_role`(`id`);
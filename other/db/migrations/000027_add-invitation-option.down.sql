@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `workspace_invitation_redemption`;
+DROP TABLE IF EXISTS `workspace_invitation_email`;
+ALTER TABLE `workspace_invitation`
+  DROP COLUMN `role`,
+  DROP COLUMN `max_uses`,
+  DROP COLUMN `uses`,
+  DROP COLUMN `allowed_domain`;
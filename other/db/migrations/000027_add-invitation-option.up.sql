@@ -0,0 +1,27 @@
+ALTER TABLE `workspace_invitation`
+  ADD COLUMN `role` VARCHAR(32) NOT NULL DEFAULT 'MEMBER',
+  ADD COLUMN `max_uses` INT UNSIGNED NULL,
+  ADD COLUMN `uses` INT UNSIGNED NOT NULL DEFAULT 0,
+  ADD COLUMN `allowed_domain` VARCHAR(128) NULL;
+
+CREATE TABLE IF NOT EXISTS `workspace_invitation_email` (
+  `invitation_id` VARCHAR(128) NOT NULL,
+  `email` VARCHAR(64) NOT NULL,
+
+  PRIMARY KEY (`invitation_id`, `email`),
+  FOREIGN KEY (`invitation_id`) REFERENCES `workspace_invitation`(`id`) ON DELETE CASCADE
+);
+
+-- Kept after the invitation is deleted
+CREATE TABLE IF NOT EXISTS `workspace_invitation_redemption` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `invitation_id` VARCHAR(128) NOT NULL,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `user_id` VARCHAR(64) NOT NULL,
+  `role` VARCHAR(32) NOT NULL,
+  `redeemed_at` DATETIME NOT NULL,
+
+  INDEX (`workspace_id`, `redeemed_at`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`),
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `workspace_join_request`;
+ALTER TABLE `workspace`
+  DROP `is_join_approval`,
+  DROP `is_discoverable`;
@@ -0,0 +1,20 @@
+ALTER TABLE `workspace`
+  ADD `is_join_approval` TINYINT(1) NOT NULL DEFAULT '0' AFTER `is_archived`,
+  ADD `is_discoverable` TINYINT(1) NOT NULL DEFAULT '0' AFTER `is_join_approval`;
+
+CREATE TABLE IF NOT EXISTS `workspace_join_request` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `user_id` VARCHAR(64) NOT NULL,
+  `invitation_id` VARCHAR(128) NULL,
+  `role` VARCHAR(32) NOT NULL DEFAULT 'MEMBER',
+  `status` VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+  `created_at` DATETIME NOT NULL,
+  `reviewer_id` VARCHAR(64) NULL,
+  `reviewed_at` DATETIME NULL,
+
+  INDEX (`workspace_id`, `status`),
+  INDEX (`user_id`, `status`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`),
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
@@ -0,0 +1,16 @@
+-- Kept after the transfer is answered as the history of the workspace owners
+CREATE TABLE IF NOT EXISTS `workspace_ownership_transfer` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `from_user_id` VARCHAR(64) NOT NULL,
+  `to_user_id` VARCHAR(64) NOT NULL,
+  `status` VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+  `created_at` DATETIME NOT NULL,
+  `expired_at` DATETIME NOT NULL,
+  `responded_at` DATETIME NULL,
+
+  INDEX (`workspace_id`, `status`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`),
+  FOREIGN KEY (`from_user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`to_user_id`) REFERENCES `user`(`id`)
+);
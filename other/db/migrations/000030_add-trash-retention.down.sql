@@ -0,0 +1,4 @@
+ALTER TABLE `assignment`
+  DROP `deleted_at`;
+ALTER TABLE `workspace`
+  DROP `deleted_at`;
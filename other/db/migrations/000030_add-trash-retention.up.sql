@@ -0,0 +1,9 @@
+ALTER TABLE `workspace`
+  ADD `deleted_at` DATETIME NULL AFTER `is_deleted`;
+
+ALTER TABLE `assignment`
+  ADD `deleted_at` DATETIME NULL AFTER `is_deleted`;
+
+-- Items deleted before the trash existed start their retention now
+UPDATE `workspace` SET `deleted_at` = NOW() WHERE `is_deleted` = TRUE;
+UPDATE `assignment` SET `deleted_at` = NOW() WHERE `is_deleted` = TRUE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `workspace_announcement_read`;
+DROP TABLE IF EXISTS `workspace_announcement`;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS `workspace_announcement` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `author_id` VARCHAR(64) NOT NULL,
+  `title` VARCHAR(128) NOT NULL,
+  `body` TEXT NOT NULL,
+  `is_pinned` TINYINT(1) NOT NULL DEFAULT '0',
+  `publish_date` DATETIME NOT NULL,
+  `notified_at` DATETIME NULL,
+  `created_at` DATETIME NOT NULL,
+  `updated_at` DATETIME NOT NULL,
+
+  INDEX (`workspace_id`, `publish_date`),
+  INDEX (`notified_at`, `publish_date`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`),
+  FOREIGN KEY (`author_id`) REFERENCES `user`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `workspace_announcement_read` (
+  `announcement_id` BIGINT UNSIGNED NOT NULL,
+  `user_id` VARCHAR(64) NOT NULL,
+  `read_at` DATETIME NOT NULL,
+
+  PRIMARY KEY (`announcement_id`, `user_id`),
+  FOREIGN KEY (`announcement_id`) REFERENCES `workspace_announcement`(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
@@ -0,0 +1,75 @@
+package platform
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type MailSender interface {
+	Send(to string, subject string, body string) error
+}
+
+type smtpMailSender struct {
+	address string
+	from    string
+	auth    smtp.Auth
+}
+
+func NewSmtpMailSender(
+	host string,
+	port int,
+	username string,
+	password string,
+	from string,
+) MailSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &smtpMailSender{
+		address: fmt.Sprintf("%s:%d", host, port),
+		from:    from,
+		auth:    auth,
+	}
+}
+
+func (s *smtpMailSender) Send(to string, subject string, body string) error {
+	message := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"UTF-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(s.address, s.auth, s.from, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("cannot send mail to %s: %w", to, err)
+	}
+	return nil
+}
+
+// logMailSender writes mails to the logger instead of delivering them,
+// intended for development where no SMTP server is available
+type logMailSender struct {
+	logger *zap.Logger
+}
+
+func NewLogMailSender(logger *zap.Logger) MailSender {
+	return &logMailSender{logger: logger}
+}
+
+func (s *logMailSender) Send(to string, subject string, body string) error {
+	s.logger.Info(
+		"Mail is not delivered by log mail sender",
+		zap.String("to", to),
+		zap.String("subject", subject),
+		zap.String("body", body),
+	)
+	return nil
+}
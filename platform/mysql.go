@@ -39,12 +39,11 @@ func (db *MySql) ExecuteTx(fn func(*sqlx.Tx) error) (retErr error) {
 				return
 			}
 			panic(p)
-		} else if err != nil {
+		} else if retErr != nil {
 			if err := tx.Rollback(); err != nil {
 				retErr = fmt.Errorf("cannot rollback transaction from error: %w", err)
 				return
 			}
-			retErr = err
 		} else {
 			if err := tx.Commit(); err != nil {
 				retErr = fmt.Errorf("cannot commit transaction: %w", err)
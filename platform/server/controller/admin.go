@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminController struct {
+	validator domain.PayloadValidator
+
+	adminUsecase          domain.AdminUsecase
+	signInThrottleUsecase domain.SignInThrottleUsecase
+}
+
+func NewAdminController(
+	validator domain.PayloadValidator,
+	adminUsecase domain.AdminUsecase,
+	signInThrottleUsecase domain.SignInThrottleUsecase,
+) *AdminController {
+	return &AdminController{
+		validator:             validator,
+		adminUsecase:          adminUsecase,
+		signInThrottleUsecase: signInThrottleUsecase,
+	}
+}
+
+func (c *AdminController) SearchUser(ctx *fiber.Ctx) error {
+	var pl payload.SearchUserPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if pl.Limit == 0 {
+		pl.Limit = constant.DefaultPageSize
+	}
+
+	users, err := c.adminUsecase.SearchUser(pl.Query, pl.Limit, pl.Offset)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, users)
+}
+
+func (c *AdminController) DisableUser(ctx *fiber.Ctx) error {
+	var pl payload.AdminUserPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.adminUsecase.DisableUser(user.Id, pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"disabled_at": time.Now(),
+	})
+}
+
+func (c *AdminController) EnableUser(ctx *fiber.Ctx) error {
+	var pl payload.AdminUserPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if err := c.adminUsecase.EnableUser(pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"enabled_at": time.Now(),
+	})
+}
+
+func (c *AdminController) SignOutUser(ctx *fiber.Ctx) error {
+	var pl payload.AdminUserPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if err := c.adminUsecase.SignOutUser(pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"signed_out_at": time.Now(),
+	})
+}
+
+func (c *AdminController) DeleteUser(ctx *fiber.Ctx) error {
+	var pl payload.AdminUserPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.adminUsecase.DeleteUser(user.Id, pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"deleted_at": time.Now(),
+	})
+}
+
+func (c *AdminController) GetWorkspace(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	workspace, err := c.adminUsecase.GetWorkspace(pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, workspace)
+}
+
+func (c *AdminController) RestoreWorkspace(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if err := c.adminUsecase.RestoreWorkspace(pl.WorkspaceId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"restored_at": time.Now(),
+	})
+}
+
+func (c *AdminController) RestoreAssignment(ctx *fiber.Ctx) error {
+	var pl payload.AssignmentPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if err := c.adminUsecase.RestoreAssignment(pl.WorkspaceId, pl.AssignmentId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"restored_at": time.Now(),
+	})
+}
+
+func (c *AdminController) ListSignInFailure(ctx *fiber.Ctx) error {
+	var pl payload.ListSignInAttemptPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	filter := &domain.SignInAttemptFilter{
+		Email:     pl.Email,
+		IpAddress: pl.IpAddress,
+		Limit:     pl.Limit,
+		Offset:    pl.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = constant.DefaultPageSize
+	}
+	if pl.Since != 0 {
+		since := time.Unix(pl.Since, 0)
+		filter.Since = &since
+	}
+
+	attempts, err := c.signInThrottleUsecase.ListFailure(filter)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, attempts)
+}
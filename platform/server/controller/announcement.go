@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AnnouncementController struct {
+	validator domain.PayloadValidator
+
+	announcementUsecase domain.AnnouncementUsecase
+}
+
+func NewAnnouncementController(
+	validator domain.PayloadValidator,
+	announcementUsecase domain.AnnouncementUsecase,
+) *AnnouncementController {
+	return &AnnouncementController{
+		validator:           validator,
+		announcementUsecase: announcementUsecase,
+	}
+}
+
+func (c *AnnouncementController) List(ctx *fiber.Ctx) error {
+	var pl payload.ListAnnouncementPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if pl.Limit == 0 {
+		pl.Limit = constant.DefaultPageSize
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	announcements, err := c.announcementUsecase.List(user.Id, pl.WorkspaceId, pl.Limit, pl.Offset)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, announcements)
+}
+
+func (c *AnnouncementController) CountUnread(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	count, err := c.announcementUsecase.CountUnread(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"unread": count,
+	})
+}
+
+func (c *AnnouncementController) Get(ctx *fiber.Ctx) error {
+	var pl payload.AnnouncementPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	announcement, err := c.announcementUsecase.Get(user.Id, pl.WorkspaceId, pl.AnnouncementId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, announcement)
+}
+
+func (c *AnnouncementController) Create(ctx *fiber.Ctx) error {
+	var pl payload.CreateAnnouncementPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	announcement, err := c.announcementUsecase.Create(user.Id, pl.WorkspaceId, &domain.CreateAnnouncement{
+		Title:       pl.Title,
+		Body:        pl.Body,
+		IsPinned:    pl.IsPinned,
+		PublishDate: pl.PublishDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, announcement)
+}
+
+func (c *AnnouncementController) Update(ctx *fiber.Ctx) error {
+	var pl payload.UpdateAnnouncementPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	err := c.announcementUsecase.Update(user.Id, pl.WorkspaceId, pl.AnnouncementId, &domain.UpdateAnnouncement{
+		Title:       pl.Title,
+		Body:        pl.Body,
+		PublishDate: pl.PublishDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"updated_at": time.Now(),
+	})
+}
+
+func (c *AnnouncementController) Delete(ctx *fiber.Ctx) error {
+	var pl payload.AnnouncementPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.announcementUsecase.Delete(user.Id, pl.WorkspaceId, pl.AnnouncementId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"deleted_at": time.Now(),
+	})
+}
+
+func (c *AnnouncementController) Pin(ctx *fiber.Ctx) error {
+	return c.pin(ctx, true)
+}
+
+func (c *AnnouncementController) Unpin(ctx *fiber.Ctx) error {
+	return c.pin(ctx, false)
+}
+
+func (c *AnnouncementController) MarkRead(ctx *fiber.Ctx) error {
+	var pl payload.AnnouncementPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.announcementUsecase.MarkRead(user.Id, pl.WorkspaceId, pl.AnnouncementId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"read_at": time.Now(),
+	})
+}
+
+func (c *AnnouncementController) pin(ctx *fiber.Ctx, isPinned bool) error {
+	var pl payload.AnnouncementPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.announcementUsecase.Pin(user.Id, pl.WorkspaceId, pl.AnnouncementId, isPinned); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"updated_at": time.Now(),
+	})
+}
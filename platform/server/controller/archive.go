@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ArchiveController struct {
+	validator domain.PayloadValidator
+
+	archiveUsecase domain.ArchiveUsecase
+}
+
+func NewArchiveController(
+	validator domain.PayloadValidator,
+	archiveUsecase domain.ArchiveUsecase,
+) *ArchiveController {
+	return &ArchiveController{
+		validator:      validator,
+		archiveUsecase: archiveUsecase,
+	}
+}
+
+func (c *ArchiveController) Export(ctx *fiber.Ctx) error {
+	var pl payload.ExportWorkspacePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	filename := fmt.Sprintf("codern-workspace-%d.zip", pl.WorkspaceId)
+	return sendArchive(ctx, filename, func(w io.Writer) error {
+		return c.archiveUsecase.Export(user.Id, pl.WorkspaceId, pl.Submissions, w)
+	})
+}
+
+// Import responds with the report of the archive, the workspace is only
+// created when it is not a dry run and the archive has no problem
+func (c *ArchiveController) Import(ctx *fiber.Ctx) error {
+	var pl payload.ImportWorkspacePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	size, err := pl.Archive.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errs.New(errs.ErrInvalidArchive, "cannot read size of workspace archive", err)
+	}
+
+	report, err := c.archiveUsecase.Import(user.Id, pl.Name, pl.Archive, size, pl.DryRun)
+	if err != nil {
+		return err
+	}
+
+	if pl.DryRun {
+		return response.NewSuccessResponse(ctx, fiber.StatusOK, report)
+	}
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, report)
+}
+
+// sendArchive builds the archive in a temporary file before responding, so a failure
+// midway is sent as an error response rather than a truncated attachment
+func sendArchive(ctx *fiber.Ctx, filename string, write func(w io.Writer) error) error {
+	file, err := os.CreateTemp("", "codern-archive-*.zip")
+	if err != nil {
+		return errs.New(errs.ErrFileSystem, "cannot create temporary file for archive", err)
+	}
+	// The file stays readable through the open descriptor until the stream is closed
+	if err := os.Remove(file.Name()); err != nil {
+		file.Close()
+		return errs.New(errs.ErrFileSystem, "cannot unlink temporary file of archive", err)
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return errs.New(errs.ErrFileSystem, "cannot rewind temporary file of archive", err)
+	}
+
+	ctx.Attachment(filename)
+	// The stream is closed by fasthttp once the response is sent
+	return ctx.SendStream(file, int(size))
+}
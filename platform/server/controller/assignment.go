@@ -146,6 +146,8 @@ func (c *AssignmentController) List(ctx *fiber.Ctx) error {
 // @Produce 		json
 // @Param				workspaceId					path	int				true	"Workspace ID"
 // @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				all									query	bool			false	"List the submissions of every participant"
+// @Param				group								query	int				false	"Only list the submissions of a group"
 // @Security 		ApiKeyAuth
 // @Param 			sid header string true "Session ID"
 // @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/submissions [get]
@@ -160,7 +162,7 @@ func (c *AssignmentController) ListSubmission(ctx *fiber.Ctx) error {
 	var err error
 
 	if pl.All {
-		submissions, err = c.assignmentUsecase.ListAllSubmission(user.Id, pl.WorkspaceId, pl.AssignmentId)
+		submissions, err = c.assignmentUsecase.ListAllSubmission(user.Id, pl.WorkspaceId, pl.AssignmentId, pl.GroupId)
 	} else {
 		submissions, err = c.assignmentUsecase.ListSubmission(user.Id, pl.AssignmentId)
 	}
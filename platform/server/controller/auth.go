@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"strings"
 	"time"
 
 	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
 	"github.com/codern-org/codern/internal/config"
 	"github.com/codern-org/codern/internal/constant"
 	"github.com/codern-org/codern/platform/server/middleware"
@@ -16,9 +18,12 @@ type AuthController struct {
 	cfg       *config.Config
 	validator domain.PayloadValidator
 
-	authUsecase   domain.AuthUsecase
-	googleUsecase domain.GoogleUsecase
-	userUsecase   domain.UserUsecase
+	authUsecase              domain.AuthUsecase
+	googleUsecase            domain.GoogleUsecase
+	userUsecase              domain.UserUsecase
+	emailVerificationUsecase domain.EmailVerificationUsecase
+	passwordResetUsecase     domain.PasswordResetUsecase
+	oidcUsecase              domain.OidcUsecase
 }
 
 func NewAuthController(
@@ -27,13 +32,19 @@ func NewAuthController(
 	authUsecase domain.AuthUsecase,
 	googleUsecase domain.GoogleUsecase,
 	userUsecase domain.UserUsecase,
+	emailVerificationUsecase domain.EmailVerificationUsecase,
+	passwordResetUsecase domain.PasswordResetUsecase,
+	oidcUsecase domain.OidcUsecase,
 ) *AuthController {
 	return &AuthController{
-		cfg:           cfg,
-		validator:     validator,
-		authUsecase:   authUsecase,
-		googleUsecase: googleUsecase,
-		userUsecase:   userUsecase,
+		cfg:                      cfg,
+		validator:                validator,
+		authUsecase:              authUsecase,
+		googleUsecase:            googleUsecase,
+		userUsecase:              userUsecase,
+		emailVerificationUsecase: emailVerificationUsecase,
+		passwordResetUsecase:     passwordResetUsecase,
+		oidcUsecase:              oidcUsecase,
 	}
 }
 
@@ -48,7 +59,14 @@ func NewAuthController(
 // @Param 			sid header string true "Session ID"
 // @Router 			/auth/me [get]
 func (c *AuthController) Me(ctx *fiber.Ctx) error {
-	return response.NewSuccessResponse(ctx, fiber.StatusOK, middleware.GetUserFromCtx(ctx))
+	user := middleware.GetUserFromCtx(ctx)
+	if impersonation := middleware.GetImpersonationFromCtx(ctx); impersonation != nil {
+		return response.NewSuccessResponse(ctx, fiber.StatusOK, struct {
+			*domain.User
+			Impersonation *domain.Impersonation `json:"impersonation"`
+		}{user, impersonation})
+	}
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, user)
 }
 
 // SignIn godoc
@@ -68,7 +86,43 @@ func (c *AuthController) SignIn(ctx *fiber.Ctx) error {
 	ipAddress := ctx.IP()
 	userAgent := ctx.Context().UserAgent()
 
-	cookie, err := c.authUsecase.SignIn(pl.Email, pl.Password, ipAddress, string(userAgent))
+	cookie, challenge, err := c.authUsecase.SignIn(pl.Email, pl.Password, ipAddress, string(userAgent))
+	if err != nil {
+		return err
+	}
+
+	if challenge != nil {
+		return response.NewSuccessResponse(ctx, fiber.StatusAccepted, fiber.Map{
+			"two_factor_required": true,
+			"challenge_token":     challenge.Token,
+			"expired_at":          challenge.ExpiredAt,
+		})
+	}
+	ctx.Cookie(cookie)
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"expired_at": cookie.Expires,
+	})
+}
+
+// SignInWithTwoFactor godoc
+//
+// @Summary 		Complete sign in with two-factor
+// @Description Pass the two-factor challenge from sign in with a TOTP or recovery code
+// @Tags 				auth
+// @Accept 			json
+// @Produce 		json
+// @Router 			/auth/signin/2fa [post]
+func (c *AuthController) SignInWithTwoFactor(ctx *fiber.Ctx) error {
+	var pl payload.SignInTwoFactorPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	ipAddress := ctx.IP()
+	userAgent := ctx.Context().UserAgent()
+
+	cookie, err := c.authUsecase.SignInWithTwoFactor(pl.Token, pl.Code, ipAddress, string(userAgent))
 	if err != nil {
 		return err
 	}
@@ -79,6 +133,124 @@ func (c *AuthController) SignIn(ctx *fiber.Ctx) error {
 	})
 }
 
+// SignUp godoc
+//
+// @Summary 		Sign up with self provider
+// @Description Register a new account with email & password and sign in to it
+// @Tags 				auth
+// @Accept 			json
+// @Produce 		json
+// @Router 			/auth/signup [post]
+func (c *AuthController) SignUp(ctx *fiber.Ctx) error {
+	var pl payload.SignUpPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	ipAddress := ctx.IP()
+	userAgent := ctx.Context().UserAgent()
+
+	cookie, err := c.authUsecase.SignUp(pl.Email, pl.Password, ipAddress, string(userAgent))
+	if err != nil {
+		return err
+	}
+	ctx.Cookie(cookie)
+
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, fiber.Map{
+		"expired_at": cookie.Expires,
+	})
+}
+
+// SendEmailVerification godoc
+//
+// @Summary 		Send an email verification
+// @Description Send a new email verification link to the authenticated user
+// @Tags 				auth
+// @Produce 		json
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/auth/email/verification [post]
+func (c *AuthController) SendEmailVerification(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.emailVerificationUsecase.Send(user.Id); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"sent_at": time.Now(),
+	})
+}
+
+// VerifyEmail godoc
+//
+// @Summary 		Verify an email
+// @Description Verify an email address with the token from the verification link
+// @Tags 				auth
+// @Accept 			json
+// @Produce 		json
+// @Router 			/auth/email/verify [post]
+func (c *AuthController) VerifyEmail(ctx *fiber.Ctx) error {
+	var pl payload.VerifyEmailPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if err := c.emailVerificationUsecase.Verify(pl.Token); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"verified_at": time.Now(),
+	})
+}
+
+// ForgotPassword godoc
+//
+// @Summary 		Request a password reset
+// @Description Send a password reset link to the email if it is registered with self provider
+// @Tags 				auth
+// @Accept 			json
+// @Produce 		json
+// @Router 			/auth/password/forgot [post]
+func (c *AuthController) ForgotPassword(ctx *fiber.Ctx) error {
+	var pl payload.ForgotPasswordPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if err := c.passwordResetUsecase.Request(pl.Email); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"requested_at": time.Now(),
+	})
+}
+
+// ResetPassword godoc
+//
+// @Summary 		Reset a password
+// @Description Reset a password with the token from the password reset link and sign out every session
+// @Tags 				auth
+// @Accept 			json
+// @Produce 		json
+// @Router 			/auth/password/reset [post]
+func (c *AuthController) ResetPassword(ctx *fiber.Ctx) error {
+	var pl payload.ResetPasswordPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if err := c.passwordResetUsecase.Reset(pl.Token, pl.Password); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"updated_at": time.Now(),
+	})
+}
+
 // GetGoogleAuthUrl godoc
 //
 // @Summary 		Get Google auth URL
@@ -87,8 +259,22 @@ func (c *AuthController) SignIn(ctx *fiber.Ctx) error {
 // @Produce 		json
 // @Router 			/auth/google [get]
 func (c *AuthController) GetGoogleAuthUrl(ctx *fiber.Ctx) error {
+	authRequest, err := c.googleUsecase.GetAuthRequest()
+	if err != nil {
+		return err
+	}
+
+	// Bind the state to the browser starting the flow, it is checked when linking the account
+	ctx.Cookie(&fiber.Cookie{
+		Name:     constant.OidcStateCookieName,
+		Value:    authRequest.State,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+		Expires:  authRequest.ExpiredAt,
+	})
+
 	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
-		"url": c.googleUsecase.GetOAuthUrl(),
+		"url": authRequest.Url,
 	})
 }
 
@@ -115,6 +301,83 @@ func (c *AuthController) SignInWithGoogle(ctx *fiber.Ctx) error {
 	})
 }
 
+// ListOidcProviders godoc
+//
+// @Summary 		List OpenID Connect providers
+// @Description List the configured OpenID Connect providers to sign in with
+// @Tags 				auth
+// @Produce 		json
+// @Router 			/auth/oidc [get]
+func (c *AuthController) ListOidcProviders(ctx *fiber.Ctx) error {
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, c.oidcUsecase.ListProviders())
+}
+
+// GetOidcAuthUrl godoc
+//
+// @Summary 		Get OpenID Connect auth URL
+// @Description Get an url to sign in with the OpenID Connect provider
+// @Tags 				auth
+// @Produce 		json
+// @Param 			provider path string true "Provider"
+// @Router 			/auth/oidc/{provider} [get]
+func (c *AuthController) GetOidcAuthUrl(ctx *fiber.Ctx) error {
+	var pl payload.OidcProviderPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	authRequest, err := c.oidcUsecase.GetAuthRequest(domain.AuthProvider(strings.ToUpper(pl.Provider)))
+	if err != nil {
+		return err
+	}
+
+	// Bind the state to the browser starting the flow to prevent login CSRF
+	ctx.Cookie(&fiber.Cookie{
+		Name:     constant.OidcStateCookieName,
+		Value:    authRequest.State,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+		Expires:  authRequest.ExpiredAt,
+	})
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, authRequest)
+}
+
+// SignInWithOidc godoc
+//
+// @Summary 		Sign in with OpenID Connect
+// @Description A callback route for the OpenID Connect provider to redirect to after signing in
+// @Tags 				auth
+// @Produce 		json
+// @Param 			provider path string true "Provider"
+// @Router 			/auth/oidc/{provider}/callback [get]
+func (c *AuthController) SignInWithOidc(ctx *fiber.Ctx) error {
+	var pl payload.OidcCallbackPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	state := ctx.Cookies(constant.OidcStateCookieName)
+	ctx.ClearCookie(constant.OidcStateCookieName)
+	if state == "" || state != pl.State {
+		return errs.New(errs.ErrOidcState, "oidc state does not match the browser that started signing in")
+	}
+
+	ipAddress := ctx.IP()
+	userAgent := ctx.Context().UserAgent()
+	provider := domain.AuthProvider(strings.ToUpper(pl.Provider))
+
+	cookie, err := c.authUsecase.SignInWithOidc(provider, pl.Code, pl.State, ipAddress, string(userAgent))
+	if err != nil {
+		return err
+	}
+	ctx.Cookie(cookie)
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"expired_at": cookie.Expires,
+	})
+}
+
 // SignOut godoc
 //
 // @Summary 		Sign out
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CustomRoleController struct {
+	validator domain.PayloadValidator
+
+	customRoleUsecase domain.CustomRoleUsecase
+	workspaceUsecase  domain.WorkspaceUsecase
+}
+
+func NewCustomRoleController(
+	validator domain.PayloadValidator,
+	customRoleUsecase domain.CustomRoleUsecase,
+	workspaceUsecase domain.WorkspaceUsecase,
+) *CustomRoleController {
+	return &CustomRoleController{
+		validator:         validator,
+		customRoleUsecase: customRoleUsecase,
+		workspaceUsecase:  workspaceUsecase,
+	}
+}
+
+// GetPermissions returns the permissions of the current user in the workspace
+func (c *CustomRoleController) GetPermissions(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	permissions, err := c.workspaceUsecase.GetPermissions(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, permissions)
+}
+
+func (c *CustomRoleController) List(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	roles, err := c.customRoleUsecase.List(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, roles)
+}
+
+func (c *CustomRoleController) Create(ctx *fiber.Ctx) error {
+	var pl payload.CreateCustomRolePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	role, err := c.customRoleUsecase.Create(user.Id, pl.WorkspaceId, pl.Name, toPermissions(pl.Permissions))
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, role)
+}
+
+func (c *CustomRoleController) Update(ctx *fiber.Ctx) error {
+	var pl payload.UpdateCustomRolePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	err := c.customRoleUsecase.Update(user.Id, pl.WorkspaceId, pl.RoleId, pl.Name, toPermissions(pl.Permissions))
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"updated_at": time.Now(),
+	})
+}
+
+func (c *CustomRoleController) Delete(ctx *fiber.Ctx) error {
+	var pl payload.CustomRolePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.customRoleUsecase.Delete(user.Id, pl.WorkspaceId, pl.RoleId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"deleted_at": time.Now(),
+	})
+}
+
+func (c *CustomRoleController) Assign(ctx *fiber.Ctx) error {
+	var pl payload.AssignCustomRolePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.customRoleUsecase.Assign(user.Id, pl.WorkspaceId, pl.UserId, pl.RoleId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"updated_at": time.Now(),
+	})
+}
+
+func toPermissions(values []string) domain.WorkspacePermissions {
+	permissions := make(domain.WorkspacePermissions, len(values))
+	for i := range values {
+		permissions[i] = domain.WorkspacePermission(values[i])
+	}
+	return permissions
+}
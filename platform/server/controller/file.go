@@ -6,6 +6,7 @@ import (
 
 	"github.com/codern-org/codern/domain"
 	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/avatar"
 	"github.com/codern-org/codern/internal/config"
 	"github.com/codern-org/codern/platform/server/middleware"
 	"github.com/codern-org/codern/platform/server/payload"
@@ -14,20 +15,23 @@ import (
 )
 
 type FileController struct {
-	validator        domain.PayloadValidator
-	filerUrl         string
-	WorkspaceUsecase domain.WorkspaceUsecase
+	validator         domain.PayloadValidator
+	filerUrl          string
+	WorkspaceUsecase  domain.WorkspaceUsecase
+	assignmentUsecase domain.AssignmentUsecase
 }
 
 func NewFileController(
 	cfg *config.Config,
 	validator domain.PayloadValidator,
 	WorkspaceUsecase domain.WorkspaceUsecase,
+	assignmentUsecase domain.AssignmentUsecase,
 ) *FileController {
 	return &FileController{
-		validator:        validator,
-		filerUrl:         cfg.Client.SeaweedFs.FilerUrls.Internal,
-		WorkspaceUsecase: WorkspaceUsecase,
+		validator:         validator,
+		filerUrl:          cfg.Client.SeaweedFs.FilerUrls.Internal,
+		WorkspaceUsecase:  WorkspaceUsecase,
+		assignmentUsecase: assignmentUsecase,
 	}
 }
 
@@ -38,13 +42,14 @@ func NewFileController(
 // @Tags				file
 // @Produce			png,jpeg,gif
 // @Param				userId			path string true "User ID"
+// @Param				size				query number false "Width in pixels, the closest stored size is served"
 // @Security 		ApiKeyAuth
 // @Param 			sid header string true "Session ID"
 // @Router			/file/user/{userId}/profile [get]
 func (c *FileController) GetUserProfile(ctx *fiber.Ctx) error {
 	userId := ctx.Params("userId")
 
-	path := fmt.Sprintf("/user/%s/profile", userId)
+	path := getProfilePath(ctx, fmt.Sprintf("/user/%s/profile", userId))
 	url, err := url.JoinPath(c.filerUrl, path)
 	if err != nil {
 		return errs.New(errs.ErrCreateUrlPath, "invalid url", err)
@@ -59,6 +64,7 @@ func (c *FileController) GetUserProfile(ctx *fiber.Ctx) error {
 // @Tags				file
 // @Produce			png,jpeg,gif
 // @Param				workspaceId			path number true "Workspace ID"
+// @Param				size				query number false "Width in pixels, the closest stored size is served"
 // @Security 		ApiKeyAuth
 // @Param 			sid header string true "Session ID"
 // @Router			/file/workspaces/{workspaceId}/profile [get]
@@ -68,7 +74,7 @@ func (c *FileController) GetWorkspaceProfile(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	path := fmt.Sprintf("/workspaces/%d/profile", pl.WorkspaceId)
+	path := getProfilePath(ctx, fmt.Sprintf("/workspaces/%d/profile", pl.WorkspaceId))
 	url, err := url.JoinPath(c.filerUrl, path)
 	if err != nil {
 		return errs.New(errs.ErrCreateUrlPath, "invalid url", err)
@@ -142,11 +148,13 @@ func (c *FileController) GetSubmission(ctx *fiber.Ctx) error {
 		pl.WorkspaceId, pl.AssignmentId, submittedUserId, pl.SubmissionId,
 	)
 
-	userRole, err := c.WorkspaceUsecase.GetRole(user.Id, pl.WorkspaceId)
+	// Files keep the path of the user who submitted, which differs after merging users,
+	// so the permission is checked on the submission rather than on the path
+	canView, err := c.assignmentUsecase.CanViewSubmission(user.Id, pl.WorkspaceId, pl.AssignmentId, pl.SubmissionId)
 	if err != nil {
 		return err
-	} else if *userRole == domain.MemberRole && user.Id != submittedUserId {
-		return errs.New(errs.ErrFilePerm, "no permission to get file not own", err)
+	} else if !canView {
+		return errs.New(errs.ErrFilePerm, "no permission to get file of submission id %d", pl.SubmissionId)
 	}
 
 	url, err := url.JoinPath(c.filerUrl, path)
@@ -155,3 +163,15 @@ func (c *FileController) GetSubmission(ctx *fiber.Ctx) error {
 	}
 	return proxy.Forward(url)(ctx)
 }
+
+// getProfilePath picks the smallest stored avatar that is not smaller than the requested size
+func getProfilePath(ctx *fiber.Ctx, profileUrl string) string {
+	requested := ctx.QueryInt("size", avatar.Sizes[0])
+	size := avatar.Sizes[0]
+	for _, stored := range avatar.Sizes {
+		if stored >= requested && stored < size {
+			size = stored
+		}
+	}
+	return avatar.Path(profileUrl, size)
+}
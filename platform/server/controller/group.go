@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GroupController struct {
+	validator domain.PayloadValidator
+
+	groupUsecase domain.GroupUsecase
+}
+
+func NewGroupController(
+	validator domain.PayloadValidator,
+	groupUsecase domain.GroupUsecase,
+) *GroupController {
+	return &GroupController{
+		validator:    validator,
+		groupUsecase: groupUsecase,
+	}
+}
+
+func (c *GroupController) List(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	groups, err := c.groupUsecase.List(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, groups)
+}
+
+func (c *GroupController) Create(ctx *fiber.Ctx) error {
+	var pl payload.CreateGroupPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	group, err := c.groupUsecase.Create(user.Id, pl.WorkspaceId, pl.Name)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, group)
+}
+
+func (c *GroupController) Update(ctx *fiber.Ctx) error {
+	var pl payload.UpdateGroupPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.groupUsecase.Update(user.Id, pl.WorkspaceId, pl.GroupId, pl.Name); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"updated_at": time.Now(),
+	})
+}
+
+func (c *GroupController) Delete(ctx *fiber.Ctx) error {
+	var pl payload.GroupPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.groupUsecase.Delete(user.Id, pl.WorkspaceId, pl.GroupId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"deleted_at": time.Now(),
+	})
+}
+
+func (c *GroupController) ListMember(ctx *fiber.Ctx) error {
+	var pl payload.GroupPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	members, err := c.groupUsecase.ListMember(user.Id, pl.WorkspaceId, pl.GroupId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, members)
+}
+
+func (c *GroupController) AddMember(ctx *fiber.Ctx) error {
+	var pl payload.GroupUserPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.groupUsecase.AddMember(user.Id, pl.WorkspaceId, pl.GroupId, pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"added_at": time.Now(),
+	})
+}
+
+func (c *GroupController) RemoveMember(ctx *fiber.Ctx) error {
+	var pl payload.GroupUserPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.groupUsecase.RemoveMember(user.Id, pl.WorkspaceId, pl.GroupId, pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"removed_at": time.Now(),
+	})
+}
+
+func (c *GroupController) AddManager(ctx *fiber.Ctx) error {
+	var pl payload.GroupUserPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.groupUsecase.AddManager(user.Id, pl.WorkspaceId, pl.GroupId, pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"added_at": time.Now(),
+	})
+}
+
+func (c *GroupController) RemoveManager(ctx *fiber.Ctx) error {
+	var pl payload.GroupUserPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.groupUsecase.RemoveManager(user.Id, pl.WorkspaceId, pl.GroupId, pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"removed_at": time.Now(),
+	})
+}
+
+func (c *GroupController) ListSchedule(ctx *fiber.Ctx) error {
+	var pl payload.GroupPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	schedules, err := c.groupUsecase.ListSchedule(user.Id, pl.WorkspaceId, pl.GroupId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, schedules)
+}
+
+func (c *GroupController) SetSchedule(ctx *fiber.Ctx) error {
+	var pl payload.SetGroupSchedulePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	schedule := &domain.GroupSchedule{
+		AssignmentId: pl.AssignmentId,
+		GroupId:      pl.GroupId,
+		PublishDate:  pl.PublishDate,
+		DueDate:      pl.DueDate,
+	}
+	if err := c.groupUsecase.SetSchedule(user.Id, pl.WorkspaceId, schedule); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, schedule)
+}
+
+func (c *GroupController) DeleteSchedule(ctx *fiber.Ctx) error {
+	var pl payload.GroupSchedulePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.groupUsecase.DeleteSchedule(user.Id, pl.WorkspaceId, pl.GroupId, pl.AssignmentId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"deleted_at": time.Now(),
+	})
+}
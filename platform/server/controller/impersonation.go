@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImpersonationController struct {
+	validator domain.PayloadValidator
+
+	impersonationUsecase domain.ImpersonationUsecase
+}
+
+func NewImpersonationController(
+	validator domain.PayloadValidator,
+	impersonationUsecase domain.ImpersonationUsecase,
+) *ImpersonationController {
+	return &ImpersonationController{
+		validator:            validator,
+		impersonationUsecase: impersonationUsecase,
+	}
+}
+
+// Start godoc
+//
+// @Summary 		Start impersonation
+// @Description View a workspace as one of its members, impersonated requests are read-only and logged
+// @Tags 				workspace
+// @Produce 		json
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/participants/{userId}/impersonation [post]
+func (c *ImpersonationController) Start(ctx *fiber.Ctx) error {
+	var pl payload.WorkspaceParticipantPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	cookie, err := c.impersonationUsecase.Start(user.Id, pl.WorkspaceId, pl.UserId, ctx.IP(), ctx.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		return err
+	}
+	ctx.Cookie(cookie)
+
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, fiber.Map{
+		"expired_at": cookie.Expires,
+	})
+}
+
+// Stop godoc
+//
+// @Summary 		Stop impersonation
+// @Description End the current impersonation and return to the signed in user
+// @Tags 				auth
+// @Produce 		json
+// @Router 			/auth/impersonation [delete]
+func (c *ImpersonationController) Stop(ctx *fiber.Ctx) error {
+	cookie, err := c.impersonationUsecase.Stop(ctx.Cookies(constant.ImpersonationCookieName))
+	if err != nil {
+		return err
+	}
+	ctx.Cookie(cookie)
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"stopped_at": time.Now(),
+	})
+}
+
+// List godoc
+//
+// @Summary 		List impersonations
+// @Description List impersonations made in a workspace for auditing
+// @Tags 				workspace
+// @Produce 		json
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/impersonations [get]
+func (c *ImpersonationController) List(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	impersonations, err := c.impersonationUsecase.List(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, impersonations)
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JoinRequestController struct {
+	validator domain.PayloadValidator
+
+	joinRequestUsecase domain.JoinRequestUsecase
+}
+
+func NewJoinRequestController(
+	validator domain.PayloadValidator,
+	joinRequestUsecase domain.JoinRequestUsecase,
+) *JoinRequestController {
+	return &JoinRequestController{
+		validator:          validator,
+		joinRequestUsecase: joinRequestUsecase,
+	}
+}
+
+// Join responds with the joined workspace, or with the pending request
+// when the workspace requires approval
+func (c *JoinRequestController) Join(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+	invitationCode := ctx.Params("invitationId")
+
+	workspace, request, err := c.joinRequestUsecase.Join(user.Id, invitationCode)
+	if err != nil {
+		return err
+	}
+
+	if request != nil {
+		return response.NewSuccessResponse(ctx, fiber.StatusAccepted, request)
+	}
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, workspace)
+}
+
+func (c *JoinRequestController) Request(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	request, err := c.joinRequestUsecase.Request(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusAccepted, request)
+}
+
+func (c *JoinRequestController) List(ctx *fiber.Ctx) error {
+	var pl payload.ListJoinRequestPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	var status *domain.JoinRequestStatus
+	if pl.Status != nil {
+		value := domain.JoinRequestStatus(*pl.Status)
+		status = &value
+	}
+
+	requests, err := c.joinRequestUsecase.List(user.Id, pl.WorkspaceId, status)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, requests)
+}
+
+func (c *JoinRequestController) Approve(ctx *fiber.Ctx) error {
+	var pl payload.JoinRequestPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.joinRequestUsecase.Approve(user.Id, pl.WorkspaceId, pl.RequestId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"approved_at": time.Now(),
+	})
+}
+
+func (c *JoinRequestController) Reject(ctx *fiber.Ctx) error {
+	var pl payload.JoinRequestPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.joinRequestUsecase.Reject(user.Id, pl.WorkspaceId, pl.RequestId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"rejected_at": time.Now(),
+	})
+}
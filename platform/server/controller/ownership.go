@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OwnershipTransferController struct {
+	validator domain.PayloadValidator
+
+	ownershipTransferUsecase domain.OwnershipTransferUsecase
+}
+
+func NewOwnershipTransferController(
+	validator domain.PayloadValidator,
+	ownershipTransferUsecase domain.OwnershipTransferUsecase,
+) *OwnershipTransferController {
+	return &OwnershipTransferController{
+		validator:                validator,
+		ownershipTransferUsecase: ownershipTransferUsecase,
+	}
+}
+
+func (c *OwnershipTransferController) List(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	transfers, err := c.ownershipTransferUsecase.List(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, transfers)
+}
+
+func (c *OwnershipTransferController) Offer(ctx *fiber.Ctx) error {
+	var pl payload.OfferOwnershipPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	transfer, err := c.ownershipTransferUsecase.Offer(user.Id, pl.WorkspaceId, pl.UserId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, transfer)
+}
+
+func (c *OwnershipTransferController) Accept(ctx *fiber.Ctx) error {
+	var pl payload.OwnershipTransferPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.ownershipTransferUsecase.Accept(user.Id, pl.WorkspaceId, pl.TransferId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"accepted_at": time.Now(),
+	})
+}
+
+func (c *OwnershipTransferController) Decline(ctx *fiber.Ctx) error {
+	var pl payload.OwnershipTransferPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.ownershipTransferUsecase.Decline(user.Id, pl.WorkspaceId, pl.TransferId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"declined_at": time.Now(),
+	})
+}
+
+func (c *OwnershipTransferController) Cancel(ctx *fiber.Ctx) error {
+	var pl payload.OwnershipTransferPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.ownershipTransferUsecase.Cancel(user.Id, pl.WorkspaceId, pl.TransferId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"cancelled_at": time.Now(),
+	})
+}
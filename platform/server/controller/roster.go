@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RosterController struct {
+	validator domain.PayloadValidator
+
+	rosterUsecase domain.RosterUsecase
+}
+
+func NewRosterController(
+	validator domain.PayloadValidator,
+	rosterUsecase domain.RosterUsecase,
+) *RosterController {
+	return &RosterController{
+		validator:     validator,
+		rosterUsecase: rosterUsecase,
+	}
+}
+
+func (c *RosterController) Import(ctx *fiber.Ctx) error {
+	var pl payload.ImportRosterPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+	defer pl.Roster.Close()
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	report, err := c.rosterUsecase.Import(user.Id, pl.WorkspaceId, pl.Roster)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, report)
+}
+
+func (c *RosterController) ListPendingInvitation(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	invitations, err := c.rosterUsecase.ListPendingInvitation(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, invitations)
+}
+
+func (c *RosterController) DeletePendingInvitation(ctx *fiber.Ctx) error {
+	var pl payload.PendingInvitationPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.rosterUsecase.DeletePendingInvitation(user.Id, pl.WorkspaceId, pl.PendingId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"deleted_at": time.Now(),
+	})
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionController struct {
+	validator domain.PayloadValidator
+
+	sessionUsecase domain.SessionUsecase
+}
+
+func NewSessionController(
+	validator domain.PayloadValidator,
+	sessionUsecase domain.SessionUsecase,
+) *SessionController {
+	return &SessionController{
+		validator:      validator,
+		sessionUsecase: sessionUsecase,
+	}
+}
+
+// List godoc
+//
+// @Summary 		List sessions
+// @Description List live sessions of the authenticated user with the current one flagged
+// @Tags 				auth
+// @Produce 		json
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/auth/sessions [get]
+func (c *SessionController) List(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	currentId, err := c.sessionUsecase.Unsign(ctx.Cookies(constant.SessionCookieName))
+	if err != nil {
+		return err
+	}
+
+	sessions, err := c.sessionUsecase.List(user.Id, currentId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, sessions)
+}
+
+// Revoke godoc
+//
+// @Summary 		Revoke a session
+// @Description Sign out a session of the authenticated user, e.g. from another device
+// @Tags 				auth
+// @Produce 		json
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/auth/sessions/{sessionId} [delete]
+func (c *SessionController) Revoke(ctx *fiber.Ctx) error {
+	var pl payload.SessionPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	currentId, err := c.sessionUsecase.Unsign(ctx.Cookies(constant.SessionCookieName))
+	if err != nil {
+		return err
+	}
+
+	if err := c.sessionUsecase.Revoke(user.Id, pl.SessionId); err != nil {
+		return err
+	}
+
+	// Revoking the current session is the same as signing out
+	if pl.SessionId == currentId {
+		ctx.ClearCookie(constant.SessionCookieName)
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"revoked_at": time.Now(),
+	})
+}
+
+// RevokeOthers godoc
+//
+// @Summary 		Revoke other sessions
+// @Description Sign out every session of the authenticated user except the current one
+// @Tags 				auth
+// @Produce 		json
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/auth/sessions [delete]
+func (c *SessionController) RevokeOthers(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	currentId, err := c.sessionUsecase.Unsign(ctx.Cookies(constant.SessionCookieName))
+	if err != nil {
+		return err
+	}
+
+	if err := c.sessionUsecase.RevokeOthers(user.Id, currentId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"revoked_at": time.Now(),
+	})
+}
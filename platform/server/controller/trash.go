@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrashController struct {
+	validator domain.PayloadValidator
+
+	trashUsecase domain.TrashUsecase
+}
+
+func NewTrashController(
+	validator domain.PayloadValidator,
+	trashUsecase domain.TrashUsecase,
+) *TrashController {
+	return &TrashController{
+		validator:    validator,
+		trashUsecase: trashUsecase,
+	}
+}
+
+func (c *TrashController) List(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	trash, err := c.trashUsecase.List(user.Id)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, trash)
+}
+
+func (c *TrashController) RestoreWorkspace(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.trashUsecase.RestoreWorkspace(user.Id, pl.WorkspaceId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"restored_at": time.Now(),
+	})
+}
+
+func (c *TrashController) RestoreAssignment(ctx *fiber.Ctx) error {
+	var pl payload.AssignmentPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.trashUsecase.RestoreAssignment(user.Id, pl.WorkspaceId, pl.AssignmentId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"restored_at": time.Now(),
+	})
+}
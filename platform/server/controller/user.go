@@ -1,9 +1,14 @@
 package controller
 
 import (
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
 	"github.com/codern-org/codern/platform/server/middleware"
 	"github.com/codern-org/codern/platform/server/payload"
 	"github.com/codern-org/codern/platform/server/response"
@@ -13,19 +18,72 @@ import (
 type UserController struct {
 	validator domain.PayloadValidator
 
-	userUsecase domain.UserUsecase
+	userUsecase      domain.UserUsecase
+	twoFactorUsecase domain.TwoFactorUsecase
+	apiTokenUsecase  domain.ApiTokenUsecase
+	identityUsecase  domain.IdentityUsecase
+	quotaUsecase     domain.QuotaUsecase
+	accountUsecase   domain.AccountUsecase
 }
 
 func NewUserController(
 	validator domain.PayloadValidator,
 	userUsecase domain.UserUsecase,
+	twoFactorUsecase domain.TwoFactorUsecase,
+	apiTokenUsecase domain.ApiTokenUsecase,
+	identityUsecase domain.IdentityUsecase,
+	quotaUsecase domain.QuotaUsecase,
+	accountUsecase domain.AccountUsecase,
 ) *UserController {
 	return &UserController{
-		validator:   validator,
-		userUsecase: userUsecase,
+		validator:        validator,
+		userUsecase:      userUsecase,
+		twoFactorUsecase: twoFactorUsecase,
+		apiTokenUsecase:  apiTokenUsecase,
+		identityUsecase:  identityUsecase,
+		quotaUsecase:     quotaUsecase,
+		accountUsecase:   accountUsecase,
 	}
 }
 
+func (c *UserController) GetUsage(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	usage, err := c.quotaUsecase.GetUsage(user.Id)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, usage)
+}
+
+func (c *UserController) Export(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	filename := fmt.Sprintf("codern-%s.zip", time.Now().Format("20060102"))
+	return sendArchive(ctx, filename, func(w io.Writer) error {
+		return c.accountUsecase.Export(user.Id, w)
+	})
+}
+
+func (c *UserController) Delete(ctx *fiber.Ctx) error {
+	var pl payload.DeleteAccountPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.accountUsecase.Delete(user.Id, pl.Password); err != nil {
+		return err
+	}
+	ctx.ClearCookie(constant.SessionCookieName)
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"deleted_at": time.Now(),
+	})
+}
+
 func (c *UserController) Update(ctx *fiber.Ctx) error {
 	var pl payload.UpdateUserPayload
 	if ok, err := c.validator.Validate(&pl, ctx); !ok {
@@ -66,3 +124,187 @@ func (c *UserController) UpdatePassword(ctx *fiber.Ctx) error {
 		"updated_at": time.Now(),
 	})
 }
+
+func (c *UserController) EnrollTwoFactor(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	enrollment, err := c.twoFactorUsecase.Enroll(user.Id)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, enrollment)
+}
+
+func (c *UserController) ActivateTwoFactor(ctx *fiber.Ctx) error {
+	var pl payload.TwoFactorCodePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	recoveryCodes, err := c.twoFactorUsecase.Activate(user.Id, pl.Code)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (c *UserController) DisableTwoFactor(ctx *fiber.Ctx) error {
+	var pl payload.DisableTwoFactorPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.twoFactorUsecase.Disable(user.Id, pl.Password, pl.Code); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"updated_at": time.Now(),
+	})
+}
+
+func (c *UserController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	var pl payload.TwoFactorCodePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	recoveryCodes, err := c.twoFactorUsecase.RegenerateRecoveryCodes(user.Id, pl.Code)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (c *UserController) ListApiToken(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	tokens, err := c.apiTokenUsecase.List(user.Id)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, tokens)
+}
+
+func (c *UserController) CreateApiToken(ctx *fiber.Ctx) error {
+	var pl payload.CreateApiTokenPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	token, err := c.apiTokenUsecase.Create(user.Id, pl.Name, domain.ApiTokenScope(pl.Scope), pl.ExpiredAt)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, token)
+}
+
+func (c *UserController) RevokeApiToken(ctx *fiber.Ctx) error {
+	var pl payload.ApiTokenPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.apiTokenUsecase.Revoke(user.Id, pl.TokenId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"revoked_at": time.Now(),
+	})
+}
+
+func (c *UserController) ListIdentity(ctx *fiber.Ctx) error {
+	user := middleware.GetUserFromCtx(ctx)
+
+	identities, err := c.identityUsecase.List(user.Id)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, identities)
+}
+
+func (c *UserController) LinkGoogle(ctx *fiber.Ctx) error {
+	var pl payload.LinkGooglePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	state := ctx.Cookies(constant.OidcStateCookieName)
+	ctx.ClearCookie(constant.OidcStateCookieName)
+	if state == "" || state != pl.State {
+		return errs.New(errs.ErrOidcState, "google auth state does not match the browser that started linking")
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.identityUsecase.LinkGoogle(user.Id, pl.Code, pl.State); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"linked_at": time.Now(),
+	})
+}
+
+func (c *UserController) LinkOidc(ctx *fiber.Ctx) error {
+	var pl payload.LinkOidcPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	state := ctx.Cookies(constant.OidcStateCookieName)
+	ctx.ClearCookie(constant.OidcStateCookieName)
+	if state == "" || state != pl.State {
+		return errs.New(errs.ErrOidcState, "oidc state does not match the browser that started linking")
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+	provider := domain.AuthProvider(strings.ToUpper(pl.Provider))
+
+	if err := c.identityUsecase.LinkOidc(user.Id, provider, pl.Code, pl.State); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"linked_at": time.Now(),
+	})
+}
+
+func (c *UserController) UnlinkIdentity(ctx *fiber.Ctx) error {
+	var pl payload.UnlinkIdentityPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+	provider := domain.AuthProvider(strings.ToUpper(pl.Provider))
+
+	if err := c.identityUsecase.Unlink(user.Id, provider, pl.Email); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"unlinked_at": time.Now(),
+	})
+}
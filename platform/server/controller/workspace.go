@@ -2,6 +2,7 @@ package controller
 
 import (
 	"strings"
+	"time"
 
 	"github.com/codern-org/codern/domain"
 	errs "github.com/codern-org/codern/domain/error"
@@ -15,15 +16,18 @@ type WorkspaceController struct {
 	validator domain.PayloadValidator
 
 	workspaceUsecase domain.WorkspaceUsecase
+	cloneUsecase     domain.CloneUsecase
 }
 
 func NewWorkspaceController(
 	validator domain.PayloadValidator,
 	workspaceUsecase domain.WorkspaceUsecase,
+	cloneUsecase domain.CloneUsecase,
 ) *WorkspaceController {
 	return &WorkspaceController{
 		validator:        validator,
 		workspaceUsecase: workspaceUsecase,
+		cloneUsecase:     cloneUsecase,
 	}
 }
 
@@ -162,12 +166,12 @@ func (c *WorkspaceController) Get(ctx *fiber.Ctx) error {
 }
 
 func (c *WorkspaceController) GetScoreboard(ctx *fiber.Ctx) error {
-	var pl payload.WorkspacePath
+	var pl payload.ScoreboardPayload
 	if ok, err := c.validator.Validate(&pl, ctx); !ok {
 		return err
 	}
 
-	scoreboard, err := c.workspaceUsecase.GetScoreboard(pl.WorkspaceId)
+	scoreboard, err := c.workspaceUsecase.GetScoreboard(pl.WorkspaceId, pl.GroupId)
 	if err != nil {
 		return err
 	}
@@ -186,8 +190,14 @@ func (c *WorkspaceController) CreateInvitation(ctx *fiber.Ctx) error {
 	id, err := c.workspaceUsecase.CreateInvitation(
 		pl.WorkspaceId,
 		user.Id,
-		pl.ValidAt,
-		pl.ValidUntil,
+		&domain.CreateInvitation{
+			ValidAt:       pl.ValidAt,
+			ValidUntil:    pl.ValidUntil,
+			Role:          domain.WorkspaceRole(pl.Role),
+			MaxUses:       pl.MaxUses,
+			AllowedDomain: pl.AllowedDomain,
+			Emails:        pl.Emails,
+		},
 	)
 	if err != nil {
 		return err
@@ -202,7 +212,9 @@ func (c *WorkspaceController) GetInvitations(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	invitations, err := c.workspaceUsecase.GetInvitations(pl.WorkspaceId)
+	user := middleware.GetUserFromCtx(ctx)
+
+	invitations, err := c.workspaceUsecase.GetInvitations(user.Id, pl.WorkspaceId)
 	if err != nil {
 		return err
 	}
@@ -210,6 +222,22 @@ func (c *WorkspaceController) GetInvitations(ctx *fiber.Ctx) error {
 	return response.NewSuccessResponse(ctx, fiber.StatusOK, invitations)
 }
 
+func (c *WorkspaceController) ListRedemption(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	redemptions, err := c.workspaceUsecase.ListRedemption(user.Id, pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, redemptions)
+}
+
 func (c *WorkspaceController) DeleteInvitation(ctx *fiber.Ctx) error {
 	user := middleware.GetUserFromCtx(ctx)
 	invitationId := ctx.Params("invitationId")
@@ -222,16 +250,41 @@ func (c *WorkspaceController) DeleteInvitation(ctx *fiber.Ctx) error {
 	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
 }
 
-func (c *WorkspaceController) JoinByInvitationCode(ctx *fiber.Ctx) error {
+func (c *WorkspaceController) ListDiscoverable(ctx *fiber.Ctx) error {
 	user := middleware.GetUserFromCtx(ctx)
-	invitationCode := ctx.Params("invitationId")
 
-	workspace, err := c.workspaceUsecase.JoinByInvitation(user.Id, invitationCode)
+	workspaces, err := c.workspaceUsecase.ListDiscoverable(user.Id)
 	if err != nil {
 		return err
 	}
 
-	return response.NewSuccessResponse(ctx, fiber.StatusOK, workspace)
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, workspaces)
+}
+
+// Clone copies the workspace into a new one owned by the current user,
+// the assignment dates are shifted by the given number of days
+func (c *WorkspaceController) Clone(ctx *fiber.Ctx) error {
+	var pl payload.CloneWorkspacePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	workspace, err := c.cloneUsecase.Clone(
+		user.Id,
+		pl.WorkspaceId,
+		&domain.CloneWorkspace{
+			Name:          pl.Name,
+			DateOffset:    time.Duration(pl.OffsetDays) * 24 * time.Hour,
+			IncludeAdmins: pl.IncludeAdmins,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusCreated, workspace)
 }
 
 func (c *WorkspaceController) Update(ctx *fiber.Ctx) error {
@@ -252,9 +305,11 @@ func (c *WorkspaceController) Update(ctx *fiber.Ctx) error {
 		user.Id,
 		pl.WorkspaceId,
 		&domain.UpdateWorkspace{
-			Name:    pl.Name,
-			Profile: pl.Profile,
-			Archive: pl.Archive,
+			Name:         pl.Name,
+			Profile:      pl.Profile,
+			Archive:      pl.Archive,
+			JoinApproval: pl.JoinApproval,
+			Discoverable: pl.Discoverable,
 		},
 	); err != nil {
 		return err
@@ -14,6 +14,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cache"
 	"github.com/gofiber/fiber/v2/middleware/favicon"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/gofiber/swagger"
 	"go.uber.org/zap"
 
@@ -94,22 +95,43 @@ func (s *FiberServer) applyRoutes() {
 
 	// Initialize Middlewares
 	fileMiddleware := middleware.NewFileMiddleware()
-	authMiddleware := middleware.NewAuthMiddleware(validator, s.usecase.Auth)
-	publishableWorkspaceMiddleware := middleware.NewPublishableWorkspaceMiddleware(validator, s.usecase.Auth, s.usecase.Workspace)
+	authMiddleware := middleware.NewAuthMiddleware(validator, s.usecase.Auth, s.usecase.Impersonation)
+	sessionOnlyMiddleware := middleware.NewSessionOnlyMiddleware()
+	adminMiddleware := middleware.NewAdminMiddleware()
+	publishableWorkspaceMiddleware := middleware.NewPublishableWorkspaceMiddleware(
+		validator, s.usecase.Auth, s.usecase.Impersonation, s.usecase.Workspace,
+	)
 	workspaceMiddleware := middleware.NewWorkspaceMiddleware(validator, s.usecase.Workspace)
-	scoreboardMiddleware := middleware.NewScoreboardMiddleware(validator, s.usecase.Auth, s.usecase.Workspace)
+	scoreboardMiddleware := middleware.NewScoreboardMiddleware(
+		validator, s.usecase.Auth, s.usecase.Impersonation, s.usecase.Workspace,
+	)
 
 	// Initialize Controllers
 	healtController := controller.NewHealthController(s.cfg)
 	webSocketController := controller.NewWebSocketController(s.platform.WebSocketHub)
-	fileController := controller.NewFileController(s.cfg, validator, s.usecase.Workspace)
+	fileController := controller.NewFileController(s.cfg, validator, s.usecase.Workspace, s.usecase.Assignment)
 	authController := controller.NewAuthController(
 		s.cfg, validator, s.usecase.Auth, s.usecase.Google, s.usecase.User,
+		s.usecase.EmailVerification, s.usecase.PasswordReset, s.usecase.Oidc,
 	)
-	workspaceController := controller.NewWorkspaceController(validator, s.usecase.Workspace)
+	sessionController := controller.NewSessionController(validator, s.usecase.Session)
+	workspaceController := controller.NewWorkspaceController(validator, s.usecase.Workspace, s.usecase.Clone)
 	assignmentController := controller.NewAssignmentController(validator, s.usecase.Assignment)
-	userController := controller.NewUserController(validator, s.usecase.User)
+	userController := controller.NewUserController(
+		validator, s.usecase.User, s.usecase.TwoFactor, s.usecase.ApiToken, s.usecase.Identity, s.usecase.Quota,
+		s.usecase.Account,
+	)
 	surveyController := controller.NewSurveyController(validator, s.usecase.Survey)
+	adminController := controller.NewAdminController(validator, s.usecase.Admin, s.usecase.SignInThrottle)
+	impersonationController := controller.NewImpersonationController(validator, s.usecase.Impersonation)
+	rosterController := controller.NewRosterController(validator, s.usecase.Roster)
+	groupController := controller.NewGroupController(validator, s.usecase.Group)
+	customRoleController := controller.NewCustomRoleController(validator, s.usecase.CustomRole, s.usecase.Workspace)
+	joinRequestController := controller.NewJoinRequestController(validator, s.usecase.JoinRequest)
+	ownershipTransferController := controller.NewOwnershipTransferController(validator, s.usecase.OwnershipTransfer)
+	archiveController := controller.NewArchiveController(validator, s.usecase.Archive)
+	trashController := controller.NewTrashController(validator, s.usecase.Trash)
+	announcementController := controller.NewAnnouncementController(validator, s.usecase.Announcement)
 
 	// Initialize Routes
 	api := s.app.Group("/")
@@ -122,24 +144,69 @@ func (s *FiberServer) applyRoutes() {
 	auth.Get("/me", authMiddleware, authController.Me)
 	auth.Get("/signout", authMiddleware, authController.SignOut)
 	auth.Post("/signin", authController.SignIn)
+	auth.Post("/signin/2fa", authController.SignInWithTwoFactor)
+	auth.Post("/signup", authController.SignUp)
+	auth.Post("/email/verification", authMiddleware, authController.SendEmailVerification)
+	auth.Post("/email/verify", authController.VerifyEmail)
+	auth.Post("/password/forgot", authController.ForgotPassword)
+	auth.Post("/password/reset", authController.ResetPassword)
 	auth.Get("/google", authController.GetGoogleAuthUrl)
 	auth.Get("/google/callback", authController.SignInWithGoogle)
+	auth.Get("/oidc", authController.ListOidcProviders)
+	auth.Get("/oidc/:provider", authController.GetOidcAuthUrl)
+	auth.Get("/oidc/:provider/callback", authController.SignInWithOidc)
+	auth.Get("/sessions", authMiddleware, sessionOnlyMiddleware, sessionController.List)
+	auth.Delete("/sessions", authMiddleware, sessionOnlyMiddleware, sessionController.RevokeOthers)
+	auth.Delete("/sessions/:sessionId", authMiddleware, sessionOnlyMiddleware, sessionController.Revoke)
+	auth.Delete("/impersonation", impersonationController.Stop)
 
 	user := api.Group("/users", middleware.PathType("user"))
+	user.Get("/me/usage", authMiddleware, userController.GetUsage)
+	user.Get("/me/export", authMiddleware, sessionOnlyMiddleware, userController.Export)
+	user.Delete("/me", authMiddleware, sessionOnlyMiddleware, userController.Delete)
 	user.Patch("/", authMiddleware, userController.Update)
-	user.Patch("/password", authMiddleware, userController.UpdatePassword)
+	user.Patch("/password", authMiddleware, sessionOnlyMiddleware, userController.UpdatePassword)
+	user.Post("/2fa/enroll", authMiddleware, sessionOnlyMiddleware, userController.EnrollTwoFactor)
+	user.Post("/2fa/activate", authMiddleware, sessionOnlyMiddleware, userController.ActivateTwoFactor)
+	user.Post("/2fa/disable", authMiddleware, sessionOnlyMiddleware, userController.DisableTwoFactor)
+	user.Post("/2fa/recovery-codes", authMiddleware, sessionOnlyMiddleware, userController.RegenerateRecoveryCodes)
+	user.Get("/tokens", authMiddleware, sessionOnlyMiddleware, userController.ListApiToken)
+	user.Post("/tokens", authMiddleware, sessionOnlyMiddleware, userController.CreateApiToken)
+	user.Delete("/tokens/:tokenId", authMiddleware, sessionOnlyMiddleware, userController.RevokeApiToken)
+	user.Get("/identities", authMiddleware, sessionOnlyMiddleware, userController.ListIdentity)
+	user.Post("/identities/google", authMiddleware, sessionOnlyMiddleware, userController.LinkGoogle)
+	user.Post("/identities/oidc/:provider", authMiddleware, sessionOnlyMiddleware, userController.LinkOidc)
+	user.Delete("/identities/:provider", authMiddleware, sessionOnlyMiddleware, userController.UnlinkIdentity)
 
 	workspace := api.Group("/workspaces", middleware.PathType("workspace"))
-	workspace.Get("/join/:invitationId", authMiddleware, workspaceController.JoinByInvitationCode)
+	workspace.Get("/join/:invitationId", authMiddleware, joinRequestController.Join)
+	workspace.Get("/discover", authMiddleware, workspaceController.ListDiscoverable)
 	workspace.Get("/", authMiddleware, workspaceMiddleware, workspaceController.List)
 	workspace.Post("/", authMiddleware, workspaceMiddleware, workspaceController.Create)
+	workspace.Post("/import", authMiddleware, archiveController.Import)
+
+	// Trashed assignments fail the workspace middleware, ownership is checked by the usecase
+	trash := workspace.Group("/trash", middleware.PathType("trash"))
+	trash.Get("/", authMiddleware, trashController.List)
+	trash.Post("/:workspaceId/restore", authMiddleware, trashController.RestoreWorkspace)
+	trash.Post("/:workspaceId/assignments/:assignmentId/restore", authMiddleware, trashController.RestoreAssignment)
+
 	workspace.Patch("/:workspaceId", authMiddleware, workspaceMiddleware, workspaceController.Update)
 	workspace.Delete("/:workspaceId", authMiddleware, workspaceMiddleware, workspaceController.Delete)
+	workspace.Post("/:workspaceId/clone", authMiddleware, workspaceMiddleware, workspaceController.Clone)
+	workspace.Get("/:workspaceId/export", authMiddleware, workspaceMiddleware, archiveController.Export)
 	workspace.Get("/:workspaceId", publishableWorkspaceMiddleware, workspaceController.Get)
 	workspace.Get("/:workspaceId/participants", authMiddleware, workspaceMiddleware, workspaceController.ListParticipant)
+	workspace.Post("/:workspaceId/participants/import", authMiddleware, workspaceMiddleware, rosterController.Import)
 	workspace.Patch("/:workspaceId/participants/:userId", authMiddleware, workspaceMiddleware, workspaceController.UpdateParticipant)
 	workspace.Delete("/:workspaceId/participants/:userId", authMiddleware, workspaceMiddleware, workspaceController.DeleteParticipant)
-	workspace.Get("/:workspaceId/scoreboard", scoreboardMiddleware, cache.New(), workspaceController.GetScoreboard)
+	workspace.Patch("/:workspaceId/participants/:userId/custom-role", authMiddleware, workspaceMiddleware, customRoleController.Assign)
+	workspace.Post("/:workspaceId/participants/:userId/impersonation", authMiddleware, sessionOnlyMiddleware, workspaceMiddleware, impersonationController.Start)
+	workspace.Get("/:workspaceId/impersonations", authMiddleware, workspaceMiddleware, impersonationController.List)
+	workspace.Get("/:workspaceId/scoreboard", scoreboardMiddleware, cache.New(cache.Config{
+		// Scoreboards filtered by group are cached separately
+		KeyGenerator: func(ctx *fiber.Ctx) string { return utils.CopyString(ctx.OriginalURL()) },
+	}), workspaceController.GetScoreboard)
 
 	assignment := workspace.Group("/:workspaceId/assignments")
 	assignment.Get("/", authMiddleware, workspaceMiddleware, assignmentController.List)
@@ -150,10 +217,70 @@ func (s *FiberServer) applyRoutes() {
 	assignment.Get("/:assignmentId/submissions", authMiddleware, workspaceMiddleware, assignmentController.ListSubmission)
 	assignment.Post("/:assignmentId/submissions", authMiddleware, workspaceMiddleware, assignmentController.CreateSubmission)
 
+	workspace.Get("/:workspaceId/permissions", authMiddleware, workspaceMiddleware, customRoleController.GetPermissions)
+
+	role := workspace.Group("/:workspaceId/roles", middleware.PathType("role"))
+	role.Get("/", authMiddleware, workspaceMiddleware, customRoleController.List)
+	role.Post("/", authMiddleware, workspaceMiddleware, customRoleController.Create)
+	role.Patch("/:roleId", authMiddleware, workspaceMiddleware, customRoleController.Update)
+	role.Delete("/:roleId", authMiddleware, workspaceMiddleware, customRoleController.Delete)
+
+	group := workspace.Group("/:workspaceId/groups", middleware.PathType("group"))
+	group.Get("/", authMiddleware, workspaceMiddleware, groupController.List)
+	group.Post("/", authMiddleware, workspaceMiddleware, groupController.Create)
+	group.Patch("/:groupId", authMiddleware, workspaceMiddleware, groupController.Update)
+	group.Delete("/:groupId", authMiddleware, workspaceMiddleware, groupController.Delete)
+	group.Get("/:groupId/members", authMiddleware, workspaceMiddleware, groupController.ListMember)
+	group.Post("/:groupId/members/:userId", authMiddleware, workspaceMiddleware, groupController.AddMember)
+	group.Delete("/:groupId/members/:userId", authMiddleware, workspaceMiddleware, groupController.RemoveMember)
+	group.Post("/:groupId/managers/:userId", authMiddleware, workspaceMiddleware, groupController.AddManager)
+	group.Delete("/:groupId/managers/:userId", authMiddleware, workspaceMiddleware, groupController.RemoveManager)
+	group.Get("/:groupId/schedules", authMiddleware, workspaceMiddleware, groupController.ListSchedule)
+	group.Post("/:groupId/schedules/:assignmentId", authMiddleware, workspaceMiddleware, groupController.SetSchedule)
+	group.Delete("/:groupId/schedules/:assignmentId", authMiddleware, workspaceMiddleware, groupController.DeleteSchedule)
+
+	joinRequest := workspace.Group("/:workspaceId/join-requests", middleware.PathType("join-request"))
+	joinRequest.Post("/", authMiddleware, joinRequestController.Request)
+	joinRequest.Get("/", authMiddleware, workspaceMiddleware, joinRequestController.List)
+	joinRequest.Post("/:requestId/approve", authMiddleware, workspaceMiddleware, joinRequestController.Approve)
+	joinRequest.Post("/:requestId/reject", authMiddleware, workspaceMiddleware, joinRequestController.Reject)
+
+	transfer := workspace.Group("/:workspaceId/ownership-transfers", middleware.PathType("ownership-transfer"))
+	transfer.Get("/", authMiddleware, workspaceMiddleware, ownershipTransferController.List)
+	transfer.Post("/", authMiddleware, workspaceMiddleware, ownershipTransferController.Offer)
+	transfer.Post("/:transferId/accept", authMiddleware, workspaceMiddleware, ownershipTransferController.Accept)
+	transfer.Post("/:transferId/decline", authMiddleware, workspaceMiddleware, ownershipTransferController.Decline)
+	transfer.Post("/:transferId/cancel", authMiddleware, workspaceMiddleware, ownershipTransferController.Cancel)
+
+	announcement := workspace.Group("/:workspaceId/announcements", middleware.PathType("announcement"))
+	announcement.Get("/", authMiddleware, workspaceMiddleware, announcementController.List)
+	announcement.Post("/", authMiddleware, workspaceMiddleware, announcementController.Create)
+	announcement.Get("/unread", authMiddleware, workspaceMiddleware, announcementController.CountUnread)
+	announcement.Get("/:announcementId", authMiddleware, workspaceMiddleware, announcementController.Get)
+	announcement.Patch("/:announcementId", authMiddleware, workspaceMiddleware, announcementController.Update)
+	announcement.Delete("/:announcementId", authMiddleware, workspaceMiddleware, announcementController.Delete)
+	announcement.Post("/:announcementId/pin", authMiddleware, workspaceMiddleware, announcementController.Pin)
+	announcement.Delete("/:announcementId/pin", authMiddleware, workspaceMiddleware, announcementController.Unpin)
+	announcement.Post("/:announcementId/read", authMiddleware, workspaceMiddleware, announcementController.MarkRead)
+
 	invitation := workspace.Group("/:workspaceId/invitation", middleware.PathType("invitation"))
 	invitation.Get("/", authMiddleware, workspaceMiddleware, workspaceController.GetInvitations)
 	invitation.Post("/", authMiddleware, workspaceMiddleware, workspaceController.CreateInvitation)
 	invitation.Delete("/:invitationId", authMiddleware, workspaceMiddleware, workspaceController.DeleteInvitation)
+	invitation.Get("/redemptions", authMiddleware, workspaceMiddleware, workspaceController.ListRedemption)
+	invitation.Get("/pending", authMiddleware, workspaceMiddleware, rosterController.ListPendingInvitation)
+	invitation.Delete("/pending/:pendingId", authMiddleware, workspaceMiddleware, rosterController.DeletePendingInvitation)
+
+	admin := api.Group("/admin", middleware.PathType("admin"), authMiddleware, sessionOnlyMiddleware, adminMiddleware)
+	admin.Get("/users", adminController.SearchUser)
+	admin.Post("/users/:userId/disable", adminController.DisableUser)
+	admin.Post("/users/:userId/enable", adminController.EnableUser)
+	admin.Post("/users/:userId/signout", adminController.SignOutUser)
+	admin.Delete("/users/:userId", adminController.DeleteUser)
+	admin.Get("/workspaces/:workspaceId", adminController.GetWorkspace)
+	admin.Post("/workspaces/:workspaceId/restore", adminController.RestoreWorkspace)
+	admin.Post("/workspaces/:workspaceId/assignments/:assignmentId/restore", adminController.RestoreAssignment)
+	admin.Get("/signin-attempts", adminController.ListSignInFailure)
 
 	survey := s.app.Group("/survey")
 	survey.Post("/", authMiddleware, surveyController.CreateSurvey)
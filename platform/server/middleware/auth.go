@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"strings"
+
 	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
 	"github.com/codern-org/codern/internal/constant"
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,8 +12,28 @@ import (
 func NewAuthMiddleware(
 	validator domain.PayloadValidator,
 	authUsecase domain.AuthUsecase,
+	impersonationUsecase domain.ImpersonationUsecase,
 ) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
+		if token := getBearerToken(ctx); token != "" {
+			user, apiToken, err := authUsecase.AuthenticateApiToken(token)
+			if err != nil {
+				return err
+			}
+
+			if !isScopeAllowed(apiToken.Scope, ctx.Method()) {
+				return errs.New(
+					errs.ErrApiTokenScope,
+					"api token with %s scope cannot make %s request", apiToken.Scope, ctx.Method(),
+				)
+			}
+
+			ctx.Locals(constant.UserCtxLocal, user)
+			ctx.Locals(constant.ApiTokenCtxLocal, apiToken)
+
+			return ctx.Next()
+		}
+
 		sid, err := validator.ValidateAuth(ctx)
 		if sid == "" {
 			return err
@@ -21,13 +44,114 @@ func NewAuthMiddleware(
 			return err
 		}
 
+		user, err = impersonate(ctx, impersonationUsecase, user)
+		if err != nil {
+			return err
+		}
 		ctx.Locals(constant.UserCtxLocal, user)
 
 		return ctx.Next()
 	}
 }
 
+// NewSessionOnlyMiddleware rejects requests authenticated by an api token,
+// used for account-sensitive routes such as managing the tokens themselves
+func NewSessionOnlyMiddleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if GetApiTokenFromCtx(ctx) != nil {
+			return errs.New(errs.ErrSessionRequired, "this route cannot be accessed with an api token")
+		}
+		return ctx.Next()
+	}
+}
+
+// NewAdminMiddleware only lets site admins through, it must be applied after NewAuthMiddleware
+func NewAdminMiddleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if user := GetUserFromCtx(ctx); user == nil || !user.IsAdmin {
+			return errs.New(errs.ErrAdminRequired, "this route can only be accessed by site admin")
+		}
+		return ctx.Next()
+	}
+}
+
 func GetUserFromCtx(ctx *fiber.Ctx) *domain.User {
 	user, _ := ctx.Locals(constant.UserCtxLocal).(*domain.User)
 	return user
 }
+
+func GetApiTokenFromCtx(ctx *fiber.Ctx) *domain.ApiToken {
+	apiToken, _ := ctx.Locals(constant.ApiTokenCtxLocal).(*domain.ApiToken)
+	return apiToken
+}
+
+func GetImpersonationFromCtx(ctx *fiber.Ctx) *domain.Impersonation {
+	impersonation, _ := ctx.Locals(constant.ImpersonationCtxLocal).(*domain.Impersonation)
+	return impersonation
+}
+
+// impersonate returns the impersonated user when the impersonation cookie is set,
+// otherwise the signed in user. Impersonated requests are read-only, limited to
+// the impersonated workspace and always logged.
+func impersonate(
+	ctx *fiber.Ctx,
+	impersonationUsecase domain.ImpersonationUsecase,
+	user *domain.User,
+) (*domain.User, error) {
+	header := ctx.Cookies(constant.ImpersonationCookieName)
+	if header == "" {
+		return user, nil
+	}
+
+	impersonatedUser, impersonation, err := impersonationUsecase.Authenticate(header, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	method := ctx.Method()
+	path := ctx.OriginalURL()
+
+	var blockedErr error
+	if !isScopeAllowed(domain.ReadScope, method) {
+		blockedErr = errs.New(errs.ErrImpersonationReadOnly, "impersonation cannot make %s request", method)
+	} else if !isImpersonationScope(ctx, impersonation) {
+		blockedErr = errs.New(
+			errs.ErrImpersonationScope,
+			"impersonation is limited to workspace id %d", impersonation.WorkspaceId,
+		)
+	}
+
+	// The request is not served when it cannot be audited
+	if err := impersonationUsecase.Log(impersonation.Id, method, path, blockedErr != nil); err != nil {
+		return nil, err
+	} else if blockedErr != nil {
+		return nil, blockedErr
+	}
+	ctx.Locals(constant.ImpersonationCtxLocal, impersonation)
+	return impersonatedUser, nil
+}
+
+func isImpersonationScope(ctx *fiber.Ctx, impersonation *domain.Impersonation) bool {
+	if ctx.Params("workspaceId") == "" {
+		return ctx.Route().Path == "/auth/me"
+	}
+	workspaceId, err := ctx.ParamsInt("workspaceId")
+	return err == nil && workspaceId == impersonation.WorkspaceId
+}
+
+func getBearerToken(ctx *fiber.Ctx) string {
+	scheme, token, found := strings.Cut(ctx.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func isScopeAllowed(scope domain.ApiTokenScope, method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return scope == domain.ReadScope || scope == domain.WriteScope
+	default:
+		return scope == domain.WriteScope
+	}
+}
@@ -10,6 +10,7 @@ import (
 func NewScoreboardMiddleware(
 	validator domain.PayloadValidator,
 	authUsecase domain.AuthUsecase,
+	impersonationUsecase domain.ImpersonationUsecase,
 	workspaceUsecase domain.WorkspaceUsecase,
 ) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
@@ -34,6 +35,10 @@ func NewScoreboardMiddleware(
 			if err != nil {
 				return errs.New(errs.SameCode, "cannot get user to get scoreboard", err)
 			}
+			user, err = impersonate(ctx, impersonationUsecase, user)
+			if err != nil {
+				return err
+			}
 
 			ok, err := workspaceUsecase.HasUser(user.Id, pl.WorkspaceId)
 			if !ok {
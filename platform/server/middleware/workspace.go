@@ -11,6 +11,7 @@ import (
 func NewPublishableWorkspaceMiddleware(
 	validator domain.PayloadValidator,
 	authUsecase domain.AuthUsecase,
+	impersonationUsecase domain.ImpersonationUsecase,
 	workspaceUsecase domain.WorkspaceUsecase,
 ) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
@@ -35,6 +36,12 @@ func NewPublishableWorkspaceMiddleware(
 		if !workspace.IsOpenScoreboard && err != nil {
 			return err
 		}
+		if user != nil {
+			user, err = impersonate(ctx, impersonationUsecase, user)
+			if err != nil {
+				return err
+			}
+		}
 		ctx.Locals(constant.UserCtxLocal, user)
 
 		return ctx.Next()
@@ -77,9 +84,9 @@ func NewWorkspaceMiddleware(
 			}
 
 			if ctx.Params("testcaseFile") != "" {
-				isAuthorized, err := workspaceUsecase.CheckPerm(user.Id, pl.WorkspaceId)
+				isAuthorized, err := workspaceUsecase.CheckPermission(user.Id, pl.WorkspaceId, domain.GradePerm)
 				if err != nil {
-					return errs.New(errs.SameCode, "cannot get workspace role", err)
+					return errs.New(errs.SameCode, "cannot get workspace permission", err)
 				}
 				if !isAuthorized {
 					return errs.New(errs.ErrWorkspaceNoPerm, "cannot access testcase of assignment id %d", pl.AssignmentId)
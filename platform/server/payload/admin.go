@@ -0,0 +1,19 @@
+package payload
+
+type AdminUserPath struct {
+	UserId string `params:"userId" validate:"required" json:"-"`
+}
+
+type SearchUserPayload struct {
+	Query  string `query:"query"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `query:"offset" validate:"omitempty,min=0"`
+}
+
+type ListSignInAttemptPayload struct {
+	Email     *string `query:"email" validate:"omitempty,email"`
+	IpAddress *string `query:"ip" validate:"omitempty,ip"`
+	Since     int64   `query:"since" validate:"omitempty,min=0"` // Unix timestamp in second unit
+	Limit     int     `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset    int     `query:"offset" validate:"omitempty,min=0"`
+}
@@ -0,0 +1,29 @@
+package payload
+
+import "time"
+
+type AnnouncementPath struct {
+	WorkspacePath
+	AnnouncementId int `params:"announcementId" validate:"required" json:"-"`
+}
+
+type ListAnnouncementPayload struct {
+	WorkspacePath
+	Limit  int `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int `query:"offset" validate:"omitempty,min=0"`
+}
+
+type CreateAnnouncementPayload struct {
+	WorkspacePath
+	Title       string     `json:"title" validate:"required,max=128"`
+	Body        string     `json:"body" validate:"required"`
+	IsPinned    bool       `json:"isPinned"`
+	PublishDate *time.Time `json:"publishDate"`
+}
+
+type UpdateAnnouncementPayload struct {
+	AnnouncementPath
+	Title       *string    `json:"title" validate:"omitempty,min=1,max=128"`
+	Body        *string    `json:"body" validate:"omitempty,min=1"`
+	PublishDate *time.Time `json:"publishDate"`
+}
@@ -0,0 +1,14 @@
+package payload
+
+import "mime/multipart"
+
+type ExportWorkspacePayload struct {
+	WorkspacePath
+	Submissions bool `query:"submissions"`
+}
+
+type ImportWorkspacePayload struct {
+	Name    string         `json:"name"`
+	DryRun  bool           `query:"dryRun"`
+	Archive multipart.File `file:"archive" validate:"required"`
+}
@@ -4,3 +4,40 @@ type SignInPayload struct {
 	Email    string `json:"email" validate:"email,required"`
 	Password string `json:"password" validate:"required"`
 }
+
+type SignInTwoFactorPayload struct {
+	Token string `json:"token" validate:"required"`
+	Code  string `json:"code" validate:"required"`
+}
+
+type SignUpPayload struct {
+	Email    string `json:"email" validate:"email,required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type VerifyEmailPayload struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ForgotPasswordPayload struct {
+	Email string `json:"email" validate:"email,required"`
+}
+
+type ResetPasswordPayload struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type SessionPath struct {
+	SessionId string `params:"sessionId" validate:"required" json:"-"`
+}
+
+type OidcProviderPath struct {
+	Provider string `params:"provider" validate:"required" json:"-"`
+}
+
+type OidcCallbackPayload struct {
+	OidcProviderPath
+	Code  string `query:"code" validate:"required"`
+	State string `query:"state" validate:"required"`
+}
@@ -0,0 +1,23 @@
+package payload
+
+type CustomRolePath struct {
+	WorkspacePath
+	RoleId int `params:"roleId" validate:"required" json:"-"`
+}
+
+type CreateCustomRolePayload struct {
+	WorkspacePath
+	Name        string   `json:"name" validate:"required,max=32"`
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+type UpdateCustomRolePayload struct {
+	CustomRolePath
+	Name        string   `json:"name" validate:"required,max=32"`
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+type AssignCustomRolePayload struct {
+	WorkspaceParticipantPath
+	RoleId *int `json:"roleId"`
+}
@@ -0,0 +1,34 @@
+package payload
+
+import "time"
+
+type GroupPath struct {
+	WorkspacePath
+	GroupId int `params:"groupId" validate:"required" json:"-"`
+}
+
+type GroupUserPath struct {
+	GroupPath
+	UserId string `params:"userId" validate:"required" json:"-"`
+}
+
+type GroupSchedulePath struct {
+	GroupPath
+	AssignmentId int `params:"assignmentId" validate:"required" json:"-"`
+}
+
+type CreateGroupPayload struct {
+	WorkspacePath
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+type UpdateGroupPayload struct {
+	GroupPath
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+type SetGroupSchedulePayload struct {
+	GroupSchedulePath
+	PublishDate *time.Time `json:"publishDate"`
+	DueDate     *time.Time `json:"dueDate"`
+}
@@ -0,0 +1,11 @@
+package payload
+
+type JoinRequestPath struct {
+	WorkspacePath
+	RequestId int `params:"requestId" validate:"required" json:"-"`
+}
+
+type ListJoinRequestPayload struct {
+	WorkspacePath
+	Status *string `query:"status" validate:"omitempty,oneof=PENDING APPROVED REJECTED"`
+}
@@ -0,0 +1,11 @@
+package payload
+
+type OwnershipTransferPath struct {
+	WorkspacePath
+	TransferId int `params:"transferId" validate:"required" json:"-"`
+}
+
+type OfferOwnershipPayload struct {
+	WorkspacePath
+	UserId string `json:"userId" validate:"required"`
+}
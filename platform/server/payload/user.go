@@ -1,6 +1,9 @@
 package payload
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+	"time"
+)
 
 type UpdateUserPayload struct {
 	DisplayName *string        `json:"displayName"`
@@ -11,3 +14,42 @@ type UpdateUserPasswordPayload struct {
 	OldPassword string `json:"oldPassword"`
 	NewPassword string `json:"newPassword"`
 }
+
+type DeleteAccountPayload struct {
+	Password string `json:"password"`
+}
+
+type TwoFactorCodePayload struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorPayload struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type ApiTokenPath struct {
+	TokenId int `params:"tokenId" validate:"required" json:"-"`
+}
+
+type CreateApiTokenPayload struct {
+	Name      string     `json:"name" validate:"required,max=64"`
+	Scope     string     `json:"scope" validate:"required,oneof=READ WRITE"`
+	ExpiredAt *time.Time `json:"expiredAt"`
+}
+
+type LinkGooglePayload struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+type LinkOidcPayload struct {
+	OidcProviderPath
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+type UnlinkIdentityPayload struct {
+	Provider string `params:"provider" validate:"required" json:"-"`
+	Email    string `query:"email" validate:"required,email"`
+}
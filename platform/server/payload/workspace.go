@@ -24,23 +24,52 @@ type CreateWorkspacePayload struct {
 	Profile multipart.File `file:"profile"`
 }
 
+type CloneWorkspacePayload struct {
+	WorkspacePath
+	Name          string `json:"name"`
+	OffsetDays    int    `json:"offsetDays"`
+	IncludeAdmins bool   `json:"includeAdmins"`
+}
+
 type UpdateWorkspacePayload struct {
 	WorkspacePath
-	Name     *string        `json:"name"`
-	Favorite *bool          `json:"favorite"`
-	Archive  *bool          `json:"archive"`
-	Profile  multipart.File `file:"profile"`
+	Name         *string        `json:"name"`
+	Favorite     *bool          `json:"favorite"`
+	Archive      *bool          `json:"archive"`
+	JoinApproval *bool          `json:"joinApproval"`
+	Discoverable *bool          `json:"discoverable"`
+	Profile      multipart.File `file:"profile"`
+}
+
+type ImportRosterPayload struct {
+	WorkspacePath
+	Roster multipart.File `file:"roster" validate:"required"`
+}
+
+type PendingInvitationPath struct {
+	WorkspacePath
+	PendingId int `params:"pendingId" validate:"required" json:"-"`
 }
 
 type CreateInvitationPayload struct {
 	WorkspacePath
-	ValidAt    time.Time `json:"validAt" validate:"required"`
-	ValidUntil time.Time `json:"validUntil" validate:"required"`
+	ValidAt       time.Time `json:"validAt" validate:"required"`
+	ValidUntil    time.Time `json:"validUntil" validate:"required"`
+	Role          string    `json:"role" validate:"omitempty,oneof=MEMBER ADMIN"`
+	MaxUses       *int      `json:"maxUses" validate:"omitempty,min=1"`
+	AllowedDomain *string   `json:"allowedDomain" validate:"omitempty,fqdn"`
+	Emails        []string  `json:"emails" validate:"omitempty,max=1000,dive,email"`
 }
 
 type ListSubmissionPayload struct {
 	AssignmentPath
-	All bool `query:"all"`
+	All     bool `query:"all"`
+	GroupId *int `query:"group"`
+}
+
+type ScoreboardPayload struct {
+	WorkspacePath
+	GroupId *int `query:"group"`
 }
 
 // TODO: Fix panic caused by sending empty body payload to be validate by struct with only pointer
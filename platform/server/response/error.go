@@ -24,17 +24,101 @@ var DomainErrCodeToHttpStatus = map[int]int{
 	errs.ErrCreateSession:     fiber.StatusInternalServerError,
 	errs.ErrGetSession:        fiber.StatusInternalServerError,
 	errs.ErrUnauthenticated:   fiber.StatusUnauthorized,
+	errs.ErrSessionNotFound:   fiber.StatusNotFound,
+	errs.ErrDeleteSession:     fiber.StatusInternalServerError,
 	errs.ErrInvalidEmail:      fiber.StatusBadRequest,
 	errs.ErrDupEmail:          fiber.StatusConflict,
+	errs.ErrEmailDomain:       fiber.StatusForbidden,
+	errs.ErrEmailNotVerified:  fiber.StatusForbidden,
+	errs.ErrEmailVerified:     fiber.StatusConflict,
+	errs.ErrUpdateSession:     fiber.StatusInternalServerError,
 	errs.ErrUserPassword:      fiber.StatusUnauthorized,
+	errs.ErrWeakPassword:      fiber.StatusBadRequest,
 	errs.ErrUserNotFound:      fiber.StatusNotFound,
 	errs.ErrGetUser:           fiber.StatusInternalServerError,
 	errs.ErrCreateUser:        fiber.StatusInternalServerError,
 	errs.ErrGoogleAuth:        fiber.StatusInternalServerError,
+	errs.ErrOidcAuth:          fiber.StatusBadGateway,
+	errs.ErrOidcProvider:      fiber.StatusNotFound,
+	errs.ErrOidcState:         fiber.StatusBadRequest,
+	errs.ErrOidcClaim:         fiber.StatusBadGateway,
+
+	errs.ErrVerificationToken:   fiber.StatusBadRequest,
+	errs.ErrVerificationExpired: fiber.StatusGone,
+	errs.ErrCreateVerification:  fiber.StatusInternalServerError,
+	errs.ErrVerifyEmail:         fiber.StatusInternalServerError,
+
+	errs.ErrPasswordResetLimit:  fiber.StatusTooManyRequests,
+	errs.ErrCreatePasswordReset: fiber.StatusInternalServerError,
+	errs.ErrResetPassword:       fiber.StatusInternalServerError,
+
+	errs.ErrTwoFactorCode:       fiber.StatusUnauthorized,
+	errs.ErrTwoFactorEnabled:    fiber.StatusConflict,
+	errs.ErrTwoFactorNotEnabled: fiber.StatusBadRequest,
+	errs.ErrTwoFactorChallenge:  fiber.StatusUnauthorized,
+	errs.ErrTwoFactorProvider:   fiber.StatusBadRequest,
+	errs.ErrUpdateTwoFactor:     fiber.StatusInternalServerError,
+	errs.ErrCreateChallenge:     fiber.StatusInternalServerError,
+	errs.ErrGetChallenge:        fiber.StatusInternalServerError,
+	errs.ErrUpdateChallenge:     fiber.StatusInternalServerError,
+	errs.ErrDeleteChallenge:     fiber.StatusInternalServerError,
+
+	errs.ErrApiTokenInvalid:       fiber.StatusUnauthorized,
+	errs.ErrApiTokenExpired:       fiber.StatusUnauthorized,
+	errs.ErrApiTokenScope:         fiber.StatusForbidden,
+	errs.ErrApiTokenNotFound:      fiber.StatusNotFound,
+	errs.ErrApiTokenLimit:         fiber.StatusConflict,
+	errs.ErrInvalidApiTokenScope:  fiber.StatusBadRequest,
+	errs.ErrInvalidApiTokenExpiry: fiber.StatusBadRequest,
+	errs.ErrCreateApiToken:        fiber.StatusInternalServerError,
+	errs.ErrGetApiToken:           fiber.StatusInternalServerError,
+	errs.ErrDeleteApiToken:        fiber.StatusInternalServerError,
+	errs.ErrSessionRequired:       fiber.StatusForbidden,
+
+	errs.ErrIdentityLinked:     fiber.StatusConflict,
+	errs.ErrIdentityNotFound:   fiber.StatusNotFound,
+	errs.ErrIdentityPrimary:    fiber.StatusBadRequest,
+	errs.ErrIdentityProvider:   fiber.StatusBadRequest,
+	errs.ErrCreateIdentity:     fiber.StatusInternalServerError,
+	errs.ErrGetIdentity:        fiber.StatusInternalServerError,
+	errs.ErrDeleteIdentity:     fiber.StatusInternalServerError,
+	errs.ErrIdentityUnverified: fiber.StatusBadRequest,
+
+	errs.ErrSignInLocked:       fiber.StatusTooManyRequests,
+	errs.ErrCheckSignInAttempt: fiber.StatusInternalServerError,
+	errs.ErrListSignInAttempt:  fiber.StatusInternalServerError,
+
+	errs.ErrAdminRequired:   fiber.StatusForbidden,
+	errs.ErrUserDisabled:    fiber.StatusForbidden,
+	errs.ErrAdminSelf:       fiber.StatusBadRequest,
+	errs.ErrSearchUser:      fiber.StatusInternalServerError,
+	errs.ErrUpdateUserState: fiber.StatusInternalServerError,
+
+	errs.ErrImpersonationInvalid:  fiber.StatusUnauthorized,
+	errs.ErrImpersonationReadOnly: fiber.StatusForbidden,
+	errs.ErrImpersonationScope:    fiber.StatusForbidden,
+	errs.ErrImpersonationTarget:   fiber.StatusBadRequest,
+	errs.ErrCreateImpersonation:   fiber.StatusInternalServerError,
+	errs.ErrGetImpersonation:      fiber.StatusInternalServerError,
+
+	errs.ErrAccountOwnsWorkspace: fiber.StatusConflict,
+	errs.ErrDeleteAccount:        fiber.StatusInternalServerError,
+	errs.ErrExportAccount:        fiber.StatusInternalServerError,
 
 	errs.ErrGradingRequest: fiber.StatusInternalServerError,
 
-	errs.ErrFilePerm: fiber.StatusForbidden,
+	errs.ErrFilePerm:     fiber.StatusForbidden,
+	errs.ErrInvalidImage: fiber.StatusBadRequest,
+	errs.ErrProcessImage: fiber.StatusInternalServerError,
+
+	errs.ErrSendMail: fiber.StatusInternalServerError,
+
+	errs.ErrWorkspaceQuota:   fiber.StatusForbidden,
+	errs.ErrParticipantQuota: fiber.StatusForbidden,
+	errs.ErrAssignmentQuota:  fiber.StatusForbidden,
+	errs.ErrTestcaseQuota:    fiber.StatusRequestEntityTooLarge,
+	errs.ErrSubmissionQuota:  fiber.StatusTooManyRequests,
+	errs.ErrGetUsage:         fiber.StatusInternalServerError,
 
 	errs.ErrCreateUrlPath: fiber.StatusInternalServerError,
 
@@ -55,6 +139,11 @@ var DomainErrCodeToHttpStatus = map[int]int{
 	errs.ErrUpdateWorkspace:            fiber.StatusInternalServerError,
 	errs.ErrDeleteWorkspace:            fiber.StatusInternalServerError,
 	errs.ErrWorkspaceAlreadyJoin:       fiber.StatusConflict,
+	errs.ErrRestoreWorkspace:           fiber.StatusInternalServerError,
+	errs.ErrCloneWorkspace:             fiber.StatusInternalServerError,
+	errs.ErrExportWorkspace:            fiber.StatusInternalServerError,
+	errs.ErrImportWorkspace:            fiber.StatusInternalServerError,
+	errs.ErrInvalidArchive:             fiber.StatusBadRequest,
 
 	errs.ErrCreateInvitation:      fiber.StatusInternalServerError,
 	errs.ErrGetInvitation:         fiber.StatusInternalServerError,
@@ -62,6 +151,48 @@ var DomainErrCodeToHttpStatus = map[int]int{
 	errs.ErrInvitationNotFound:    fiber.StatusNotFound,
 	errs.ErrInvitationNoPerm:      fiber.StatusForbidden,
 	errs.ErrInvitationInvalidDate: fiber.StatusBadRequest,
+	errs.ErrRosterFormat:          fiber.StatusBadRequest,
+	errs.ErrRosterTooLarge:        fiber.StatusRequestEntityTooLarge,
+	errs.ErrPendingInvitation:     fiber.StatusInternalServerError,
+	errs.ErrPendingNotFound:       fiber.StatusNotFound,
+	errs.ErrInvitationExhausted:   fiber.StatusGone,
+	errs.ErrInvitationEmail:       fiber.StatusForbidden,
+	errs.ErrInvitationRedemption:  fiber.StatusInternalServerError,
+
+	errs.ErrGroupNotFound:    fiber.StatusNotFound,
+	errs.ErrGetGroup:         fiber.StatusInternalServerError,
+	errs.ErrCreateGroup:      fiber.StatusInternalServerError,
+	errs.ErrUpdateGroup:      fiber.StatusInternalServerError,
+	errs.ErrDeleteGroup:      fiber.StatusInternalServerError,
+	errs.ErrGroupMember:      fiber.StatusInternalServerError,
+	errs.ErrGroupInvalidRole: fiber.StatusBadRequest,
+	errs.ErrGroupSchedule:    fiber.StatusInternalServerError,
+	errs.ErrGroupInvalidDate: fiber.StatusBadRequest,
+
+	errs.ErrCustomRoleNotFound: fiber.StatusNotFound,
+	errs.ErrCustomRole:         fiber.StatusInternalServerError,
+	errs.ErrCustomRoleInUse:    fiber.StatusConflict,
+	errs.ErrInvalidPermission:  fiber.StatusBadRequest,
+	errs.ErrCustomRoleTarget:   fiber.StatusBadRequest,
+
+	errs.ErrJoinRequestNotFound:      fiber.StatusNotFound,
+	errs.ErrJoinRequest:              fiber.StatusInternalServerError,
+	errs.ErrJoinRequestPending:       fiber.StatusConflict,
+	errs.ErrJoinRequestReviewed:      fiber.StatusConflict,
+	errs.ErrWorkspaceNotDiscoverable: fiber.StatusForbidden,
+
+	errs.ErrOwnershipTransferNotFound: fiber.StatusNotFound,
+	errs.ErrOwnershipTransfer:         fiber.StatusInternalServerError,
+	errs.ErrOwnershipTransferPending:  fiber.StatusConflict,
+	errs.ErrOwnershipTransferExpired:  fiber.StatusGone,
+	errs.ErrOwnershipTransferTarget:   fiber.StatusBadRequest,
+
+	errs.ErrTrashNotFound: fiber.StatusNotFound,
+	errs.ErrTrash:         fiber.StatusInternalServerError,
+	errs.ErrTrashExpired:  fiber.StatusGone,
+
+	errs.ErrAnnouncementNotFound: fiber.StatusNotFound,
+	errs.ErrAnnouncement:         fiber.StatusInternalServerError,
 
 	errs.ErrGetAssignment:        fiber.StatusInternalServerError,
 	errs.ErrListAssignment:       fiber.StatusInternalServerError,
@@ -69,6 +200,7 @@ var DomainErrCodeToHttpStatus = map[int]int{
 	errs.ErrAssignmentNoTestcase: fiber.StatusInternalServerError,
 	errs.ErrCreateAssignment:     fiber.StatusInternalServerError,
 	errs.ErrUpdateAssignment:     fiber.StatusInternalServerError,
+	errs.ErrRestoreAssignment:    fiber.StatusInternalServerError,
 
 	errs.ErrCreateSubmission:       fiber.StatusInternalServerError,
 	errs.ErrCreateSubmissionResult: fiber.StatusInternalServerError,
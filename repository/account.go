@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
+)
+
+type accountRepository struct {
+	db *platform.MySql
+}
+
+func NewAccountRepository(db *platform.MySql) domain.AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) ListParticipation(userId string) ([]domain.AccountParticipation, error) {
+	participations := make([]domain.AccountParticipation, 0)
+	err := r.db.Select(&participations, `
+		SELECT wp.workspace_id, w.name AS workspace_name, wp.role, wp.joined_at
+		FROM workspace_participant wp
+		INNER JOIN workspace w ON w.id = wp.workspace_id
+		WHERE wp.user_id = ?
+		ORDER BY wp.joined_at
+	`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list account participation: %w", err)
+	}
+	return participations, nil
+}
+
+func (r *accountRepository) ListSubmission(userId string) ([]domain.AccountSubmission, error) {
+	submissions := make([]domain.AccountSubmission, 0)
+	err := r.db.Select(&submissions, `
+		SELECT
+			s.id, a.workspace_id, s.assignment_id, a.name AS assignment_name,
+			s.language, s.status, s.score, s.file_url, s.submitted_at,
+			COALESCE(s.submitted_at > a.due_date, FALSE) AS is_late
+		FROM submission s
+		INNER JOIN assignment a ON a.id = s.assignment_id
+		WHERE s.user_id = ?
+		ORDER BY s.submitted_at
+	`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list account submission: %w", err)
+	}
+	return submissions, nil
+}
+
+// Anonymize scrubs the personal data of the user in place. The user row is kept so
+// participations and submissions still count towards scoreboards.
+func (r *accountRepository) Anonymize(user *domain.User, deletedAt time.Time) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("DELETE FROM sign_in_attempt WHERE email = ?", user.Email)
+		if err != nil {
+			return fmt.Errorf("cannot query to delete sign in attempt of deleted user: %w", err)
+		}
+
+		deleteQueries := []string{
+			"DELETE FROM session WHERE user_id = ?",
+			"DELETE FROM email_verification WHERE user_id = ?",
+			"DELETE FROM password_reset WHERE user_id = ?",
+			"DELETE FROM recovery_code WHERE user_id = ?",
+			"DELETE FROM two_factor_challenge WHERE user_id = ?",
+			"DELETE FROM api_token WHERE user_id = ?",
+			"DELETE FROM user_identity WHERE user_id = ?",
+			"DELETE FROM survey WHERE user_id = ?",
+		}
+		for _, query := range deleteQueries {
+			if _, err := tx.Exec(query, user.Id); err != nil {
+				return fmt.Errorf("cannot query to delete data of deleted user: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(`
+			UPDATE user
+			SET
+				email = ?,
+				password = '',
+				totp_secret = NULL,
+				is_two_factor_enabled = FALSE,
+				display_name = ?,
+				profile_url = '',
+				is_email_verified = FALSE,
+				is_admin = FALSE,
+				is_disabled = TRUE,
+				deleted_at = ?
+			WHERE id = ?
+		`, fmt.Sprintf("%s@deleted.invalid", user.Id), "Deleted user", deletedAt, user.Id)
+		if err != nil {
+			return fmt.Errorf("cannot query to anonymize user: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+// announcementQuery takes the id of the reading user as its first argument
+const announcementQuery = `
+	SELECT
+		an.*,
+		user.display_name AS author_name,
+		user.profile_url AS author_profile_url,
+		EXISTS (
+			SELECT 1 FROM workspace_announcement_read ar
+			WHERE ar.announcement_id = an.id AND ar.user_id = ?
+		) AS is_read
+	FROM workspace_announcement an
+	INNER JOIN user ON user.id = an.author_id
+`
+
+type announcementRepository struct {
+	db *platform.MySql
+}
+
+func NewAnnouncementRepository(db *platform.MySql) domain.AnnouncementRepository {
+	return &announcementRepository{db: db}
+}
+
+func (r *announcementRepository) Create(announcement *domain.Announcement) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO workspace_announcement
+			(id, workspace_id, author_id, title, body, is_pinned, publish_date, notified_at, created_at, updated_at)
+		VALUES
+			(:id, :workspace_id, :author_id, :title, :body, :is_pinned, :publish_date, :notified_at, :created_at, :updated_at)
+	`, announcement)
+	if err != nil {
+		return fmt.Errorf("cannot query to create workspace announcement: %w", err)
+	}
+	return nil
+}
+
+func (r *announcementRepository) Get(id int, userId string) (*domain.Announcement, error) {
+	var announcement domain.Announcement
+	err := r.db.Get(&announcement, announcementQuery+"WHERE an.id = ?", userId, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get workspace announcement: %w", err)
+	}
+	return &announcement, nil
+}
+
+// List returns the pinned announcements first then the latest ones,
+// the scheduled announcements are only included when asked for
+func (r *announcementRepository) List(
+	workspaceId int,
+	userId string,
+	includeScheduled bool,
+	limit int,
+	offset int,
+) ([]domain.Announcement, error) {
+	announcements := make([]domain.Announcement, 0)
+	err := r.db.Select(&announcements, announcementQuery+`
+		WHERE an.workspace_id = ? AND (? OR an.publish_date <= ?)
+		ORDER BY an.is_pinned DESC, an.publish_date DESC
+		LIMIT ? OFFSET ?
+	`, userId, workspaceId, includeScheduled, time.Now(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace announcement: %w", err)
+	}
+	return announcements, nil
+}
+
+// ListDue returns the published announcements that have not been pushed yet
+func (r *announcementRepository) ListDue(before time.Time) ([]domain.Announcement, error) {
+	announcements := make([]domain.Announcement, 0)
+	err := r.db.Select(&announcements, announcementQuery+`
+		INNER JOIN workspace w ON w.id = an.workspace_id AND w.is_deleted = FALSE
+		WHERE an.notified_at IS NULL AND an.publish_date <= ?
+		ORDER BY an.publish_date
+	`, "", before)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list due workspace announcement: %w", err)
+	}
+	return announcements, nil
+}
+
+func (r *announcementRepository) CountUnread(workspaceId int, userId string) (int, error) {
+	var count int
+	err := r.db.Get(&count, `
+		SELECT COUNT(*) FROM workspace_announcement an
+		WHERE an.workspace_id = ? AND an.publish_date <= ? AND NOT EXISTS (
+			SELECT 1 FROM workspace_announcement_read ar
+			WHERE ar.announcement_id = an.id AND ar.user_id = ?
+		)
+	`, workspaceId, time.Now(), userId)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count unread workspace announcement: %w", err)
+	}
+	return count, nil
+}
+
+func (r *announcementRepository) Update(announcement *domain.Announcement) error {
+	_, err := r.db.NamedExec(`
+		UPDATE workspace_announcement
+		SET
+			title = :title,
+			body = :body,
+			is_pinned = :is_pinned,
+			publish_date = :publish_date,
+			notified_at = :notified_at,
+			updated_at = :updated_at
+		WHERE id = :id
+	`, announcement)
+	if err != nil {
+		return fmt.Errorf("cannot query to update workspace announcement: %w", err)
+	}
+	return nil
+}
+
+// MarkNotified claims the push of an announcement, false is returned when
+// it has already been pushed
+func (r *announcementRepository) MarkNotified(id int, notifiedAt time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE workspace_announcement SET notified_at = ? WHERE id = ? AND notified_at IS NULL",
+		notifiedAt, id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to mark workspace announcement as notified: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of notified workspace announcement: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *announcementRepository) MarkRead(id int, userId string) error {
+	_, err := r.db.Exec(`
+		INSERT IGNORE INTO workspace_announcement_read (announcement_id, user_id, read_at)
+		VALUES (?, ?, ?)
+	`, id, userId, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot query to mark workspace announcement as read: %w", err)
+	}
+	return nil
+}
+
+func (r *announcementRepository) Delete(id int) error {
+	_, err := r.db.Exec("DELETE FROM workspace_announcement WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete workspace announcement: %w", err)
+	}
+	return nil
+}
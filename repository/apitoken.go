@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type apiTokenRepository struct {
+	db *platform.MySql
+}
+
+func NewApiTokenRepository(db *platform.MySql) domain.ApiTokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+func (r *apiTokenRepository) Create(token *domain.ApiToken) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO api_token (id, user_id, name, token_hash, hint, scope, created_at, expired_at)
+		VALUES (:id, :user_id, :name, :token_hash, :hint, :scope, :created_at, :expired_at)
+	`, token)
+	if err != nil {
+		return fmt.Errorf("cannot query to create api token: %w", err)
+	}
+	return nil
+}
+
+func (r *apiTokenRepository) Get(id int) (*domain.ApiToken, error) {
+	var token domain.ApiToken
+	err := r.db.Get(&token, "SELECT * FROM api_token WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get api token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *apiTokenRepository) GetByHash(hash string) (*domain.ApiToken, error) {
+	var token domain.ApiToken
+	err := r.db.Get(&token, "SELECT * FROM api_token WHERE token_hash = ?", hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get api token by hash: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *apiTokenRepository) ListByUserId(userId string) ([]domain.ApiToken, error) {
+	tokens := make([]domain.ApiToken, 0)
+	err := r.db.Select(
+		&tokens,
+		"SELECT * FROM api_token WHERE user_id = ? ORDER BY created_at DESC",
+		userId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list api token: %w", err)
+	}
+	return tokens, nil
+}
+
+func (r *apiTokenRepository) CountByUserId(userId string) (int, error) {
+	var count int
+	err := r.db.Get(&count, "SELECT COUNT(*) FROM api_token WHERE user_id = ?", userId)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count api token: %w", err)
+	}
+	return count, nil
+}
+
+func (r *apiTokenRepository) UpdateLastUsed(id int, usedAt time.Time) error {
+	_, err := r.db.Exec("UPDATE api_token SET last_used_at = ? WHERE id = ?", usedAt, id)
+	if err != nil {
+		return fmt.Errorf("cannot query to update api token last used: %w", err)
+	}
+	return nil
+}
+
+func (r *apiTokenRepository) Delete(id int) error {
+	_, err := r.db.Exec("DELETE FROM api_token WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete api token: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/codern-org/codern/domain"
 	"github.com/codern-org/codern/platform"
@@ -18,18 +19,32 @@ func NewAssignmentRepository(db *platform.MySql) domain.AssignmentRepository {
 	return &assignmentRepository{db: db}
 }
 
-func (r *assignmentRepository) Create(assignment *domain.Assignment) error {
-	_, err := r.db.NamedExec(`
-		INSERT INTO assignment
-			(id, workspace_id, name, description, detail_url, memory_limit, time_limit, level, publish_date, due_date)
-		VALUES
-			(:id, :workspace_id, :name, :description, :detail_url, :memory_limit, :time_limit, :level, :publish_date, :due_date)
-		`, assignment)
-	if err != nil {
-		return fmt.Errorf("cannot query to insert assignment: %w", err)
-	}
+// Create inserts the assignment, false is returned when its workspace already
+// has maxAssignments assignments
+func (r *assignmentRepository) Create(assignment *domain.Assignment, maxAssignments int) (bool, error) {
+	return isWithinQuota(r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if err := checkAssignmentQuota(tx, assignment.WorkspaceId, maxAssignments); err != nil {
+			return err
+		}
 
-	return nil
+		_, err := tx.NamedExec(`
+			INSERT INTO assignment
+				(
+					id, workspace_id, name, description, detail_url, memory_limit, time_limit, level,
+					publish_date, due_date, is_auto_trim_enabled
+				)
+			VALUES
+				(
+					:id, :workspace_id, :name, :description, :detail_url, :memory_limit, :time_limit, :level,
+					:publish_date, :due_date, :is_auto_trim_enabled
+				)
+			`, assignment)
+		if err != nil {
+			return fmt.Errorf("cannot query to insert assignment: %w", err)
+		}
+
+		return nil
+	}))
 }
 
 func (r *assignmentRepository) Update(assignment *domain.Assignment) error {
@@ -54,42 +69,81 @@ func (r *assignmentRepository) Update(assignment *domain.Assignment) error {
 }
 
 func (r *assignmentRepository) Delete(id int) error {
-	_, err := r.db.Exec("UPDATE assignment SET is_deleted = TRUE WHERE id = ?", id)
+	_, err := r.db.Exec(
+		"UPDATE assignment SET is_deleted = TRUE, deleted_at = ? WHERE id = ? AND is_deleted = FALSE",
+		time.Now(), id,
+	)
 	if err != nil {
 		return fmt.Errorf("cannot query to soft delete assignment: %w", err)
 	}
 	return nil
 }
 
-func (r *assignmentRepository) CreateTestcases(testcases []domain.Testcase) error {
-	var revision int
-	err := r.db.Get(
-		&revision,
-		"SELECT MAX(revision) AS revision FROM testcase WHERE assignment_id = ? GROUP BY assignment_id",
-		testcases[0].AssignmentId,
-	)
-	if err == sql.ErrNoRows {
-		revision = 1
-	} else if err != nil {
-		return fmt.Errorf("cannot query revision to create testcase: %w", err)
-	} else {
-		revision += 1
-	}
+// Restore brings the assignment back, false is returned when its workspace already
+// has maxAssignments assignments
+func (r *assignmentRepository) Restore(id int, maxAssignments int) (bool, error) {
+	return isWithinQuota(r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if maxAssignments != domain.UnlimitedQuota {
+			var workspaceId int
+			if err := tx.Get(&workspaceId, "SELECT workspace_id FROM assignment WHERE id = ?", id); err != nil {
+				return fmt.Errorf("cannot query to get workspace of restored assignment: %w", err)
+			}
+			if err := checkAssignmentQuota(tx, workspaceId, maxAssignments); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec("UPDATE assignment SET is_deleted = FALSE, deleted_at = NULL WHERE id = ?", id)
+		if err != nil {
+			return fmt.Errorf("cannot query to restore assignment: %w", err)
+		}
+		return nil
+	}))
+}
 
-	query := "INSERT INTO testcase (id, assignment_id, revision, input_file_url, output_file_url) VALUES "
-	args := make([]interface{}, 0, len(testcases)*4)
+// CreateTestcases inserts a new revision of the testcases, false is returned when they
+// would take the workspace owner over maxBytes of testcase storage
+func (r *assignmentRepository) CreateTestcases(testcases []domain.Testcase, maxBytes int64) (bool, error) {
+	assignmentId := testcases[0].AssignmentId
+	var size int64
 	for _, testcase := range testcases {
-		query += "(?, ?, ?, ?, ?),"
-		args = append(args, testcase.Id, testcase.AssignmentId, revision, testcase.InputFileUrl, testcase.OutputFileUrl)
+		size += testcase.Size
 	}
 
-	query = query[:len(query)-1]
+	return isWithinQuota(r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if err := checkTestcaseQuota(tx, assignmentId, size, maxBytes); err != nil {
+			return err
+		}
 
-	if _, err := r.db.Exec(query, args...); err != nil {
-		return fmt.Errorf("cannot query to create testcase: %w", err)
-	}
+		var revision int
+		err := tx.Get(
+			&revision,
+			"SELECT MAX(revision) AS revision FROM testcase WHERE assignment_id = ? GROUP BY assignment_id",
+			assignmentId,
+		)
+		if err == sql.ErrNoRows {
+			revision = 1
+		} else if err != nil {
+			return fmt.Errorf("cannot query revision to create testcase: %w", err)
+		} else {
+			revision += 1
+		}
 
-	return nil
+		query := "INSERT INTO testcase (id, assignment_id, revision, input_file_url, output_file_url, size) VALUES "
+		args := make([]interface{}, 0, len(testcases)*6)
+		for _, testcase := range testcases {
+			query += "(?, ?, ?, ?, ?, ?),"
+			args = append(args, testcase.Id, testcase.AssignmentId, revision, testcase.InputFileUrl, testcase.OutputFileUrl, testcase.Size)
+		}
+
+		query = query[:len(query)-1]
+
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("cannot query to create testcase: %w", err)
+		}
+
+		return nil
+	}))
 }
 
 func (r *assignmentRepository) DeleteTestcases(assignmentId int) error {
@@ -100,18 +154,28 @@ func (r *assignmentRepository) DeleteTestcases(assignmentId int) error {
 	return nil
 }
 
+// CreateSubmission inserts the submission, false is returned when the submitter
+// already made maxSubmissions submissions since the given time
 func (r *assignmentRepository) CreateSubmission(
 	submission *domain.Submission,
 	testcases []domain.Testcase,
-) error {
-	_, err := r.db.NamedExec(`
-		INSERT INTO submission (id, assignment_id, user_id, language, status, score, file_url)
-		VALUES (:id, :assignment_id, :user_id, :language, 'GRADING', 0, :file_url)
-	`, submission)
-	if err != nil {
-		return fmt.Errorf("cannot query to create submission: %w", err)
-	}
-	return nil
+	maxSubmissions int,
+	since time.Time,
+) (bool, error) {
+	return isWithinQuota(r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if err := checkSubmissionQuota(tx, submission.SubmitterId, since, maxSubmissions); err != nil {
+			return err
+		}
+
+		_, err := tx.NamedExec(`
+			INSERT INTO submission (id, assignment_id, user_id, language, status, score, file_url)
+			VALUES (:id, :assignment_id, :user_id, :language, 'GRADING', 0, :file_url)
+		`, submission)
+		if err != nil {
+			return fmt.Errorf("cannot query to create submission: %w", err)
+		}
+		return nil
+	}))
 }
 
 func (r *assignmentRepository) CreateSubmissionResults(
@@ -248,6 +312,19 @@ func (r *assignmentRepository) list(
 	return assignments, nil
 }
 
+func (r *assignmentRepository) ListWithDeleted(workspaceId int) ([]domain.Assignment, error) {
+	assignments := make([]domain.Assignment, 0)
+	err := r.db.Select(
+		&assignments,
+		"SELECT * FROM assignment WHERE workspace_id = ? ORDER BY created_at",
+		workspaceId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list assignment with deleted: %w", err)
+	}
+	return assignments, nil
+}
+
 func (r *assignmentRepository) listRaw(
 	workspaceId *int,
 	assignmentId *int,
@@ -328,6 +405,7 @@ func (r *assignmentRepository) listTestcase(assignmentIds []int) ([]domain.Testc
 func (r *assignmentRepository) ListSubmission(
 	userId *string,
 	assignmentId *int,
+	groupIds []int,
 ) ([]domain.Submission, error) {
 	submissions := make([]domain.Submission, 0)
 
@@ -344,23 +422,27 @@ func (r *assignmentRepository) ListSubmission(
 		whereQueries = append(whereQueries, "s.assignment_id = ?")
 	}
 
+	if len(groupIds) > 0 {
+		groupQuery, groupArgs, err := groupMemberFilter("s.user_id", groupIds)
+		if err != nil {
+			return nil, fmt.Errorf("cannot query to create query to filter submission by group: %w", err)
+		}
+		queryArgs = append(queryArgs, groupArgs...)
+		whereQueries = append(whereQueries, groupQuery)
+	}
+
 	whereQueryString := fmt.Sprintf("WHERE %s", strings.Join(whereQueries, " AND "))
 	query := fmt.Sprintf(`
 		SELECT
 			s.*,
 			u.display_name AS user_display_name,
 			u.profile_url AS user_profile_url,
-			CASE
-				WHEN s.submitted_at > a.due_date THEN TRUE
-				WHEN s.submitted_at < a.due_date THEN FALSE
-				WHEN s.submitted_at = a.due_date THEN FALSE
-				ELSE FALSE
-			END AS is_late
+			COALESCE(s.submitted_at > %s, FALSE) AS is_late
 		FROM submission s
 		INNER JOIN user u ON u.id = s.user_id
 		INNER JOIN assignment a ON a.id = s.assignment_id
 		%s
-	`, whereQueryString)
+	`, effectiveDueDate, whereQueryString)
 
 	err := r.db.Select(&submissions, query, queryArgs...)
 	if err != nil {
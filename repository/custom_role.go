@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type customRoleRepository struct {
+	db *platform.MySql
+}
+
+func NewCustomRoleRepository(db *platform.MySql) domain.CustomRoleRepository {
+	return &customRoleRepository{db: db}
+}
+
+func (r *customRoleRepository) Create(role *domain.WorkspaceCustomRole) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO workspace_custom_role (id, workspace_id, name, permissions, created_at)
+		VALUES (:id, :workspace_id, :name, :permissions, :created_at)
+	`, role)
+	if err != nil {
+		return fmt.Errorf("cannot query to create workspace custom role: %w", err)
+	}
+	return nil
+}
+
+func (r *customRoleRepository) Get(id int) (*domain.WorkspaceCustomRole, error) {
+	var role domain.WorkspaceCustomRole
+	err := r.db.Get(&role, "SELECT * FROM workspace_custom_role WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get workspace custom role: %w", err)
+	}
+	return &role, nil
+}
+
+func (r *customRoleRepository) List(workspaceId int) ([]domain.WorkspaceCustomRole, error) {
+	roles := make([]domain.WorkspaceCustomRole, 0)
+	err := r.db.Select(
+		&roles,
+		"SELECT * FROM workspace_custom_role WHERE workspace_id = ? ORDER BY name",
+		workspaceId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace custom role: %w", err)
+	}
+	return roles, nil
+}
+
+func (r *customRoleRepository) Update(role *domain.WorkspaceCustomRole) error {
+	_, err := r.db.NamedExec(
+		"UPDATE workspace_custom_role SET name = :name, permissions = :permissions WHERE id = :id",
+		role,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to update workspace custom role: %w", err)
+	}
+	return nil
+}
+
+func (r *customRoleRepository) Delete(id int) error {
+	_, err := r.db.Exec("DELETE FROM workspace_custom_role WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete workspace custom role: %w", err)
+	}
+	return nil
+}
+
+func (r *customRoleRepository) CountParticipant(id int) (int, error) {
+	var count int
+	err := r.db.Get(&count, "SELECT COUNT(*) FROM workspace_participant WHERE custom_role_id = ?", id)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count participant of workspace custom role: %w", err)
+	}
+	return count, nil
+}
+
+func (r *customRoleRepository) SetParticipant(workspaceId int, userId string, id *int) error {
+	_, err := r.db.Exec(
+		"UPDATE workspace_participant SET custom_role_id = ? WHERE workspace_id = ? AND user_id = ?",
+		id, workspaceId, userId,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to set custom role of participant: %w", err)
+	}
+	return nil
+}
+
+func (r *customRoleRepository) GetParticipantRole(userId string, workspaceId int) (*domain.ParticipantRole, error) {
+	var role domain.ParticipantRole
+	err := r.db.Get(&role, `
+		SELECT wp.role, wp.custom_role_id, cr.permissions
+		FROM workspace_participant wp
+		LEFT JOIN workspace_custom_role cr ON cr.id = wp.custom_role_id
+		WHERE wp.user_id = ? AND wp.workspace_id = ?
+	`, userId, workspaceId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get participant role: %w", err)
+	}
+	return &role, nil
+}
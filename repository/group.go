@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
+)
+
+// effectiveDueDate is the due date of the assignment a for the submitter of the submission s,
+// a due date set for the group of the submitter takes precedence
+const effectiveDueDate = `COALESCE((
+	SELECT ags.due_date FROM assignment_group_schedule ags
+	INNER JOIN workspace_group_member wgm ON wgm.group_id = ags.group_id
+	WHERE ags.assignment_id = a.id AND wgm.user_id = s.user_id
+), a.due_date)`
+
+type groupRepository struct {
+	db *platform.MySql
+}
+
+func NewGroupRepository(db *platform.MySql) domain.GroupRepository {
+	return &groupRepository{db: db}
+}
+
+func (r *groupRepository) Create(group *domain.WorkspaceGroup) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO workspace_group (id, workspace_id, name, created_at)
+		VALUES (:id, :workspace_id, :name, :created_at)
+	`, group)
+	if err != nil {
+		return fmt.Errorf("cannot query to create workspace group: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) Get(id int) (*domain.WorkspaceGroup, error) {
+	var group domain.WorkspaceGroup
+	err := r.db.Get(&group, `
+		SELECT
+			g.*,
+			(SELECT COUNT(*) FROM workspace_group_member WHERE group_id = g.id) AS member_count
+		FROM workspace_group g
+		WHERE g.id = ?
+	`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get workspace group: %w", err)
+	}
+	return &group, nil
+}
+
+func (r *groupRepository) List(workspaceId int) ([]domain.WorkspaceGroup, error) {
+	groups := make([]domain.WorkspaceGroup, 0)
+	err := r.db.Select(&groups, `
+		SELECT
+			g.*,
+			(SELECT COUNT(*) FROM workspace_group_member WHERE group_id = g.id) AS member_count
+		FROM workspace_group g
+		WHERE g.workspace_id = ?
+		ORDER BY g.name
+	`, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace group: %w", err)
+	}
+	return groups, nil
+}
+
+func (r *groupRepository) Update(group *domain.WorkspaceGroup) error {
+	_, err := r.db.NamedExec("UPDATE workspace_group SET name = :name WHERE id = :id", group)
+	if err != nil {
+		return fmt.Errorf("cannot query to update workspace group: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the group, its members, managers and schedules go along with it
+func (r *groupRepository) Delete(id int) error {
+	_, err := r.db.Exec("DELETE FROM workspace_group WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete workspace group: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) ListMember(groupId int) ([]domain.GroupMember, error) {
+	members := make([]domain.GroupMember, 0)
+	err := r.db.Select(&members, `
+		SELECT * FROM (
+			SELECT u.id AS user_id, u.display_name AS name, u.profile_url, wp.role, FALSE AS is_manager
+			FROM workspace_group_member wgm
+			INNER JOIN user u ON u.id = wgm.user_id
+			INNER JOIN workspace_participant wp ON wp.workspace_id = wgm.workspace_id AND wp.user_id = wgm.user_id
+			WHERE wgm.group_id = ?
+			UNION ALL
+			SELECT u.id AS user_id, u.display_name AS name, u.profile_url, wp.role, TRUE AS is_manager
+			FROM workspace_group_manager wgm
+			INNER JOIN workspace_group g ON g.id = wgm.group_id
+			INNER JOIN user u ON u.id = wgm.user_id
+			INNER JOIN workspace_participant wp ON wp.workspace_id = g.workspace_id AND wp.user_id = wgm.user_id
+			WHERE wgm.group_id = ?
+		) AS m
+		ORDER BY m.is_manager DESC, m.name
+	`, groupId, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace group member: %w", err)
+	}
+	return members, nil
+}
+
+// SetMember puts the user in the group, moving them out of any other group of the workspace
+func (r *groupRepository) SetMember(workspaceId int, groupId int, userId string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO workspace_group_member (group_id, workspace_id, user_id)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE group_id = VALUES(group_id)
+	`, groupId, workspaceId, userId)
+	if err != nil {
+		return fmt.Errorf("cannot query to set workspace group member: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) DeleteMember(groupId int, userId string) error {
+	_, err := r.db.Exec(
+		"DELETE FROM workspace_group_member WHERE group_id = ? AND user_id = ?",
+		groupId, userId,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete workspace group member: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) CreateManager(groupId int, userId string) error {
+	_, err := r.db.Exec(
+		"INSERT IGNORE INTO workspace_group_manager (group_id, user_id) VALUES (?, ?)",
+		groupId, userId,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to create workspace group manager: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) DeleteManager(groupId int, userId string) error {
+	_, err := r.db.Exec(
+		"DELETE FROM workspace_group_manager WHERE group_id = ? AND user_id = ?",
+		groupId, userId,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete workspace group manager: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) ListManagedGroupId(userId string, workspaceId int) ([]int, error) {
+	ids := make([]int, 0)
+	err := r.db.Select(&ids, `
+		SELECT wgm.group_id
+		FROM workspace_group_manager wgm
+		INNER JOIN workspace_group g ON g.id = wgm.group_id
+		WHERE wgm.user_id = ? AND g.workspace_id = ?
+	`, userId, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list managed workspace group id: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *groupRepository) SetSchedule(schedule *domain.GroupSchedule) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO assignment_group_schedule (assignment_id, group_id, publish_date, due_date)
+		VALUES (:assignment_id, :group_id, :publish_date, :due_date)
+		ON DUPLICATE KEY UPDATE publish_date = VALUES(publish_date), due_date = VALUES(due_date)
+	`, schedule)
+	if err != nil {
+		return fmt.Errorf("cannot query to set assignment group schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) DeleteSchedule(groupId int, assignmentId int) error {
+	_, err := r.db.Exec(
+		"DELETE FROM assignment_group_schedule WHERE group_id = ? AND assignment_id = ?",
+		groupId, assignmentId,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete assignment group schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) ListSchedule(groupId int) ([]domain.GroupSchedule, error) {
+	schedules := make([]domain.GroupSchedule, 0)
+	err := r.db.Select(&schedules, `
+		SELECT ags.*
+		FROM assignment_group_schedule ags
+		INNER JOIN assignment a ON a.id = ags.assignment_id
+		WHERE ags.group_id = ? AND a.is_deleted = FALSE
+	`, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list assignment group schedule: %w", err)
+	}
+	return schedules, nil
+}
+
+func (r *groupRepository) ListScheduleByUser(userId string, workspaceId int) ([]domain.GroupSchedule, error) {
+	schedules := make([]domain.GroupSchedule, 0)
+	err := r.db.Select(&schedules, `
+		SELECT ags.*
+		FROM assignment_group_schedule ags
+		INNER JOIN workspace_group_member wgm ON wgm.group_id = ags.group_id
+		WHERE wgm.user_id = ? AND wgm.workspace_id = ?
+	`, userId, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list assignment group schedule of user: %w", err)
+	}
+	return schedules, nil
+}
+
+// groupMemberFilter restricts a query on user_id to the members of the given groups
+func groupMemberFilter(column string, groupIds []int) (string, []interface{}, error) {
+	return sqlx.In(
+		fmt.Sprintf("%s IN (SELECT user_id FROM workspace_group_member WHERE group_id IN (?))", column),
+		groupIds,
+	)
+}
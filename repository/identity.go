@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type identityRepository struct {
+	db *platform.MySql
+}
+
+func NewIdentityRepository(db *platform.MySql) domain.IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(identity *domain.UserIdentity) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO user_identity (provider, subject, email, is_email_verified, user_id, created_at)
+		VALUES (:provider, :subject, :email, :is_email_verified, :user_id, :created_at)
+	`, identity)
+	if err != nil {
+		return fmt.Errorf("cannot query to create user identity: %w", err)
+	}
+	return nil
+}
+
+func (r *identityRepository) Get(provider domain.AuthProvider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.db.Get(
+		&identity,
+		"SELECT * FROM user_identity WHERE provider = ? AND subject = ?",
+		provider, subject,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get user identity: %w", err)
+	}
+	return &identity, nil
+}
+
+// GetWithoutSubject returns an identity stored before subjects were recorded,
+// which is the only case an identity is still looked up by its email
+func (r *identityRepository) GetWithoutSubject(
+	provider domain.AuthProvider,
+	email string,
+) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.db.Get(
+		&identity,
+		"SELECT * FROM user_identity WHERE provider = ? AND email = ? AND subject IS NULL",
+		provider, email,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get user identity without subject: %w", err)
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) ListByUserId(userId string) ([]domain.UserIdentity, error) {
+	identities := make([]domain.UserIdentity, 0)
+	err := r.db.Select(
+		&identities,
+		"SELECT * FROM user_identity WHERE user_id = ? ORDER BY created_at ASC",
+		userId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list user identity: %w", err)
+	}
+	return identities, nil
+}
+
+func (r *identityRepository) UpdateSubject(provider domain.AuthProvider, email string, subject string) error {
+	_, err := r.db.Exec(
+		"UPDATE user_identity SET subject = ? WHERE provider = ? AND email = ? AND subject IS NULL",
+		subject, provider, email,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to update subject of user identity: %w", err)
+	}
+	return nil
+}
+
+func (r *identityRepository) UpdateEmail(
+	provider domain.AuthProvider,
+	subject string,
+	email string,
+	isEmailVerified bool,
+) error {
+	_, err := r.db.Exec(
+		"UPDATE user_identity SET email = ?, is_email_verified = ? WHERE provider = ? AND subject = ?",
+		email, isEmailVerified, provider, subject,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to update email of user identity: %w", err)
+	}
+	return nil
+}
+
+func (r *identityRepository) Delete(userId string, provider domain.AuthProvider, email string) error {
+	_, err := r.db.Exec(
+		"DELETE FROM user_identity WHERE user_id = ? AND provider = ? AND email = ?",
+		userId, provider, email,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete user identity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type impersonationRepository struct {
+	db *platform.MySql
+}
+
+func NewImpersonationRepository(db *platform.MySql) domain.ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
+
+func (r *impersonationRepository) Create(impersonation *domain.Impersonation) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO impersonation (id, impersonator_id, user_id, workspace_id, ip_address, user_agent, created_at, expired_at)
+		VALUES (:id, :impersonator_id, :user_id, :workspace_id, :ip_address, :user_agent, :created_at, :expired_at)
+	`, impersonation)
+	if err != nil {
+		return fmt.Errorf("cannot query to create impersonation: %w", err)
+	}
+	return nil
+}
+
+func (r *impersonationRepository) CreateLog(log *domain.ImpersonationLog) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO impersonation_log (id, impersonation_id, method, path, is_blocked, created_at)
+		VALUES (:id, :impersonation_id, :method, :path, :is_blocked, :created_at)
+	`, log)
+	if err != nil {
+		return fmt.Errorf("cannot query to create impersonation log: %w", err)
+	}
+	return nil
+}
+
+func (r *impersonationRepository) Get(id string) (*domain.Impersonation, error) {
+	var impersonation domain.Impersonation
+	err := r.db.Get(&impersonation, "SELECT * FROM impersonation WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get impersonation: %w", err)
+	}
+	return &impersonation, nil
+}
+
+func (r *impersonationRepository) ListByWorkspaceId(workspaceId int) ([]domain.Impersonation, error) {
+	impersonations := make([]domain.Impersonation, 0)
+	err := r.db.Select(
+		&impersonations,
+		"SELECT * FROM impersonation WHERE workspace_id = ? ORDER BY created_at DESC",
+		workspaceId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list impersonation: %w", err)
+	}
+	return impersonations, nil
+}
+
+func (r *impersonationRepository) End(id string, endedAt time.Time) error {
+	_, err := r.db.Exec(
+		"UPDATE impersonation SET ended_at = ? WHERE id = ? AND ended_at IS NULL",
+		endedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to end impersonation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+const joinRequestQuery = `
+	SELECT
+		jr.*,
+		user.display_name AS user_display_name,
+		user.profile_url AS user_profile_url
+	FROM workspace_join_request jr
+	INNER JOIN user ON user.id = jr.user_id
+`
+
+type joinRequestRepository struct {
+	db *platform.MySql
+}
+
+func NewJoinRequestRepository(db *platform.MySql) domain.JoinRequestRepository {
+	return &joinRequestRepository{db: db}
+}
+
+func (r *joinRequestRepository) Create(request *domain.JoinRequest) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO workspace_join_request (id, workspace_id, user_id, invitation_id, role, status, created_at)
+		VALUES (:id, :workspace_id, :user_id, :invitation_id, :role, :status, :created_at)
+	`, request)
+	if err != nil {
+		return fmt.Errorf("cannot query to create workspace join request: %w", err)
+	}
+	return nil
+}
+
+func (r *joinRequestRepository) Get(id int) (*domain.JoinRequest, error) {
+	var request domain.JoinRequest
+	err := r.db.Get(&request, joinRequestQuery+"WHERE jr.id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get workspace join request: %w", err)
+	}
+	return &request, nil
+}
+
+func (r *joinRequestRepository) GetPending(userId string, workspaceId int) (*domain.JoinRequest, error) {
+	var request domain.JoinRequest
+	err := r.db.Get(
+		&request,
+		joinRequestQuery+"WHERE jr.user_id = ? AND jr.workspace_id = ? AND jr.status = ?",
+		userId, workspaceId, domain.PendingJoinRequest,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get pending workspace join request: %w", err)
+	}
+	return &request, nil
+}
+
+func (r *joinRequestRepository) List(
+	workspaceId int,
+	status *domain.JoinRequestStatus,
+) ([]domain.JoinRequest, error) {
+	requests := make([]domain.JoinRequest, 0)
+	err := r.db.Select(
+		&requests,
+		joinRequestQuery+"WHERE jr.workspace_id = ? AND (? IS NULL OR jr.status = ?) ORDER BY jr.created_at",
+		workspaceId, status, status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace join request: %w", err)
+	}
+	return requests, nil
+}
+
+// Review stores the decision on a pending request, false is returned when
+// the request has already been reviewed
+func (r *joinRequestRepository) Review(request *domain.JoinRequest) (bool, error) {
+	result, err := r.db.NamedExec(`
+		UPDATE workspace_join_request
+		SET status = :status, reviewer_id = :reviewer_id, reviewed_at = :reviewed_at
+		WHERE id = :id AND status = 'PENDING'
+	`, request)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to review workspace join request: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of reviewed workspace join request: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// Reopen puts an approved request back to pending when the requester could not be added
+func (r *joinRequestRepository) Reopen(id int) error {
+	_, err := r.db.Exec(`
+		UPDATE workspace_join_request
+		SET status = 'PENDING', reviewer_id = NULL, reviewed_at = NULL
+		WHERE id = ? AND status = 'APPROVED'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("cannot query to reopen workspace join request: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type oidcRepository struct {
+	db *platform.MySql
+}
+
+func NewOidcRepository(db *platform.MySql) domain.OidcRepository {
+	return &oidcRepository{db: db}
+}
+
+func (r *oidcRepository) CreateState(state *domain.OidcState) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO oidc_state (id, provider, nonce, code_verifier, created_at, expired_at)
+		VALUES (:id, :provider, :nonce, :code_verifier, :created_at, :expired_at)
+	`, state)
+	if err != nil {
+		return fmt.Errorf("cannot query to create oidc state: %w", err)
+	}
+	return nil
+}
+
+func (r *oidcRepository) GetState(id string) (*domain.OidcState, error) {
+	var state domain.OidcState
+	err := r.db.Get(&state, "SELECT * FROM oidc_state WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get oidc state: %w", err)
+	}
+	return &state, nil
+}
+
+func (r *oidcRepository) DeleteState(id string) error {
+	_, err := r.db.Exec("DELETE FROM oidc_state WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete oidc state: %w", err)
+	}
+	return nil
+}
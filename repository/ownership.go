@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
+)
+
+const ownershipTransferQuery = `
+	SELECT
+		t.*,
+		from_user.display_name AS from_name,
+		to_user.display_name AS to_name
+	FROM workspace_ownership_transfer t
+	INNER JOIN user from_user ON from_user.id = t.from_user_id
+	INNER JOIN user to_user ON to_user.id = t.to_user_id
+`
+
+// errOwnershipConflict rolls back an accepted transfer whose participants changed in the meantime
+var errOwnershipConflict = errors.New("ownership transfer conflict")
+
+type ownershipTransferRepository struct {
+	db *platform.MySql
+}
+
+func NewOwnershipTransferRepository(db *platform.MySql) domain.OwnershipTransferRepository {
+	return &ownershipTransferRepository{db: db}
+}
+
+func (r *ownershipTransferRepository) Create(transfer *domain.OwnershipTransfer) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO workspace_ownership_transfer (id, workspace_id, from_user_id, to_user_id, status, created_at, expired_at)
+		VALUES (:id, :workspace_id, :from_user_id, :to_user_id, :status, :created_at, :expired_at)
+	`, transfer)
+	if err != nil {
+		return fmt.Errorf("cannot query to create ownership transfer: %w", err)
+	}
+	return nil
+}
+
+func (r *ownershipTransferRepository) Get(id int) (*domain.OwnershipTransfer, error) {
+	var transfer domain.OwnershipTransfer
+	err := r.db.Get(&transfer, ownershipTransferQuery+"WHERE t.id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get ownership transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+func (r *ownershipTransferRepository) GetPending(workspaceId int) (*domain.OwnershipTransfer, error) {
+	var transfer domain.OwnershipTransfer
+	err := r.db.Get(
+		&transfer,
+		ownershipTransferQuery+"WHERE t.workspace_id = ? AND t.status = ? AND t.expired_at > NOW()",
+		workspaceId, domain.PendingOwnershipTransfer,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get pending ownership transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+func (r *ownershipTransferRepository) List(workspaceId int) ([]domain.OwnershipTransfer, error) {
+	transfers := make([]domain.OwnershipTransfer, 0)
+	err := r.db.Select(
+		&transfers,
+		ownershipTransferQuery+"WHERE t.workspace_id = ? ORDER BY t.created_at DESC",
+		workspaceId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list ownership transfer: %w", err)
+	}
+	return transfers, nil
+}
+
+// Respond stores the answer to a pending transfer, false is returned when
+// the transfer has already been answered
+func (r *ownershipTransferRepository) Respond(transfer *domain.OwnershipTransfer) (bool, error) {
+	result, err := r.db.NamedExec(`
+		UPDATE workspace_ownership_transfer SET status = :status, responded_at = :responded_at
+		WHERE id = :id AND status = 'PENDING'
+	`, transfer)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to respond to ownership transfer: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of ownership transfer response: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// Accept swaps the roles of both participants in one transaction, false is returned
+// when the transfer was answered, the previous owner is not the owner anymore or the
+// new owner already owns maxOwned workspaces
+func (r *ownershipTransferRepository) Accept(transfer *domain.OwnershipTransfer, maxOwned int) (bool, error) {
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if err := checkOwnedWorkspaceQuota(tx, transfer.ToUserId, maxOwned); err != nil {
+			return err
+		}
+
+		updates := []struct {
+			query string
+			args  []interface{}
+		}{
+			{
+				query: "UPDATE workspace_ownership_transfer SET status = ?, responded_at = ? WHERE id = ? AND status = 'PENDING'",
+				args:  []interface{}{transfer.Status, transfer.RespondedAt, transfer.Id},
+			},
+			{
+				query: `
+					UPDATE workspace_participant SET role = 'ADMIN', custom_role_id = NULL
+					WHERE workspace_id = ? AND user_id = ? AND role = 'OWNER'
+				`,
+				args: []interface{}{transfer.WorkspaceId, transfer.FromUserId},
+			},
+			{
+				query: `
+					UPDATE workspace_participant SET role = 'OWNER', custom_role_id = NULL
+					WHERE workspace_id = ? AND user_id = ?
+				`,
+				args: []interface{}{transfer.WorkspaceId, transfer.ToUserId},
+			},
+		}
+
+		for _, update := range updates {
+			result, err := tx.Exec(update.query, update.args...)
+			if err != nil {
+				return fmt.Errorf("cannot query to accept ownership transfer: %w", err)
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("cannot get affected rows of accepted ownership transfer: %w", err)
+			} else if affected == 0 {
+				return errOwnershipConflict
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errOwnershipConflict) {
+		return false, nil
+	}
+	return isWithinQuota(err)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type passwordResetRepository struct {
+	db *platform.MySql
+}
+
+func NewPasswordResetRepository(db *platform.MySql) domain.PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) Create(reset *domain.PasswordReset) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO password_reset (id, user_id, email, created_at, expired_at)
+		VALUES (:id, :user_id, :email, :created_at, :expired_at)
+	`, reset)
+	if err != nil {
+		return fmt.Errorf("cannot query to create password reset: %w", err)
+	}
+	return nil
+}
+
+func (r *passwordResetRepository) Get(id string) (*domain.PasswordReset, error) {
+	var reset domain.PasswordReset
+	err := r.db.Get(&reset, "SELECT * FROM password_reset WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get password reset: %w", err)
+	}
+	return &reset, nil
+}
+
+func (r *passwordResetRepository) CountByEmail(email string, since time.Time) (int, error) {
+	var count int
+	err := r.db.Get(
+		&count,
+		"SELECT COUNT(*) FROM password_reset WHERE email = ? AND created_at >= ?",
+		email, since,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count password reset: %w", err)
+	}
+	return count, nil
+}
+
+// Use marks the token as used and reports whether this call was the one that consumed it,
+// so the same token cannot be redeemed twice by concurrent requests
+func (r *passwordResetRepository) Use(id string, usedAt time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE password_reset SET used_at = ? WHERE id = ? AND used_at IS NULL",
+		usedAt, id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to use password reset: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of using password reset: %w", err)
+	}
+	return affected == 1, nil
+}
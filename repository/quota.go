@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
+)
+
+// errQuotaReached rolls back an insert that would go over its quota
+var errQuotaReached = errors.New("quota reached")
+
+type quotaRepository struct {
+	db *platform.MySql
+}
+
+func NewQuotaRepository(db *platform.MySql) domain.QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+func (r *quotaRepository) GetWorkspaceOwner(workspaceId int) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Get(&user, `
+		SELECT user.* FROM user
+		INNER JOIN workspace_participant wp ON wp.user_id = user.id
+		WHERE wp.workspace_id = ? AND wp.role = 'OWNER'
+	`, workspaceId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get workspace owner: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *quotaRepository) CountOwnedWorkspace(userId string) (int, error) {
+	return countOwnedWorkspace(r.db, userId)
+}
+
+func (r *quotaRepository) CountParticipant(workspaceId int) (int, error) {
+	return countParticipant(r.db, workspaceId)
+}
+
+func (r *quotaRepository) CountAssignment(workspaceId int) (int, error) {
+	return countAssignment(r.db, workspaceId)
+}
+
+func (r *quotaRepository) CountSubmission(userId string, since time.Time) (int, error) {
+	return countSubmission(r.db, userId, since)
+}
+
+// SumTestcaseSize sums the latest testcase revision of every assignment in the workspaces
+// owned by the user, excludedAssignmentId is left out as its testcases are being replaced
+func (r *quotaRepository) SumTestcaseSize(ownerId string, excludedAssignmentId int) (int64, error) {
+	return sumTestcaseSize(r.db, ownerId, excludedAssignmentId)
+}
+
+func (r *quotaRepository) ListWorkspaceUsage(ownerId string) ([]domain.WorkspaceUsage, error) {
+	usages := make([]domain.WorkspaceUsage, 0)
+	err := r.db.Select(&usages, `
+		SELECT
+			w.id AS workspace_id,
+			w.name,
+			(SELECT COUNT(*) FROM workspace_participant p WHERE p.workspace_id = w.id) AS participants,
+			(SELECT COUNT(*) FROM assignment a WHERE a.workspace_id = w.id AND a.is_deleted = FALSE) AS assignments
+		FROM workspace w
+		INNER JOIN workspace_participant wp ON wp.workspace_id = w.id AND wp.role = 'OWNER'
+		WHERE wp.user_id = ? AND w.is_deleted = FALSE
+		ORDER BY w.created_at
+	`, ownerId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace usage: %w", err)
+	}
+	return usages, nil
+}
+
+// The quota checks below run in the transaction of the insert they guard. The row the quota
+// belongs to is locked first so concurrent inserts are counted one after another, and
+// errQuotaReached is returned when the insert would go over the limit.
+
+func checkOwnedWorkspaceQuota(tx *sqlx.Tx, userId string, limit int) error {
+	if limit == domain.UnlimitedQuota {
+		return nil
+	}
+	if err := lockUser(tx, userId); err != nil {
+		return err
+	}
+	count, err := countOwnedWorkspace(tx, userId)
+	if err != nil {
+		return err
+	} else if count >= limit {
+		return errQuotaReached
+	}
+	return nil
+}
+
+func checkParticipantQuota(tx *sqlx.Tx, workspaceId int, limit int) error {
+	if limit == domain.UnlimitedQuota {
+		return nil
+	}
+	if err := lockWorkspace(tx, workspaceId); err != nil {
+		return err
+	}
+	count, err := countParticipant(tx, workspaceId)
+	if err != nil {
+		return err
+	} else if count >= limit {
+		return errQuotaReached
+	}
+	return nil
+}
+
+func checkAssignmentQuota(tx *sqlx.Tx, workspaceId int, limit int) error {
+	if limit == domain.UnlimitedQuota {
+		return nil
+	}
+	if err := lockWorkspace(tx, workspaceId); err != nil {
+		return err
+	}
+	count, err := countAssignment(tx, workspaceId)
+	if err != nil {
+		return err
+	} else if count >= limit {
+		return errQuotaReached
+	}
+	return nil
+}
+
+func checkSubmissionQuota(tx *sqlx.Tx, userId string, since time.Time, limit int) error {
+	if limit == domain.UnlimitedQuota {
+		return nil
+	}
+	if err := lockUser(tx, userId); err != nil {
+		return err
+	}
+	count, err := countSubmission(tx, userId, since)
+	if err != nil {
+		return err
+	} else if count >= limit {
+		return errQuotaReached
+	}
+	return nil
+}
+
+// checkTestcaseQuota counts the storage of the owner of the assignment workspace,
+// size replaces the testcases the assignment currently has
+func checkTestcaseQuota(tx *sqlx.Tx, assignmentId int, size int64, limit int64) error {
+	if limit == domain.UnlimitedQuota {
+		return nil
+	}
+	var ownerId string
+	err := tx.Get(&ownerId, `
+		SELECT wp.user_id FROM workspace_participant wp
+		INNER JOIN assignment a ON a.workspace_id = wp.workspace_id
+		WHERE a.id = ? AND wp.role = 'OWNER'
+	`, assignmentId)
+	if err != nil {
+		return fmt.Errorf("cannot query to get owner of assignment: %w", err)
+	}
+	if err := lockUser(tx, ownerId); err != nil {
+		return err
+	}
+	used, err := sumTestcaseSize(tx, ownerId, assignmentId)
+	if err != nil {
+		return err
+	} else if used+size > limit {
+		return errQuotaReached
+	}
+	return nil
+}
+
+// isWithinQuota turns errQuotaReached of a transaction into false
+func isWithinQuota(err error) (bool, error) {
+	if errors.Is(err, errQuotaReached) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func lockUser(tx *sqlx.Tx, userId string) error {
+	var lockedId string
+	if err := tx.Get(&lockedId, "SELECT id FROM user WHERE id = ? FOR UPDATE", userId); err != nil {
+		return fmt.Errorf("cannot query to lock user: %w", err)
+	}
+	return nil
+}
+
+func lockWorkspace(tx *sqlx.Tx, workspaceId int) error {
+	var lockedId int
+	if err := tx.Get(&lockedId, "SELECT id FROM workspace WHERE id = ? FOR UPDATE", workspaceId); err != nil {
+		return fmt.Errorf("cannot query to lock workspace: %w", err)
+	}
+	return nil
+}
+
+func countOwnedWorkspace(q sqlx.Queryer, userId string) (int, error) {
+	var count int
+	err := sqlx.Get(q, &count, `
+		SELECT COUNT(*) FROM workspace_participant wp
+		INNER JOIN workspace w ON w.id = wp.workspace_id
+		WHERE wp.user_id = ? AND wp.role = 'OWNER' AND w.is_deleted = FALSE
+	`, userId)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count owned workspace: %w", err)
+	}
+	return count, nil
+}
+
+func countParticipant(q sqlx.Queryer, workspaceId int) (int, error) {
+	var count int
+	err := sqlx.Get(q, &count, "SELECT COUNT(*) FROM workspace_participant WHERE workspace_id = ?", workspaceId)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count participant: %w", err)
+	}
+	return count, nil
+}
+
+func countAssignment(q sqlx.Queryer, workspaceId int) (int, error) {
+	var count int
+	err := sqlx.Get(
+		q,
+		&count,
+		"SELECT COUNT(*) FROM assignment WHERE workspace_id = ? AND is_deleted = FALSE",
+		workspaceId,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count assignment: %w", err)
+	}
+	return count, nil
+}
+
+func countSubmission(q sqlx.Queryer, userId string, since time.Time) (int, error) {
+	var count int
+	err := sqlx.Get(
+		q,
+		&count,
+		"SELECT COUNT(*) FROM submission WHERE user_id = ? AND submitted_at >= ?",
+		userId, since,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count submission: %w", err)
+	}
+	return count, nil
+}
+
+func sumTestcaseSize(q sqlx.Queryer, ownerId string, excludedAssignmentId int) (int64, error) {
+	var size int64
+	err := sqlx.Get(q, &size, `
+		SELECT COALESCE(SUM(t.size), 0)
+		FROM testcase t
+		INNER JOIN assignment a ON a.id = t.assignment_id AND a.is_deleted = FALSE
+		INNER JOIN workspace w ON w.id = a.workspace_id AND w.is_deleted = FALSE
+		INNER JOIN workspace_participant wp ON wp.workspace_id = w.id AND wp.role = 'OWNER'
+		WHERE wp.user_id = ?
+			AND a.id != ?
+			AND t.revision = (SELECT MAX(revision) FROM testcase WHERE assignment_id = a.id)
+	`, ownerId, excludedAssignmentId)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to sum testcase size: %w", err)
+	}
+	return size, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
+)
+
+type rosterRepository struct {
+	db *platform.MySql
+}
+
+func NewRosterRepository(db *platform.MySql) domain.RosterRepository {
+	return &rosterRepository{db: db}
+}
+
+func (r *rosterRepository) CreatePendingInvitation(invitation *domain.PendingInvitation) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO workspace_pending_invitation (id, workspace_id, email, role, inviter_id, created_at)
+		VALUES (:id, :workspace_id, :email, :role, :inviter_id, :created_at)
+	`, invitation)
+	if err != nil {
+		return fmt.Errorf("cannot query to create pending invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *rosterRepository) HasPendingInvitation(workspaceId int, email string) (bool, error) {
+	var count int
+	err := r.db.Get(
+		&count,
+		"SELECT COUNT(*) FROM workspace_pending_invitation WHERE workspace_id = ? AND email = ?",
+		workspaceId, email,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to check pending invitation: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *rosterRepository) GetPendingInvitation(id int) (*domain.PendingInvitation, error) {
+	var invitation domain.PendingInvitation
+	err := r.db.Get(&invitation, "SELECT * FROM workspace_pending_invitation WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get pending invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+// GetVerifiedUserIdByEmail only matches emails the user proved to own,
+// either a verified primary email or a linked identity verified by its provider
+func (r *rosterRepository) GetVerifiedUserIdByEmail(email string) (*string, error) {
+	var id string
+	err := r.db.Get(&id, `
+		SELECT u.id FROM user u
+		WHERE u.deleted_at IS NULL AND (
+			(u.email = ? AND u.is_email_verified = TRUE)
+			OR u.id IN (SELECT user_id FROM user_identity WHERE email = ? AND is_email_verified = TRUE)
+		)
+		ORDER BY u.created_at
+		LIMIT 1
+	`, email, email)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get user id by email: %w", err)
+	}
+	return &id, nil
+}
+
+func (r *rosterRepository) ListPendingInvitation(workspaceId int) ([]domain.PendingInvitation, error) {
+	invitations := make([]domain.PendingInvitation, 0)
+	err := r.db.Select(
+		&invitations,
+		"SELECT * FROM workspace_pending_invitation WHERE workspace_id = ? ORDER BY created_at, email",
+		workspaceId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list pending invitation: %w", err)
+	}
+	return invitations, nil
+}
+
+func (r *rosterRepository) ListPendingInvitationByEmails(emails []string) ([]domain.PendingInvitation, error) {
+	invitations := make([]domain.PendingInvitation, 0)
+	if len(emails) == 0 {
+		return invitations, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM workspace_pending_invitation WHERE email IN (?)", emails)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to create query to list pending invitation: %w", err)
+	}
+	if err := r.db.Select(&invitations, query, args...); err != nil {
+		return nil, fmt.Errorf("cannot query to list pending invitation by email: %w", err)
+	}
+	return invitations, nil
+}
+
+func (r *rosterRepository) DeletePendingInvitation(id int) error {
+	_, err := r.db.Exec("DELETE FROM workspace_pending_invitation WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete pending invitation: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/codern-org/codern/domain"
 	"github.com/codern-org/codern/platform"
@@ -38,6 +39,27 @@ func (r *sessionRepository) Get(id string) (*domain.Session, error) {
 	return &session, nil
 }
 
+func (r *sessionRepository) ListByUserId(userId string) ([]domain.Session, error) {
+	sessions := make([]domain.Session, 0)
+	err := r.db.Select(
+		&sessions,
+		"SELECT * FROM session WHERE user_id = ? ORDER BY created_at DESC",
+		userId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list session: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) UpdateExpiredAt(id string, expiredAt time.Time) error {
+	_, err := r.db.Exec("UPDATE session SET expired_at = ? WHERE id = ?", expiredAt, id)
+	if err != nil {
+		return fmt.Errorf("cannot query to update session expiry: %w", err)
+	}
+	return nil
+}
+
 func (r *sessionRepository) Delete(id string) error {
 	_, err := r.db.Exec("DELETE FROM session WHERE id = ?", id)
 	if err != nil {
@@ -54,6 +76,14 @@ func (r *sessionRepository) DeleteByUserId(userId string) error {
 	return nil
 }
 
+func (r *sessionRepository) DeleteByUserIdExcept(userId string, exceptId string) error {
+	_, err := r.db.Exec("DELETE FROM session WHERE user_id = ? AND id != ?", userId, exceptId)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete other session: %w", err)
+	}
+	return nil
+}
+
 func (r *sessionRepository) DeleteDuplicates(userId string, ipAddress string, userAgent string) error {
 	_, err := r.db.Exec(
 		"DELETE FROM session WHERE user_id = ? AND user_agent = ? AND ip_address = ?",
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type signInAttemptRepository struct {
+	db *platform.MySql
+}
+
+func NewSignInAttemptRepository(db *platform.MySql) domain.SignInAttemptRepository {
+	return &signInAttemptRepository{db: db}
+}
+
+func (r *signInAttemptRepository) Create(attempt *domain.SignInAttempt) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO sign_in_attempt (id, email, ip_address, user_agent, is_success, reason, created_at)
+		VALUES (:id, :email, :ip_address, :user_agent, :is_success, :reason, :created_at)
+	`, attempt)
+	if err != nil {
+		return fmt.Errorf("cannot query to create sign in attempt: %w", err)
+	}
+	return nil
+}
+
+// GetFailureStatByEmail counts failures after the latest successful sign in,
+// so the owner signing in resets the backoff of the email
+func (r *signInAttemptRepository) GetFailureStatByEmail(
+	email string,
+	since time.Time,
+) (*domain.SignInFailureStat, error) {
+	var stat domain.SignInFailureStat
+	err := r.db.Get(&stat, `
+		SELECT COUNT(*) AS count, MAX(created_at) AS last_failed_at
+		FROM sign_in_attempt
+		WHERE email = ?
+			AND is_success = false
+			AND reason != ?
+			AND created_at >= ?
+			AND created_at > COALESCE(
+				(SELECT MAX(created_at) FROM sign_in_attempt WHERE email = ? AND is_success = true),
+				'1970-01-01'
+			)
+	`, email, domain.LockedFailure, since, email)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to get sign in failure stat by email: %w", err)
+	}
+	return &stat, nil
+}
+
+// GetFailureStatByIp is not reset by a successful sign in,
+// otherwise an attacker could reset it with an account of their own
+func (r *signInAttemptRepository) GetFailureStatByIp(
+	ipAddress string,
+	since time.Time,
+) (*domain.SignInFailureStat, error) {
+	var stat domain.SignInFailureStat
+	err := r.db.Get(&stat, `
+		SELECT COUNT(*) AS count, MAX(created_at) AS last_failed_at
+		FROM sign_in_attempt
+		WHERE ip_address = ? AND is_success = false AND reason != ? AND created_at >= ?
+	`, ipAddress, domain.LockedFailure, since)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to get sign in failure stat by ip: %w", err)
+	}
+	return &stat, nil
+}
+
+func (r *signInAttemptRepository) ListFailure(filter *domain.SignInAttemptFilter) ([]domain.SignInAttempt, error) {
+	conditions := []string{"is_success = false"}
+	args := make([]interface{}, 0)
+
+	if filter.Email != nil {
+		conditions = append(conditions, "email = ?")
+		args = append(args, *filter.Email)
+	}
+	if filter.IpAddress != nil {
+		conditions = append(conditions, "ip_address = ?")
+		args = append(args, *filter.IpAddress)
+	}
+	if filter.Since != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.Since)
+	}
+	args = append(args, filter.Limit, filter.Offset)
+
+	attempts := make([]domain.SignInAttempt, 0)
+	err := r.db.Select(&attempts, fmt.Sprintf(`
+		SELECT * FROM sign_in_attempt
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, strings.Join(conditions, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list sign in failure: %w", err)
+	}
+	return attempts, nil
+}
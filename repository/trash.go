@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
+)
+
+// errTrashRestored rolls back a purge whose item was restored in the meantime
+var errTrashRestored = errors.New("trash item restored")
+
+// The rows of an assignment in the order they can be deleted, the results
+// of a submission are removed by the cascade before its testcases go
+var assignmentPurgeQueries = []string{
+	"DELETE FROM submission WHERE assignment_id IN (%s)",
+	"DELETE FROM testcase WHERE assignment_id IN (%s)",
+	"DELETE FROM assignment_group_schedule WHERE assignment_id IN (%s)",
+}
+
+// The rows of a workspace in the order they can be deleted once its assignments are gone
+var workspacePurgeQueries = []string{
+	"DELETE FROM assignment WHERE workspace_id = ?",
+	"DELETE FROM workspace_group WHERE workspace_id = ?",
+	"DELETE FROM workspace_participant WHERE workspace_id = ?",
+	"DELETE FROM workspace_custom_role WHERE workspace_id = ?",
+	"DELETE FROM workspace_invitation_redemption WHERE workspace_id = ?",
+	"DELETE FROM workspace_invitation WHERE workspace_id = ?",
+	"DELETE FROM workspace_pending_invitation WHERE workspace_id = ?",
+	"DELETE FROM workspace_join_request WHERE workspace_id = ?",
+	"DELETE FROM workspace_ownership_transfer WHERE workspace_id = ?",
+	"DELETE FROM impersonation WHERE workspace_id = ?",
+	"DELETE FROM workspace_announcement WHERE workspace_id = ?",
+	"DELETE FROM workspace WHERE id = ?",
+}
+
+type trashRepository struct {
+	db *platform.MySql
+}
+
+func NewTrashRepository(db *platform.MySql) domain.TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func (r *trashRepository) GetWorkspace(id int) (*domain.TrashedWorkspace, error) {
+	var workspace domain.TrashedWorkspace
+	err := r.db.Get(&workspace, `
+		SELECT id, name, profile_url, deleted_at FROM workspace
+		WHERE id = ? AND is_deleted = TRUE AND deleted_at IS NOT NULL
+	`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get trashed workspace: %w", err)
+	}
+	return &workspace, nil
+}
+
+func (r *trashRepository) GetAssignment(id int) (*domain.TrashedAssignment, error) {
+	var assignment domain.TrashedAssignment
+	err := r.db.Get(&assignment, `
+		SELECT a.id, a.workspace_id, w.name AS workspace_name, a.name, a.deleted_at
+		FROM assignment a
+		INNER JOIN workspace w ON w.id = a.workspace_id
+		WHERE a.id = ? AND a.is_deleted = TRUE AND a.deleted_at IS NOT NULL
+	`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get trashed assignment: %w", err)
+	}
+	return &assignment, nil
+}
+
+func (r *trashRepository) ListWorkspace(ownerId string, since time.Time) ([]domain.TrashedWorkspace, error) {
+	workspaces := make([]domain.TrashedWorkspace, 0)
+	err := r.db.Select(&workspaces, `
+		SELECT w.id, w.name, w.profile_url, w.deleted_at
+		FROM workspace w
+		INNER JOIN workspace_participant wp ON wp.workspace_id = w.id
+		WHERE wp.user_id = ? AND wp.role = 'OWNER' AND w.is_deleted = TRUE AND w.deleted_at >= ?
+		ORDER BY w.deleted_at DESC
+	`, ownerId, since)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list trashed workspace: %w", err)
+	}
+	return workspaces, nil
+}
+
+func (r *trashRepository) ListAssignment(ownerId string, since time.Time) ([]domain.TrashedAssignment, error) {
+	assignments := make([]domain.TrashedAssignment, 0)
+	err := r.db.Select(&assignments, `
+		SELECT a.id, a.workspace_id, w.name AS workspace_name, a.name, a.deleted_at
+		FROM assignment a
+		INNER JOIN workspace w ON w.id = a.workspace_id AND w.is_deleted = FALSE
+		INNER JOIN workspace_participant wp ON wp.workspace_id = w.id
+		WHERE wp.user_id = ? AND wp.role = 'OWNER' AND a.is_deleted = TRUE AND a.deleted_at >= ?
+		ORDER BY a.deleted_at DESC
+	`, ownerId, since)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list trashed assignment: %w", err)
+	}
+	return assignments, nil
+}
+
+func (r *trashRepository) ListExpiredWorkspace(before time.Time) ([]domain.TrashedWorkspace, error) {
+	workspaces := make([]domain.TrashedWorkspace, 0)
+	err := r.db.Select(&workspaces, `
+		SELECT id, name, profile_url, deleted_at FROM workspace
+		WHERE is_deleted = TRUE AND deleted_at < ?
+	`, before)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list expired trashed workspace: %w", err)
+	}
+	return workspaces, nil
+}
+
+func (r *trashRepository) ListExpiredAssignment(before time.Time) ([]domain.TrashedAssignment, error) {
+	assignments := make([]domain.TrashedAssignment, 0)
+	err := r.db.Select(&assignments, `
+		SELECT a.id, a.workspace_id, w.name AS workspace_name, a.name, a.deleted_at
+		FROM assignment a
+		INNER JOIN workspace w ON w.id = a.workspace_id
+		WHERE a.is_deleted = TRUE AND a.deleted_at < ?
+	`, before)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list expired trashed assignment: %w", err)
+	}
+	return assignments, nil
+}
+
+// PurgeWorkspace hard deletes the workspace with everything in it,
+// false is returned when it was restored or is not expired anymore
+func (r *trashRepository) PurgeWorkspace(id int, before time.Time) (bool, error) {
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if err := lockExpired(tx, "workspace", id, before); err != nil {
+			return err
+		}
+		return deleteWorkspace(tx, id)
+	})
+	if errors.Is(err, errTrashRestored) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteWorkspace hard deletes the workspace with everything in it whether it is trashed or not
+func (r *trashRepository) DeleteWorkspace(id int) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		return deleteWorkspace(tx, id)
+	})
+}
+
+// PurgeAssignment hard deletes the assignment with its testcases and submissions,
+// false is returned when it was restored or is not expired anymore
+func (r *trashRepository) PurgeAssignment(id int, before time.Time) (bool, error) {
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if err := lockExpired(tx, "assignment", id, before); err != nil {
+			return err
+		}
+
+		for _, query := range assignmentPurgeQueries {
+			if _, err := tx.Exec(fmt.Sprintf(query, "?"), id); err != nil {
+				return fmt.Errorf("cannot query to purge assignment: %w", err)
+			}
+		}
+		if _, err := tx.Exec("DELETE FROM assignment WHERE id = ?", id); err != nil {
+			return fmt.Errorf("cannot query to purge assignment: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, errTrashRestored) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func deleteWorkspace(tx *sqlx.Tx, id int) error {
+	subquery := "SELECT id FROM assignment WHERE workspace_id = ?"
+	for _, query := range assignmentPurgeQueries {
+		if _, err := tx.Exec(fmt.Sprintf(query, subquery), id); err != nil {
+			return fmt.Errorf("cannot query to purge assignment of workspace: %w", err)
+		}
+	}
+	for _, query := range workspacePurgeQueries {
+		if _, err := tx.Exec(query, id); err != nil {
+			return fmt.Errorf("cannot query to purge workspace: %w", err)
+		}
+	}
+	return nil
+}
+
+// lockExpired holds the row until the purge commits so a restore cannot slip in between
+func lockExpired(tx *sqlx.Tx, table string, id int, before time.Time) error {
+	var lockedId int
+	err := tx.Get(&lockedId, fmt.Sprintf(`
+		SELECT id FROM %s WHERE id = ? AND is_deleted = TRUE AND deleted_at < ? FOR UPDATE
+	`, table), id, before)
+	if err == sql.ErrNoRows {
+		return errTrashRestored
+	} else if err != nil {
+		return fmt.Errorf("cannot query to lock trashed %s: %w", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
+)
+
+type twoFactorRepository struct {
+	db *platform.MySql
+}
+
+func NewTwoFactorRepository(db *platform.MySql) domain.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) CreateChallenge(challenge *domain.TwoFactorChallenge) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO two_factor_challenge (id, user_id, attempt, created_at, expired_at)
+		VALUES (:id, :user_id, :attempt, :created_at, :expired_at)
+	`, challenge)
+	if err != nil {
+		return fmt.Errorf("cannot query to create two-factor challenge: %w", err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) GetChallenge(id string) (*domain.TwoFactorChallenge, error) {
+	var challenge domain.TwoFactorChallenge
+	err := r.db.Get(&challenge, "SELECT * FROM two_factor_challenge WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get two-factor challenge: %w", err)
+	}
+	return &challenge, nil
+}
+
+func (r *twoFactorRepository) CountChallenge(userId string, since time.Time) (int, error) {
+	var count int
+	err := r.db.Get(
+		&count,
+		"SELECT COUNT(*) FROM two_factor_challenge WHERE user_id = ? AND created_at >= ?",
+		userId, since,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query to count two-factor challenge: %w", err)
+	}
+	return count, nil
+}
+
+// IncreaseChallengeAttempt counts an attempt before the code is checked, false is returned
+// when the challenge is gone, expired or out of attempts
+func (r *twoFactorRepository) IncreaseChallengeAttempt(id string, maxAttempt int, now time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE two_factor_challenge SET attempt = attempt + 1 WHERE id = ? AND attempt < ? AND expired_at > ?",
+		id, maxAttempt, now,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to increase two-factor challenge attempt: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of increasing two-factor challenge attempt: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// DeleteChallenge returns false when the challenge was already deleted by another request
+func (r *twoFactorRepository) DeleteChallenge(id string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM two_factor_challenge WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to delete two-factor challenge: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of deleting two-factor challenge: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (r *twoFactorRepository) CreateRecoveryCodes(userId string, codes []domain.RecoveryCode) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec("DELETE FROM recovery_code WHERE user_id = ?", userId); err != nil {
+			return fmt.Errorf("cannot query to delete previous recovery code: %w", err)
+		}
+
+		_, err := tx.NamedExec(`
+			INSERT INTO recovery_code (id, user_id, created_at)
+			VALUES (:id, :user_id, :created_at)
+		`, codes)
+		if err != nil {
+			return fmt.Errorf("cannot query to create recovery code: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userId string, id string, usedAt time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE recovery_code SET used_at = ? WHERE id = ? AND user_id = ? AND used_at IS NULL",
+		usedAt, id, userId,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to use recovery code: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of using recovery code: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// UseTotpStep moves the last accepted time step of the user forward,
+// false is returned when the step was already reached
+func (r *twoFactorRepository) UseTotpStep(userId string, step int64) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE user SET totp_last_step = ? WHERE id = ? AND (totp_last_step IS NULL OR totp_last_step < ?)",
+		step, userId, step,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to use totp step: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of using totp step: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (r *twoFactorRepository) DeleteRecoveryCodes(userId string) error {
+	_, err := r.db.Exec("DELETE FROM recovery_code WHERE user_id = ?", userId)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete recovery code: %w", err)
+	}
+	return nil
+}
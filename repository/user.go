@@ -3,9 +3,11 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/codern-org/codern/domain"
 	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
 )
 
 type userRepository struct {
@@ -18,8 +20,8 @@ func NewUserRepository(db *platform.MySql) domain.UserRepository {
 
 func (r *userRepository) Create(user *domain.User) error {
 	_, err := r.db.NamedExec(
-		"INSERT INTO user (id, email, password, display_name, profile_url, account_type, provider, created_at)"+
-			"VALUES (:id, :email, :password, :display_name, :profile_url, :account_type, :provider, :created_at)",
+		"INSERT INTO user (id, email, password, display_name, profile_url, account_type, provider, is_email_verified, created_at)"+
+			"VALUES (:id, :email, :password, :display_name, :profile_url, :account_type, :provider, :is_email_verified, :created_at)",
 		user,
 	)
 	if err != nil {
@@ -72,16 +74,35 @@ func (r *userRepository) GetByEmail(
 	return &user, nil
 }
 
+// Search matches the query against the id, email and display name of users
+func (r *userRepository) Search(query string, limit int, offset int) ([]domain.User, error) {
+	pattern := "%" + query + "%"
+	users := make([]domain.User, 0)
+	err := r.db.Select(&users, `
+		SELECT * FROM user
+		WHERE id = ? OR email LIKE ? OR display_name LIKE ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, query, pattern, pattern, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to search user: %w", err)
+	}
+	return users, nil
+}
+
 func (r *userRepository) Update(user *domain.User) error {
 	_, err := r.db.NamedExec(`
 		UPDATE user
 		SET
 			email = :email,
 			password = :password,
+			totp_secret = :totp_secret,
+			is_two_factor_enabled = :is_two_factor_enabled,
 			display_name = :display_name,
 			profile_url = :profile_url,
 			account_type = :account_type,
-			provider = :provider
+			provider = :provider,
+			is_email_verified = :is_email_verified
 		WHERE id = :id
 	`, user)
 	if err != nil {
@@ -89,3 +110,100 @@ func (r *userRepository) Update(user *domain.User) error {
 	}
 	return nil
 }
+
+func (r *userRepository) UpdateAdmin(id string, isAdmin bool) error {
+	_, err := r.db.Exec("UPDATE user SET is_admin = ? WHERE id = ?", isAdmin, id)
+	if err != nil {
+		return fmt.Errorf("cannot query to update user admin: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) UpdateDisabled(id string, isDisabled bool) error {
+	_, err := r.db.Exec("UPDATE user SET is_disabled = ? WHERE id = ?", isDisabled, id)
+	if err != nil {
+		return fmt.Errorf("cannot query to update user disabled: %w", err)
+	}
+	return nil
+}
+
+// Merge moves everything owned by the user fromId to the user intoId and deletes fromId,
+// workspaces joined by both users keep the higher role of the two
+func (r *userRepository) Merge(fromId string, intoId string) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE workspace_participant target
+			JOIN workspace_participant source
+				ON source.workspace_id = target.workspace_id AND source.user_id = ?
+			SET target.role = source.role
+			WHERE target.user_id = ?
+				AND FIELD(source.role, 'MEMBER', 'ADMIN', 'OWNER') > FIELD(target.role, 'MEMBER', 'ADMIN', 'OWNER')
+		`, fromId, intoId)
+		if err != nil {
+			return fmt.Errorf("cannot query to merge workspace participant role: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			DELETE source FROM workspace_participant source
+			JOIN workspace_participant target ON target.workspace_id = source.workspace_id
+			WHERE source.user_id = ? AND target.user_id = ?
+		`, fromId, intoId)
+		if err != nil {
+			return fmt.Errorf("cannot query to delete duplicated workspace participant: %w", err)
+		}
+
+		reassignQueries := []string{
+			"UPDATE workspace_participant SET user_id = ? WHERE user_id = ?",
+			"UPDATE submission SET user_id = ? WHERE user_id = ?",
+			"UPDATE workspace_invitation SET inviter_id = ? WHERE inviter_id = ?",
+			"UPDATE workspace_pending_invitation SET inviter_id = ? WHERE inviter_id = ?",
+			"UPDATE workspace_invitation_redemption SET user_id = ? WHERE user_id = ?",
+			"UPDATE workspace_join_request SET user_id = ? WHERE user_id = ?",
+			"UPDATE workspace_join_request SET reviewer_id = ? WHERE reviewer_id = ?",
+			"UPDATE workspace_ownership_transfer SET from_user_id = ? WHERE from_user_id = ?",
+			"UPDATE workspace_ownership_transfer SET to_user_id = ? WHERE to_user_id = ?",
+			"UPDATE survey SET user_id = ? WHERE user_id = ?",
+			"UPDATE api_token SET user_id = ? WHERE user_id = ?",
+			"UPDATE user_identity SET user_id = ? WHERE user_id = ?",
+			"UPDATE user_merge SET into_user_id = ? WHERE into_user_id = ?",
+			"UPDATE impersonation SET impersonator_id = ? WHERE impersonator_id = ?",
+			"UPDATE impersonation SET user_id = ? WHERE user_id = ?",
+			"UPDATE IGNORE workspace_group_member SET user_id = ? WHERE user_id = ?",
+			"UPDATE IGNORE workspace_group_manager SET user_id = ? WHERE user_id = ?",
+			"UPDATE workspace_announcement SET author_id = ? WHERE author_id = ?",
+			"UPDATE IGNORE workspace_announcement_read SET user_id = ? WHERE user_id = ?",
+		}
+		for _, query := range reassignQueries {
+			if _, err := tx.Exec(query, intoId, fromId); err != nil {
+				return fmt.Errorf("cannot query to reassign merged user data: %w", err)
+			}
+		}
+
+		// Credentials of the merged user must not be usable anymore
+		deleteQueries := []string{
+			"DELETE FROM session WHERE user_id = ?",
+			"DELETE FROM email_verification WHERE user_id = ?",
+			"DELETE FROM password_reset WHERE user_id = ?",
+			"DELETE FROM recovery_code WHERE user_id = ?",
+			"DELETE FROM two_factor_challenge WHERE user_id = ?",
+			"DELETE FROM workspace_group_member WHERE user_id = ?",
+			"DELETE FROM workspace_group_manager WHERE user_id = ?",
+			"DELETE FROM workspace_announcement_read WHERE user_id = ?",
+			"DELETE FROM user WHERE id = ?",
+		}
+		for _, query := range deleteQueries {
+			if _, err := tx.Exec(query, fromId); err != nil {
+				return fmt.Errorf("cannot query to delete merged user data: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO user_merge (from_user_id, into_user_id, merged_at) VALUES (?, ?, ?)",
+			fromId, intoId, time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("cannot query to create user merge log: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type emailVerificationRepository struct {
+	db *platform.MySql
+}
+
+func NewEmailVerificationRepository(db *platform.MySql) domain.EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+func (r *emailVerificationRepository) Create(verification *domain.EmailVerification) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO email_verification (id, user_id, email, created_at, expired_at)
+		VALUES (:id, :user_id, :email, :created_at, :expired_at)
+	`, verification)
+	if err != nil {
+		return fmt.Errorf("cannot query to create email verification: %w", err)
+	}
+	return nil
+}
+
+func (r *emailVerificationRepository) Get(id string) (*domain.EmailVerification, error) {
+	var verification domain.EmailVerification
+	err := r.db.Get(&verification, "SELECT * FROM email_verification WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get email verification: %w", err)
+	}
+	return &verification, nil
+}
+
+func (r *emailVerificationRepository) DeleteByUserId(userId string) error {
+	_, err := r.db.Exec("DELETE FROM email_verification WHERE user_id = ?", userId)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete email verification: %w", err)
+	}
+	return nil
+}
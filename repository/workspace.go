@@ -18,11 +18,21 @@ func NewWorkspaceRepository(db *platform.MySql) domain.WorkspaceRepository {
 	return &workspaceRepository{db: db}
 }
 
-func (r *workspaceRepository) Create(userId string, workspace *domain.RawWorkspace) error {
-	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+// Create inserts the workspace owned by the user, false is returned when the user
+// already owns maxOwned workspaces
+func (r *workspaceRepository) Create(userId string, workspace *domain.RawWorkspace, maxOwned int) (bool, error) {
+	return isWithinQuota(r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if err := checkOwnedWorkspaceQuota(tx, userId, maxOwned); err != nil {
+			return err
+		}
+
 		_, err := tx.NamedExec(`
-			INSERT INTO workspace (id, name, profile_url, created_at)
-			VALUES (:id, :name, :profile_url, :created_at)
+			INSERT INTO workspace (
+				id, name, profile_url, created_at, is_open_scoreboard, is_join_approval, is_discoverable
+			)
+			VALUES (
+				:id, :name, :profile_url, :created_at, :is_open_scoreboard, :is_join_approval, :is_discoverable
+			)
 		`, workspace)
 		if err != nil {
 			return fmt.Errorf("cannot query to create workspace: %w", err)
@@ -37,29 +47,53 @@ func (r *workspaceRepository) Create(userId string, workspace *domain.RawWorkspa
 		}
 
 		return nil
-	})
+	}))
 }
 
 func (r *workspaceRepository) CreateInvitation(invitation *domain.WorkspaceInvitation) error {
-	_, err := r.db.Exec(`
-		INSERT INTO workspace_invitation (id, workspace_id, inviter_id, created_at, valid_at, valid_until)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, invitation.Id, invitation.WorkspaceId, invitation.InviterId, invitation.CreatedAt, invitation.ValidAt, invitation.ValidUntil)
-	if err != nil {
-		return fmt.Errorf("cannot query to insert workspace invitation: %w", err)
-	}
-	return nil
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExec(`
+			INSERT INTO workspace_invitation (
+				id, workspace_id, inviter_id, created_at, valid_at, valid_until, role, max_uses, allowed_domain
+			)
+			VALUES (
+				:id, :workspace_id, :inviter_id, :created_at, :valid_at, :valid_until, :role, :max_uses, :allowed_domain
+			)
+		`, invitation)
+		if err != nil {
+			return fmt.Errorf("cannot query to insert workspace invitation: %w", err)
+		}
+
+		for _, email := range invitation.Emails {
+			_, err := tx.Exec(
+				"INSERT IGNORE INTO workspace_invitation_email (invitation_id, email) VALUES (?, ?)",
+				invitation.Id, email,
+			)
+			if err != nil {
+				return fmt.Errorf("cannot query to insert workspace invitation email: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
-func (r *workspaceRepository) CreateParticipant(participant *domain.WorkspaceParticipant) error {
-	_, err := r.db.Exec(
-		"INSERT INTO workspace_participant (workspace_id, user_id, role, favorite) VALUES (?, ?, ?, ?)",
-		participant.WorkspaceId, participant.UserId, participant.Role, participant.Favorite,
-	)
-	if err != nil {
-		return fmt.Errorf("cannot query to insert workspace participant: %w", err)
-	}
-	return nil
+// CreateParticipant adds the participant, false is returned when the workspace
+// already has maxParticipants participants
+func (r *workspaceRepository) CreateParticipant(participant *domain.WorkspaceParticipant, maxParticipants int) (bool, error) {
+	return isWithinQuota(r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if err := checkParticipantQuota(tx, participant.WorkspaceId, maxParticipants); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(
+			"INSERT INTO workspace_participant (workspace_id, user_id, role, favorite) VALUES (?, ?, ?, ?)",
+			participant.WorkspaceId, participant.UserId, participant.Role, participant.Favorite,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot query to insert workspace participant: %w", err)
+		}
+		return nil
+	}))
 }
 
 func (r *workspaceRepository) HasUser(userId string, workspaceId int) (bool, error) {
@@ -152,7 +186,12 @@ func (r *workspaceRepository) GetInvitation(id string) (*domain.WorkspaceInvitat
 	} else if err != nil {
 		return nil, fmt.Errorf("cannot query to get workspace invitation: %w", err)
 	}
-	return &invitation, nil
+
+	invitations := []domain.WorkspaceInvitation{invitation}
+	if err := r.loadInvitationEmails(invitations); err != nil {
+		return nil, err
+	}
+	return &invitations[0], nil
 }
 
 func (r *workspaceRepository) GetInvitations(workspaceId int) ([]domain.WorkspaceInvitation, error) {
@@ -165,10 +204,99 @@ func (r *workspaceRepository) GetInvitations(workspaceId int) ([]domain.Workspac
 	if err != nil {
 		return nil, fmt.Errorf("cannot query to get workspace invitations: %w", err)
 	}
+	if err := r.loadInvitationEmails(invitations); err != nil {
+		return nil, err
+	}
 	return invitations, nil
 }
 
-func (r *workspaceRepository) GetScoreboard(workspaceId int) ([]domain.WorkspaceRank, error) {
+func (r *workspaceRepository) loadInvitationEmails(invitations []domain.WorkspaceInvitation) error {
+	if len(invitations) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(invitations))
+	invitationById := make(map[string]*domain.WorkspaceInvitation, len(invitations))
+	for i := range invitations {
+		ids[i] = invitations[i].Id
+		invitations[i].Emails = make([]string, 0)
+		invitationById[invitations[i].Id] = &invitations[i]
+	}
+
+	var emails []struct {
+		InvitationId string `db:"invitation_id"`
+		Email        string `db:"email"`
+	}
+	query, args, err := sqlx.In(
+		"SELECT invitation_id, email FROM workspace_invitation_email WHERE invitation_id IN (?) ORDER BY email",
+		ids,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to create query to list workspace invitation email: %w", err)
+	}
+	if err := r.db.Select(&emails, query, args...); err != nil {
+		return fmt.Errorf("cannot query to list workspace invitation email: %w", err)
+	}
+
+	for _, email := range emails {
+		invitation := invitationById[email.InvitationId]
+		invitation.Emails = append(invitation.Emails, email.Email)
+	}
+	return nil
+}
+
+// UseInvitation takes one use of the invitation, false when every use is already taken
+func (r *workspaceRepository) UseInvitation(id string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE workspace_invitation SET uses = uses + 1
+		WHERE id = ? AND (max_uses IS NULL OR uses < max_uses)
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to use workspace invitation: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of workspace invitation use: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *workspaceRepository) ReleaseInvitation(id string) error {
+	_, err := r.db.Exec("UPDATE workspace_invitation SET uses = uses - 1 WHERE id = ? AND uses > 0", id)
+	if err != nil {
+		return fmt.Errorf("cannot query to release workspace invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *workspaceRepository) CreateRedemption(redemption *domain.InvitationRedemption) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO workspace_invitation_redemption (id, invitation_id, workspace_id, user_id, role, redeemed_at)
+		VALUES (:id, :invitation_id, :workspace_id, :user_id, :role, :redeemed_at)
+	`, redemption)
+	if err != nil {
+		return fmt.Errorf("cannot query to create workspace invitation redemption: %w", err)
+	}
+	return nil
+}
+
+func (r *workspaceRepository) ListRedemption(workspaceId int) ([]domain.InvitationRedemption, error) {
+	redemptions := make([]domain.InvitationRedemption, 0)
+	err := r.db.Select(&redemptions, `
+		SELECT r.*, u.display_name AS user_display_name
+		FROM workspace_invitation_redemption r
+		INNER JOIN user u ON u.id = r.user_id
+		WHERE r.workspace_id = ?
+		ORDER BY r.redeemed_at DESC
+	`, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace invitation redemption: %w", err)
+	}
+	return redemptions, nil
+}
+
+// GetScoreboard ranks the members of the workspace, or only the members of the group when groupId is given
+func (r *workspaceRepository) GetScoreboard(workspaceId int, groupId *int) ([]domain.WorkspaceRank, error) {
 	scoreboard := make([]domain.WorkspaceRank, 0)
 	err := r.db.Select(&scoreboard, `
 		WITH filtered_submission AS (
@@ -177,6 +305,11 @@ func (r *workspaceRepository) GetScoreboard(workspaceId int) ([]domain.Workspace
 				SELECT
 					*,
 					COALESCE(
+						(
+							SELECT ags.due_date FROM assignment_group_schedule ags
+							INNER JOIN workspace_group_member wgm ON wgm.group_id = ags.group_id
+							WHERE ags.assignment_id = submission.assignment_id AND wgm.user_id = submission.user_id
+						),
 						(SELECT assignment.due_date FROM assignment WHERE assignment.id = submission.assignment_id),
 						'9999-01-01 00:00:00'
 					) as due_date
@@ -186,6 +319,10 @@ func (r *workspaceRepository) GetScoreboard(workspaceId int) ([]domain.Workspace
 					AND id NOT IN (SELECT submission_id FROM submission_result WHERE status LIKE 'SYSTEM%')
 					AND status != 'GRADING'
 					AND user_id NOT IN (SELECT user_id FROM workspace_participant WHERE workspace_id = ? AND role IN ('ADMIN', 'OWNER'))
+					AND (
+						? IS NULL
+						OR user_id IN (SELECT user_id FROM workspace_group_member WHERE workspace_id = ? AND group_id = ?)
+					)
 			) as i1
 			WHERE i1.submitted_at < i1.due_date
 		)
@@ -218,7 +355,7 @@ func (r *workspaceRepository) GetScoreboard(workspaceId int) ([]domain.Workspace
 		) as t3 ON t1.user_id = t3.user_id
 		INNER JOIN user u ON u.id = t1.user_id
 		ORDER BY score DESC, t3.last_submitted_at ASC, t2.total_submission ASC
-	`, workspaceId, workspaceId)
+	`, workspaceId, workspaceId, groupId, workspaceId, groupId)
 	if err != nil {
 		return nil, fmt.Errorf("cannot query to get workspace scoreboard: %w", err)
 	}
@@ -240,6 +377,31 @@ func (r *workspaceRepository) List(userId string) ([]domain.Workspace, error) {
 	return r.list(workspaceIds, userId)
 }
 
+// ListDiscoverable lists workspaces listed publicly that the user has not joined yet
+func (r *workspaceRepository) ListDiscoverable(userId string) ([]domain.RawWorkspace, error) {
+	workspaces := make([]domain.RawWorkspace, 0)
+	err := r.db.Select(&workspaces, `
+		SELECT
+			w.*,
+			user.display_name AS owner_name,
+			user.profile_url AS owner_profile_url,
+			(SELECT COUNT(*) FROM workspace_participant wp WHERE wp.workspace_id = w.id) AS participant_count,
+			(SELECT COUNT(*) FROM assignment a WHERE a.workspace_id = w.id AND is_deleted = FALSE) AS total_assignment
+		FROM workspace w
+		INNER JOIN user ON user.id = (SELECT user_id FROM workspace_participant WHERE workspace_id = w.id AND role = 'OWNER')
+		WHERE
+			w.is_discoverable = TRUE
+			AND w.is_deleted = FALSE
+			AND w.is_archived = FALSE
+			AND w.id NOT IN (SELECT workspace_id FROM workspace_participant WHERE user_id = ?)
+		ORDER BY w.name
+	`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list discoverable workspace: %w", err)
+	}
+	return workspaces, nil
+}
+
 func (r *workspaceRepository) list(ids []int, userId string) ([]domain.Workspace, error) {
 	workspaces := make([]domain.Workspace, 0)
 	if len(ids) == 0 {
@@ -304,7 +466,9 @@ func (r *workspaceRepository) Update(userId string, workspace *domain.Workspace)
 			UPDATE workspace SET 
 				name = :name,
 				profile_url = :profile_url,
-				is_archived = :is_archived
+				is_archived = :is_archived,
+				is_join_approval = :is_join_approval,
+				is_discoverable = :is_discoverable
 			WHERE id = :id;
 		`, workspace.RawWorkspace)
 		if err != nil {
@@ -337,10 +501,12 @@ func (r *workspaceRepository) UpdateParticipant(
 	workspaceId int,
 	participant *domain.WorkspaceParticipant,
 ) error {
-	_, err := r.db.Exec(
-		"UPDATE workspace_participant SET role = ? WHERE user_id = ? AND workspace_id = ?",
-		participant.Role, userId, workspaceId,
-	)
+	// A custom role is dropped along with the role it was given for
+	_, err := r.db.Exec(`
+		UPDATE workspace_participant
+		SET custom_role_id = IF(role = ?, custom_role_id, NULL), role = ?
+		WHERE user_id = ? AND workspace_id = ?
+	`, participant.Role, participant.Role, userId, workspaceId)
 	if err != nil {
 		return fmt.Errorf("cannot query to update role: %w", err)
 	}
@@ -349,14 +515,43 @@ func (r *workspaceRepository) UpdateParticipant(
 
 func (r *workspaceRepository) Delete(workspaceId int) error {
 	_, err := r.db.Exec(`
-		UPDATE workspace SET is_deleted = TRUE WHERE id = ?
-	`, workspaceId)
+		UPDATE workspace SET is_deleted = TRUE, deleted_at = ? WHERE id = ? AND is_deleted = FALSE
+	`, time.Now(), workspaceId)
 	if err != nil {
 		return fmt.Errorf("cannot query to soft delete workspace: %w", err)
 	}
 	return nil
 }
 
+// Restore brings the workspace back, false is returned when its owner already
+// owns maxOwned workspaces
+func (r *workspaceRepository) Restore(workspaceId int, maxOwned int) (bool, error) {
+	return isWithinQuota(r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if maxOwned != domain.UnlimitedQuota {
+			var ownerId string
+			err := tx.Get(
+				&ownerId,
+				"SELECT user_id FROM workspace_participant WHERE workspace_id = ? AND role = 'OWNER'",
+				workspaceId,
+			)
+			if err != nil {
+				return fmt.Errorf("cannot query to get owner of restored workspace: %w", err)
+			}
+			if err := checkOwnedWorkspaceQuota(tx, ownerId, maxOwned); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec(`
+			UPDATE workspace SET is_deleted = FALSE, deleted_at = NULL WHERE id = ?
+		`, workspaceId)
+		if err != nil {
+			return fmt.Errorf("cannot query to restore workspace: %w", err)
+		}
+		return nil
+	}))
+}
+
 func (r *workspaceRepository) DeleteInvitation(invitationId string) error {
 	_, err := r.db.Exec("DELETE FROM workspace_invitation WHERE id = ?", invitationId)
 	if err != nil {
@@ -366,11 +561,29 @@ func (r *workspaceRepository) DeleteInvitation(invitationId string) error {
 }
 
 func (r *workspaceRepository) DeleteParticipant(workspaceId int, userId string) error {
-	_, err := r.db.Exec(`
-		DELETE FROM workspace_participant WHERE workspace_id = ? AND user_id = ?
-	`, workspaceId, userId)
-	if err != nil {
-		return fmt.Errorf("cannot query to delete workspace participant: %w", err)
-	}
-	return nil
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM workspace_group_manager
+			WHERE user_id = ? AND group_id IN (SELECT id FROM workspace_group WHERE workspace_id = ?)
+		`, userId, workspaceId)
+		if err != nil {
+			return fmt.Errorf("cannot query to delete workspace group manager: %w", err)
+		}
+
+		_, err = tx.Exec(
+			"DELETE FROM workspace_group_member WHERE workspace_id = ? AND user_id = ?",
+			workspaceId, userId,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot query to delete workspace group member: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			DELETE FROM workspace_participant WHERE workspace_id = ? AND user_id = ?
+		`, workspaceId, userId)
+		if err != nil {
+			return fmt.Errorf("cannot query to delete workspace participant: %w", err)
+		}
+		return nil
+	})
 }
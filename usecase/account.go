@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/avatar"
+	"github.com/codern-org/codern/platform"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type accountUsecase struct {
+	seaweedfs          *platform.SeaweedFs
+	accountRepository  domain.AccountRepository
+	userRepository     domain.UserRepository
+	sessionRepository  domain.SessionRepository
+	identityRepository domain.IdentityRepository
+	quotaRepository    domain.QuotaRepository
+}
+
+func NewAccountUsecase(
+	seaweedfs *platform.SeaweedFs,
+	accountRepository domain.AccountRepository,
+	userRepository domain.UserRepository,
+	sessionRepository domain.SessionRepository,
+	identityRepository domain.IdentityRepository,
+	quotaRepository domain.QuotaRepository,
+) domain.AccountUsecase {
+	return &accountUsecase{
+		seaweedfs:          seaweedfs,
+		accountRepository:  accountRepository,
+		userRepository:     userRepository,
+		sessionRepository:  sessionRepository,
+		identityRepository: identityRepository,
+		quotaRepository:    quotaRepository,
+	}
+}
+
+// Export writes a zip archive of everything stored about the user, the source
+// files of the submissions are included under the submissions directory
+func (u *accountUsecase) Export(userId string, w io.Writer) error {
+	user, err := u.getUser(userId)
+	if err != nil {
+		return err
+	}
+
+	identities, err := u.identityRepository.ListByUserId(userId)
+	if err != nil {
+		return errs.New(errs.ErrExportAccount, "cannot list identity of user id %s", userId, err)
+	}
+	sessions, err := u.sessionRepository.ListByUserId(userId)
+	if err != nil {
+		return errs.New(errs.ErrExportAccount, "cannot list session of user id %s", userId, err)
+	}
+	participations, err := u.accountRepository.ListParticipation(userId)
+	if err != nil {
+		return errs.New(errs.ErrExportAccount, "cannot list participation of user id %s", userId, err)
+	}
+	submissions, err := u.accountRepository.ListSubmission(userId)
+	if err != nil {
+		return errs.New(errs.ErrExportAccount, "cannot list submission of user id %s", userId, err)
+	}
+
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", user},
+		{"identities.json", identities},
+		{"sessions.json", sessions},
+		{"participations.json", participations},
+		{"submissions.json", submissions},
+	}
+	for _, file := range files {
+		if err := writeZipJson(archive, file.name, file.data); err != nil {
+			return errs.New(errs.ErrExportAccount, "cannot write %s of user id %s", file.name, userId, err)
+		}
+	}
+
+	if strings.HasPrefix(user.ProfileUrl, "/") {
+		if err := writeZipFile(u.seaweedfs, archive, "profile", user.ProfileUrl); err != nil {
+			return errs.New(errs.ErrExportAccount, "cannot write profile of user id %s", userId, err)
+		}
+	}
+	for _, submission := range submissions {
+		name := fmt.Sprintf(
+			"submissions/%d/%d/%d.%s",
+			submission.WorkspaceId, submission.AssignmentId, submission.Id, submission.Language,
+		)
+		if err := writeZipFile(u.seaweedfs, archive, name, submission.FileUrl); err != nil {
+			return errs.New(errs.ErrExportAccount, "cannot write submission id %d", submission.Id, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return errs.New(errs.ErrExportAccount, "cannot close archive of user id %s", userId, err)
+	}
+	return nil
+}
+
+// Delete is requested by the user themselves, the password is confirmed when the account has one
+func (u *accountUsecase) Delete(userId string, password string) error {
+	user, err := u.getUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if user.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+			return errs.New(errs.ErrUserPassword, "cannot delete account due to invalid password", err)
+		}
+	}
+	return u.Anonymize(userId)
+}
+
+// Anonymize removes the credentials and personal data of the user, the submissions are kept
+// under an anonymous name so scoreboards stay the same. Owned workspaces have to be deleted first.
+func (u *accountUsecase) Anonymize(userId string) error {
+	user, err := u.getUser(userId)
+	if err != nil {
+		return err
+	}
+
+	ownedWorkspaces, err := u.quotaRepository.CountOwnedWorkspace(userId)
+	if err != nil {
+		return errs.New(errs.ErrDeleteAccount, "cannot count owned workspace of user id %s", userId, err)
+	} else if ownedWorkspaces > 0 {
+		return errs.New(
+			errs.ErrAccountOwnsWorkspace,
+			"user id %s still owns %d workspaces", userId, ownedWorkspaces,
+		)
+	}
+
+	submissions, err := u.accountRepository.ListSubmission(userId)
+	if err != nil {
+		return errs.New(errs.ErrDeleteAccount, "cannot list submission of user id %s", userId, err)
+	}
+
+	if err := u.accountRepository.Anonymize(user, time.Now()); err != nil {
+		return errs.New(errs.ErrDeleteAccount, "cannot anonymize user id %s", userId, err)
+	}
+
+	go u.deleteFiles(user, submissions)
+
+	return nil
+}
+
+func (u *accountUsecase) getUser(userId string) (*domain.User, error) {
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUser, "cannot get user id %s", userId, err)
+	} else if user == nil || user.DeletedAt != nil {
+		return nil, errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+	return user, nil
+}
+
+func writeZipFile(seaweedfs *platform.SeaweedFs, archive *zip.Writer, name string, path string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	return seaweedfs.Download(path, func(r io.Reader) error {
+		_, err := io.Copy(file, r)
+		return err
+	})
+}
+
+func (u *accountUsecase) deleteFiles(user *domain.User, submissions []domain.AccountSubmission) {
+	if strings.HasPrefix(user.ProfileUrl, "/") {
+		for _, size := range avatar.Sizes {
+			u.seaweedfs.Delete(avatar.Path(user.ProfileUrl, size), nil)
+		}
+	}
+	for _, submission := range submissions {
+		u.seaweedfs.Delete(submission.FileUrl, nil)
+	}
+}
+
+func writeZipJson(archive *zip.Writer, name string, data interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+)
+
+type adminUsecase struct {
+	userRepository       domain.UserRepository
+	workspaceRepository  domain.WorkspaceRepository
+	assignmentRepository domain.AssignmentRepository
+	sessionUsecase       domain.SessionUsecase
+	accountUsecase       domain.AccountUsecase
+}
+
+func NewAdminUsecase(
+	userRepository domain.UserRepository,
+	workspaceRepository domain.WorkspaceRepository,
+	assignmentRepository domain.AssignmentRepository,
+	sessionUsecase domain.SessionUsecase,
+	accountUsecase domain.AccountUsecase,
+) domain.AdminUsecase {
+	return &adminUsecase{
+		userRepository:       userRepository,
+		workspaceRepository:  workspaceRepository,
+		assignmentRepository: assignmentRepository,
+		sessionUsecase:       sessionUsecase,
+		accountUsecase:       accountUsecase,
+	}
+}
+
+func (u *adminUsecase) SearchUser(query string, limit int, offset int) ([]domain.User, error) {
+	users, err := u.userRepository.Search(query, limit, offset)
+	if err != nil {
+		return nil, errs.New(errs.ErrSearchUser, "cannot search user with query %s", query, err)
+	}
+	return users, nil
+}
+
+// DisableUser also signs the user out, api tokens are rejected while the user is disabled
+func (u *adminUsecase) DisableUser(adminId string, userId string) error {
+	if adminId == userId {
+		return errs.New(errs.ErrAdminSelf, "admin cannot disable their own account")
+	}
+	if _, err := u.getUser(userId); err != nil {
+		return err
+	}
+
+	if err := u.userRepository.UpdateDisabled(userId, true); err != nil {
+		return errs.New(errs.ErrUpdateUserState, "cannot disable user id %s", userId, err)
+	}
+	if _, err := u.sessionUsecase.DestroyByUserId(userId); err != nil {
+		return errs.New(errs.ErrDeleteSession, "cannot sign out disabled user id %s", userId, err)
+	}
+	return nil
+}
+
+func (u *adminUsecase) EnableUser(userId string) error {
+	if _, err := u.getUser(userId); err != nil {
+		return err
+	}
+
+	if err := u.userRepository.UpdateDisabled(userId, false); err != nil {
+		return errs.New(errs.ErrUpdateUserState, "cannot enable user id %s", userId, err)
+	}
+	return nil
+}
+
+func (u *adminUsecase) SignOutUser(userId string) error {
+	if _, err := u.getUser(userId); err != nil {
+		return err
+	}
+
+	if _, err := u.sessionUsecase.DestroyByUserId(userId); err != nil {
+		return errs.New(errs.ErrDeleteSession, "cannot sign out user id %s", userId, err)
+	}
+	return nil
+}
+
+func (u *adminUsecase) DeleteUser(adminId string, userId string) error {
+	if adminId == userId {
+		return errs.New(errs.ErrAdminSelf, "admin cannot delete their own account from admin panel")
+	}
+	if err := u.accountUsecase.Anonymize(userId); err != nil {
+		return errs.New(errs.SameCode, "cannot delete user id %s", userId, err)
+	}
+	return nil
+}
+
+func (u *adminUsecase) GetWorkspace(id int) (*domain.AdminWorkspace, error) {
+	workspace, err := u.workspaceRepository.GetRaw(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetWorkspace, "cannot get workspace id %d", id, err)
+	} else if workspace == nil {
+		return nil, errs.New(errs.ErrWorkspaceNotFound, "workspace id %d not found", id)
+	}
+
+	participants, err := u.workspaceRepository.ListParticipant(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrListWorkspaceParticipant, "cannot list participant of workspace id %d", id, err)
+	}
+
+	assignments, err := u.assignmentRepository.ListWithDeleted(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrListAssignment, "cannot list assignment of workspace id %d", id, err)
+	}
+	adminAssignments := make([]domain.AdminAssignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		adminAssignments = append(adminAssignments, domain.AdminAssignment{
+			Assignment: assignment,
+			IsDeleted:  assignment.IsDeleted,
+		})
+	}
+
+	return &domain.AdminWorkspace{
+		RawWorkspace: *workspace,
+		IsDeleted:    workspace.IsDeleted,
+		Participants: participants,
+		Assignments:  adminAssignments,
+	}, nil
+}
+
+func (u *adminUsecase) RestoreWorkspace(id int) error {
+	workspace, err := u.workspaceRepository.GetRaw(id)
+	if err != nil {
+		return errs.New(errs.ErrGetWorkspace, "cannot get workspace id %d", id, err)
+	} else if workspace == nil {
+		return errs.New(errs.ErrWorkspaceNotFound, "workspace id %d not found", id)
+	}
+
+	// An administrator restores regardless of the owner quota
+	if _, err := u.workspaceRepository.Restore(id, domain.UnlimitedQuota); err != nil {
+		return errs.New(errs.ErrRestoreWorkspace, "cannot restore workspace id %d", id, err)
+	}
+	return nil
+}
+
+func (u *adminUsecase) RestoreAssignment(workspaceId int, assignmentId int) error {
+	assignments, err := u.assignmentRepository.ListWithDeleted(workspaceId)
+	if err != nil {
+		return errs.New(errs.ErrListAssignment, "cannot list assignment of workspace id %d", workspaceId, err)
+	}
+
+	for _, assignment := range assignments {
+		if assignment.Id != assignmentId {
+			continue
+		}
+		if _, err := u.assignmentRepository.Restore(assignmentId, domain.UnlimitedQuota); err != nil {
+			return errs.New(errs.ErrRestoreAssignment, "cannot restore assignment id %d", assignmentId, err)
+		}
+		return nil
+	}
+	return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found in workspace id %d", assignmentId, workspaceId)
+}
+
+func (u *adminUsecase) getUser(id string) (*domain.User, error) {
+	user, err := u.userRepository.Get(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUser, "cannot get user id %s", id, err)
+	} else if user == nil {
+		return nil, errs.New(errs.ErrUserNotFound, "user id %s not found", id)
+	}
+	return user, nil
+}
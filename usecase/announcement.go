@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/platform"
+)
+
+type announcementUsecase struct {
+	wsHub                  *platform.WebSocketHub
+	announcementRepository domain.AnnouncementRepository
+	workspaceUsecase       domain.WorkspaceUsecase
+}
+
+func NewAnnouncementUsecase(
+	wsHub *platform.WebSocketHub,
+	announcementRepository domain.AnnouncementRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+) domain.AnnouncementUsecase {
+	return &announcementUsecase{
+		wsHub:                  wsHub,
+		announcementRepository: announcementRepository,
+		workspaceUsecase:       workspaceUsecase,
+	}
+}
+
+// Create posts the announcement right away unless a publish date in the future is given,
+// a scheduled announcement is pushed by PublishDue once the date is reached
+func (u *announcementUsecase) Create(
+	userId string,
+	workspaceId int,
+	ca *domain.CreateAnnouncement,
+) (*domain.Announcement, error) {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	announcement := &domain.Announcement{
+		Id:          generator.GetId(),
+		WorkspaceId: workspaceId,
+		AuthorId:    userId,
+		Title:       ca.Title,
+		Body:        ca.Body,
+		IsPinned:    ca.IsPinned,
+		PublishDate: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if ca.PublishDate != nil {
+		announcement.PublishDate = *ca.PublishDate
+	}
+
+	isPublishing := announcement.IsPublished()
+	if isPublishing {
+		announcement.NotifiedAt = &now
+	}
+
+	if err := u.announcementRepository.Create(announcement); err != nil {
+		return nil, errs.New(errs.ErrAnnouncement, "cannot create announcement of workspace id %d", workspaceId, err)
+	}
+
+	created, err := u.get(userId, workspaceId, announcement.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isPublishing {
+		go u.broadcast(created, "onAnnouncementPublish")
+	}
+	return created, nil
+}
+
+func (u *announcementUsecase) Get(userId string, workspaceId int, id int) (*domain.Announcement, error) {
+	announcement, err := u.get(userId, workspaceId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !announcement.IsPublished() {
+		if err := u.checkPerm(userId, workspaceId); err != nil {
+			return nil, errs.New(errs.ErrAnnouncementNotFound, "announcement id %d not found", id)
+		}
+	}
+	return announcement, nil
+}
+
+// List returns a page of announcements, the scheduled ones are only listed
+// to the participants able to manage them
+func (u *announcementUsecase) List(
+	userId string,
+	workspaceId int,
+	limit int,
+	offset int,
+) ([]domain.Announcement, error) {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageAnnouncementPerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check permission of user id %s to list announcement", userId, err)
+	}
+
+	announcements, err := u.announcementRepository.List(workspaceId, userId, isAuthorized, limit, offset)
+	if err != nil {
+		return nil, errs.New(errs.ErrAnnouncement, "cannot list announcement of workspace id %d", workspaceId, err)
+	}
+	return announcements, nil
+}
+
+func (u *announcementUsecase) CountUnread(userId string, workspaceId int) (int, error) {
+	count, err := u.announcementRepository.CountUnread(workspaceId, userId)
+	if err != nil {
+		return 0, errs.New(errs.ErrAnnouncement, "cannot count unread announcement of user id %s", userId, err)
+	}
+	return count, nil
+}
+
+func (u *announcementUsecase) Update(
+	userId string,
+	workspaceId int,
+	id int,
+	ua *domain.UpdateAnnouncement,
+) error {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return err
+	}
+	announcement, err := u.get(userId, workspaceId, id)
+	if err != nil {
+		return err
+	}
+
+	if ua.Title != nil {
+		announcement.Title = *ua.Title
+	}
+	if ua.Body != nil {
+		announcement.Body = *ua.Body
+	}
+	if ua.PublishDate != nil {
+		announcement.PublishDate = *ua.PublishDate
+	}
+	return u.update(announcement)
+}
+
+func (u *announcementUsecase) Pin(userId string, workspaceId int, id int, isPinned bool) error {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return err
+	}
+	announcement, err := u.get(userId, workspaceId, id)
+	if err != nil {
+		return err
+	}
+
+	announcement.IsPinned = isPinned
+	return u.update(announcement)
+}
+
+func (u *announcementUsecase) Delete(userId string, workspaceId int, id int) error {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return err
+	}
+	announcement, err := u.get(userId, workspaceId, id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.announcementRepository.Delete(id); err != nil {
+		return errs.New(errs.ErrAnnouncement, "cannot delete announcement id %d", id, err)
+	}
+
+	if announcement.NotifiedAt != nil {
+		go u.broadcast(announcement, "onAnnouncementDelete")
+	}
+	return nil
+}
+
+func (u *announcementUsecase) MarkRead(userId string, workspaceId int, id int) error {
+	if _, err := u.Get(userId, workspaceId, id); err != nil {
+		return err
+	}
+
+	if err := u.announcementRepository.MarkRead(id, userId); err != nil {
+		return errs.New(errs.ErrAnnouncement, "cannot mark announcement id %d as read", id, err)
+	}
+	return nil
+}
+
+// PublishDue pushes the scheduled announcements whose publish date is reached,
+// the number of pushed announcements is returned
+func (u *announcementUsecase) PublishDue() (int, error) {
+	now := time.Now()
+
+	announcements, err := u.announcementRepository.ListDue(now)
+	if err != nil {
+		return 0, errs.New(errs.ErrAnnouncement, "cannot list due announcement", err)
+	}
+
+	published := 0
+	for i := range announcements {
+		// Another instance may have pushed it in the meantime
+		isClaimed, err := u.announcementRepository.MarkNotified(announcements[i].Id, now)
+		if err != nil {
+			return published, errs.New(errs.ErrAnnouncement, "cannot mark announcement id %d as notified", announcements[i].Id, err)
+		} else if !isClaimed {
+			continue
+		}
+
+		announcements[i].NotifiedAt = &now
+		u.broadcast(&announcements[i], "onAnnouncementPublish")
+		published++
+	}
+	return published, nil
+}
+
+// update stores the announcement and tells the participants who already received it,
+// moving a published announcement back to the future withdraws it until then
+func (u *announcementUsecase) update(announcement *domain.Announcement) error {
+	now := time.Now()
+	wasNotified := announcement.NotifiedAt != nil
+
+	channel := "onAnnouncementUpdate"
+	if !announcement.IsPublished() {
+		announcement.NotifiedAt = nil
+		channel = "onAnnouncementDelete"
+	} else if !wasNotified {
+		announcement.NotifiedAt = &now
+		channel = "onAnnouncementPublish"
+	}
+	announcement.UpdatedAt = now
+
+	if err := u.announcementRepository.Update(announcement); err != nil {
+		return errs.New(errs.ErrAnnouncement, "cannot update announcement id %d", announcement.Id, err)
+	}
+
+	if wasNotified || announcement.NotifiedAt != nil {
+		go u.broadcast(announcement, channel)
+	}
+	return nil
+}
+
+func (u *announcementUsecase) get(userId string, workspaceId int, id int) (*domain.Announcement, error) {
+	announcement, err := u.announcementRepository.Get(id, userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrAnnouncement, "cannot get announcement id %d", id, err)
+	} else if announcement == nil || announcement.WorkspaceId != workspaceId {
+		return nil, errs.New(errs.ErrAnnouncementNotFound, "announcement id %d not found", id)
+	}
+	return announcement, nil
+}
+
+func (u *announcementUsecase) checkPerm(userId string, workspaceId int) error {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageAnnouncementPerm)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to manage announcement", userId, err)
+	} else if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot manage announcement of workspace id %d", userId, workspaceId)
+	}
+	return nil
+}
+
+// broadcast pushes to the participants connected at the moment,
+// the others see the announcement in the feed on their next visit
+func (u *announcementUsecase) broadcast(announcement *domain.Announcement, channel string) {
+	participants, err := u.workspaceUsecase.ListParticipant(announcement.WorkspaceId)
+	if err != nil {
+		return
+	}
+
+	// The read state belongs to whoever loaded the announcement
+	message := *announcement
+	message.IsRead = false
+	for _, participant := range participants {
+		u.wsHub.SendMessage(participant.UserId, channel, message)
+	}
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+)
+
+type apiTokenUsecase struct {
+	apiTokenRepository domain.ApiTokenRepository
+}
+
+func NewApiTokenUsecase(
+	apiTokenRepository domain.ApiTokenRepository,
+) domain.ApiTokenUsecase {
+	return &apiTokenUsecase{
+		apiTokenRepository: apiTokenRepository,
+	}
+}
+
+func (u *apiTokenUsecase) Create(
+	userId string,
+	name string,
+	scope domain.ApiTokenScope,
+	expiredAt *time.Time,
+) (*domain.ApiToken, error) {
+	if scope != domain.ReadScope && scope != domain.WriteScope {
+		return nil, errs.New(errs.ErrInvalidApiTokenScope, "api token scope %s is invalid", scope)
+	}
+	if expiredAt != nil && !time.Now().Before(*expiredAt) {
+		return nil, errs.New(errs.ErrInvalidApiTokenExpiry, "api token expiration must be in the future")
+	}
+
+	count, err := u.apiTokenRepository.CountByUserId(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrCreateApiToken, "cannot count api token of user id %s", userId, err)
+	} else if count >= constant.MaxApiTokenPerUser {
+		return nil, errs.New(errs.ErrApiTokenLimit, "user id %s reached the limit of %d api tokens", userId, constant.MaxApiTokenPerUser)
+	}
+
+	randToken, err := generator.RandToken(32)
+	if err != nil {
+		return nil, errs.New(errs.ErrCreateApiToken, "cannot generate api token", err)
+	}
+	plainToken := constant.ApiTokenPrefix + randToken
+
+	token := &domain.ApiToken{
+		Id:        generator.GetId(),
+		UserId:    userId,
+		Name:      name,
+		Hash:      generator.HashToken(plainToken),
+		Hint:      plainToken[:len(constant.ApiTokenPrefix)+4],
+		Scope:     scope,
+		CreatedAt: time.Now(),
+		ExpiredAt: expiredAt,
+	}
+	if err := u.apiTokenRepository.Create(token); err != nil {
+		return nil, errs.New(errs.ErrCreateApiToken, "cannot create api token for user id %s", userId, err)
+	}
+
+	token.Token = plainToken
+	return token, nil
+}
+
+func (u *apiTokenUsecase) List(userId string) ([]domain.ApiToken, error) {
+	tokens, err := u.apiTokenRepository.ListByUserId(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetApiToken, "cannot list api token of user id %s", userId, err)
+	}
+	return tokens, nil
+}
+
+func (u *apiTokenUsecase) Revoke(userId string, id int) error {
+	token, err := u.apiTokenRepository.Get(id)
+	if err != nil {
+		return errs.New(errs.ErrGetApiToken, "cannot get api token id %d", id, err)
+	} else if token == nil || token.UserId != userId {
+		return errs.New(errs.ErrApiTokenNotFound, "api token id %d not found", id)
+	}
+
+	if err := u.apiTokenRepository.Delete(id); err != nil {
+		return errs.New(errs.ErrDeleteApiToken, "cannot delete api token id %d", id, err)
+	}
+	return nil
+}
+
+func (u *apiTokenUsecase) Validate(plainToken string) (*domain.ApiToken, error) {
+	token, err := u.apiTokenRepository.GetByHash(generator.HashToken(plainToken))
+	if err != nil {
+		return nil, errs.New(errs.ErrGetApiToken, "cannot get api token", err)
+	} else if token == nil {
+		return nil, errs.New(errs.ErrApiTokenInvalid, "api token is invalid")
+	}
+
+	now := time.Now()
+	if token.ExpiredAt != nil && !now.Before(*token.ExpiredAt) {
+		return nil, errs.New(errs.ErrApiTokenExpired, "api token id %d expired", token.Id)
+	}
+
+	// Throttle the write since the token can be used on every request of a script
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= constant.ApiTokenLastUsedInterval {
+		go u.apiTokenRepository.UpdateLastUsed(token.Id, now)
+		token.LastUsedAt = &now
+	}
+	return token, nil
+}
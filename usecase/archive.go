@@ -0,0 +1,473 @@
+package usecase
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/platform"
+)
+
+type archiveUsecase struct {
+	seaweedfs            *platform.SeaweedFs
+	trashRepository      domain.TrashRepository
+	assignmentRepository domain.AssignmentRepository
+	workspaceUsecase     domain.WorkspaceUsecase
+	assignmentUsecase    domain.AssignmentUsecase
+	quotaUsecase         domain.QuotaUsecase
+}
+
+func NewArchiveUsecase(
+	seaweedfs *platform.SeaweedFs,
+	trashRepository domain.TrashRepository,
+	assignmentRepository domain.AssignmentRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+	assignmentUsecase domain.AssignmentUsecase,
+	quotaUsecase domain.QuotaUsecase,
+) domain.ArchiveUsecase {
+	return &archiveUsecase{
+		seaweedfs:            seaweedfs,
+		trashRepository:      trashRepository,
+		assignmentRepository: assignmentRepository,
+		workspaceUsecase:     workspaceUsecase,
+		assignmentUsecase:    assignmentUsecase,
+		quotaUsecase:         quotaUsecase,
+	}
+}
+
+// Export writes the workspace as a zip archive with the manifest first, followed by
+// the profile, the detail and testcase files and optionally the submitted source codes
+func (u *archiveUsecase) Export(userId string, workspaceId int, includeSubmissions bool, w io.Writer) error {
+	permissions, err := u.workspaceUsecase.GetPermissions(userId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get permission of user id %s to export workspace", userId, err)
+	} else if !permissions.Has(domain.ManageWorkspacePerm) ||
+		(includeSubmissions && !permissions.Has(domain.ViewAllSubmissionPerm)) {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot export workspace id %d", userId, workspaceId)
+	}
+
+	workspace, err := u.workspaceUsecase.GetRaw(workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace id %d to export", workspaceId, err)
+	} else if workspace == nil || workspace.IsDeleted {
+		return errs.New(errs.ErrWorkspaceNotFound, "workspace id %d not found", workspaceId)
+	}
+
+	manifest := &domain.WorkspaceArchive{
+		Version:    domain.WorkspaceArchiveVersion,
+		ExportedAt: time.Now(),
+		Workspace: domain.ArchiveWorkspace{
+			Name:             workspace.Name,
+			IsOpenScoreboard: workspace.IsOpenScoreboard,
+			IsJoinApproval:   workspace.IsJoinApproval,
+			IsDiscoverable:   workspace.IsDiscoverable,
+		},
+		Assignments: make([]domain.ArchiveAssignment, 0),
+	}
+	// Archive paths mapped to their paths in SeaweedFS
+	files := make([][2]string, 0)
+
+	if strings.HasPrefix(workspace.ProfileUrl, "/") {
+		name := "workspace/profile"
+		manifest.Workspace.ProfileFile = &name
+		files = append(files, [2]string{name, workspace.ProfileUrl})
+	}
+
+	rawAssignments, err := u.assignmentRepository.ListWithDeleted(workspaceId)
+	if err != nil {
+		return errs.New(errs.ErrExportWorkspace, "cannot list assignment of workspace id %d", workspaceId, err)
+	}
+	for _, rawAssignment := range rawAssignments {
+		if rawAssignment.IsDeleted {
+			continue
+		}
+		assignment, err := u.assignmentRepository.Get(rawAssignment.Id)
+		if err != nil {
+			return errs.New(errs.ErrExportWorkspace, "cannot get assignment id %d to export", rawAssignment.Id, err)
+		} else if assignment == nil {
+			return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", rawAssignment.Id)
+		}
+
+		dir := fmt.Sprintf("assignments/%d", len(manifest.Assignments)+1)
+		detailFile := dir + "/detail" + path.Ext(assignment.DetailUrl)
+		files = append(files, [2]string{detailFile, assignment.DetailUrl})
+
+		archived := domain.ArchiveAssignment{
+			Name:              assignment.Name,
+			Description:       assignment.Description,
+			DetailFile:        detailFile,
+			MemoryLimit:       assignment.MemoryLimit,
+			TimeLimit:         assignment.TimeLimit,
+			Level:             assignment.Level,
+			PublishDate:       assignment.PublishDate,
+			DueDate:           assignment.DueDate,
+			IsAutoTrimEnabled: assignment.IsAutoTrimEnabled,
+			Testcases:         make([]domain.ArchiveTestcase, len(assignment.Testcases)),
+		}
+		for i, testcase := range assignment.Testcases {
+			archived.Testcases[i] = domain.ArchiveTestcase{
+				InputFile:  fmt.Sprintf("%s/testcases/%d.in", dir, i+1),
+				OutputFile: fmt.Sprintf("%s/testcases/%d.out", dir, i+1),
+			}
+			files = append(files,
+				[2]string{archived.Testcases[i].InputFile, testcase.InputFileUrl},
+				[2]string{archived.Testcases[i].OutputFile, testcase.OutputFileUrl},
+			)
+		}
+
+		if includeSubmissions {
+			submissions, err := u.assignmentRepository.ListSubmission(nil, &assignment.Id, nil)
+			if err != nil {
+				return errs.New(errs.ErrExportWorkspace, "cannot list submission of assignment id %d", assignment.Id, err)
+			}
+			for _, submission := range submissions {
+				file := fmt.Sprintf("%s/submissions/%d.%s", dir, submission.Id, submission.Language)
+				archived.Submissions = append(archived.Submissions, domain.ArchiveSubmission{
+					SubmitterId:   submission.SubmitterId,
+					SubmitterName: submission.SubmitterName,
+					Language:      submission.Language,
+					Status:        submission.Status,
+					Score:         submission.Score,
+					SubmittedAt:   submission.SubmittedAt,
+					File:          file,
+				})
+				files = append(files, [2]string{file, submission.FileUrl})
+			}
+		}
+
+		manifest.Assignments = append(manifest.Assignments, archived)
+	}
+
+	archive := zip.NewWriter(w)
+	if err := writeZipJson(archive, domain.WorkspaceArchiveManifest, manifest); err != nil {
+		return errs.New(errs.ErrExportWorkspace, "cannot write manifest of workspace id %d", workspaceId, err)
+	}
+	for _, file := range files {
+		if err := writeZipFile(u.seaweedfs, archive, file[0], file[1]); err != nil {
+			return errs.New(errs.ErrExportWorkspace, "cannot write %s of workspace id %d", file[0], workspaceId, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return errs.New(errs.ErrExportWorkspace, "cannot close archive of workspace id %d", workspaceId, err)
+	}
+	return nil
+}
+
+// Import creates a workspace owned by the user from an exported archive, the archive is
+// checked as a whole first so a dry run reports every problem without creating anything
+func (u *archiveUsecase) Import(
+	userId string,
+	name string,
+	r io.ReaderAt,
+	size int64,
+	dryRun bool,
+) (*domain.ImportReport, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errs.New(errs.ErrInvalidArchive, "cannot read workspace archive", err)
+	}
+	if err := checkArchiveSize(archive.File); err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	manifestFile, ok := files[domain.WorkspaceArchiveManifest]
+	if !ok {
+		return nil, errs.New(errs.ErrInvalidArchive, "workspace archive has no manifest")
+	}
+	var manifest domain.WorkspaceArchive
+	if err := readZipJson(manifestFile, &manifest); err != nil {
+		return nil, errs.New(errs.ErrInvalidArchive, "cannot parse workspace archive manifest", err)
+	}
+	if manifest.Version < 1 || manifest.Version > domain.WorkspaceArchiveVersion {
+		return nil, errs.New(
+			errs.ErrInvalidArchive,
+			"workspace archive version %d is not supported, the latest is %d",
+			manifest.Version, domain.WorkspaceArchiveVersion,
+		)
+	}
+	if name != "" {
+		manifest.Workspace.Name = name
+	}
+
+	report := u.validate(files, &manifest)
+	report.DryRun = dryRun
+	if err := u.checkQuota(userId, files, &manifest, report); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return report, nil
+	} else if len(report.Problems) > 0 {
+		return nil, errs.New(
+			errs.ErrInvalidArchive,
+			"workspace archive has %d problems: %s", len(report.Problems), strings.Join(report.Problems, "; "),
+		)
+	}
+
+	workspace, err := u.create(userId, files, &manifest)
+	if err != nil {
+		return nil, err
+	}
+	report.Workspace = workspace
+	return report, nil
+}
+
+func (u *archiveUsecase) validate(files map[string]*zip.File, manifest *domain.WorkspaceArchive) *domain.ImportReport {
+	report := &domain.ImportReport{
+		Version:       manifest.Version,
+		WorkspaceName: manifest.Workspace.Name,
+		Assignments:   len(manifest.Assignments),
+		Problems:      make([]string, 0),
+	}
+	problem := func(format string, args ...interface{}) {
+		report.Problems = append(report.Problems, fmt.Sprintf(format, args...))
+	}
+
+	if strings.TrimSpace(manifest.Workspace.Name) == "" {
+		problem("workspace has no name")
+	}
+	if file := manifest.Workspace.ProfileFile; file != nil && files[*file] == nil {
+		problem("workspace profile %s is missing", *file)
+	}
+
+	for i, assignment := range manifest.Assignments {
+		label := fmt.Sprintf("assignment %d (%s)", i+1, assignment.Name)
+		if strings.TrimSpace(assignment.Name) == "" {
+			problem("%s has no name", label)
+		}
+		if !assignment.Level.IsValid() {
+			problem("%s has invalid level %s", label, assignment.Level)
+		}
+		if assignment.MemoryLimit <= 0 || assignment.TimeLimit <= 0 {
+			problem("%s has invalid memory or time limit", label)
+		}
+		if assignment.DueDate != nil && assignment.DueDate.Before(assignment.PublishDate) {
+			problem("%s is due before it is published", label)
+		}
+		if files[assignment.DetailFile] == nil {
+			problem("%s detail file %s is missing", label, assignment.DetailFile)
+		} else if !strings.HasSuffix(assignment.DetailFile, ".md") && !strings.HasSuffix(assignment.DetailFile, ".pdf") {
+			problem("%s detail file %s must be markdown or pdf", label, assignment.DetailFile)
+		}
+		if len(assignment.Testcases) == 0 {
+			problem("%s has no testcase", label)
+		}
+		for j, testcase := range assignment.Testcases {
+			for _, name := range []string{testcase.InputFile, testcase.OutputFile} {
+				if files[name] == nil {
+					problem("%s testcase %d file %s is missing", label, j+1, name)
+				}
+			}
+		}
+		report.Testcases += len(assignment.Testcases)
+		report.SkippedSubmissions += len(assignment.Submissions)
+	}
+	return report
+}
+
+// checkQuota reports the quotas the import would go over as problems
+func (u *archiveUsecase) checkQuota(
+	userId string,
+	files map[string]*zip.File,
+	manifest *domain.WorkspaceArchive,
+	report *domain.ImportReport,
+) error {
+	usage, err := u.quotaUsecase.GetUsage(userId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get usage of user id %s to import workspace", userId, err)
+	}
+
+	quota := usage.Quota
+	remainingBytes := int64(math.MaxInt64 - 1)
+	if quota.TestcaseBytes != domain.UnlimitedQuota {
+		remainingBytes = max(quota.TestcaseBytes-usage.TestcaseBytes, 0)
+	}
+	report.TestcaseBytes, err = readTestcaseBytes(files, manifest, remainingBytes)
+	if err != nil {
+		report.Problems = append(report.Problems, err.Error())
+	}
+
+	if quota.OwnedWorkspaces != domain.UnlimitedQuota && usage.OwnedWorkspaces >= quota.OwnedWorkspaces {
+		report.Problems = append(report.Problems, fmt.Sprintf(
+			"%s account can own up to %d workspaces", usage.AccountType, quota.OwnedWorkspaces,
+		))
+	}
+	if quota.AssignmentsPerWorkspace != domain.UnlimitedQuota && report.Assignments > quota.AssignmentsPerWorkspace {
+		report.Problems = append(report.Problems, fmt.Sprintf(
+			"%s account can have up to %d assignments per workspace", usage.AccountType, quota.AssignmentsPerWorkspace,
+		))
+	}
+	if report.TestcaseBytes > remainingBytes {
+		report.Problems = append(report.Problems, fmt.Sprintf(
+			"%s account can store up to %d bytes of testcases", usage.AccountType, quota.TestcaseBytes,
+		))
+	}
+	return nil
+}
+
+func (u *archiveUsecase) create(
+	userId string,
+	files map[string]*zip.File,
+	manifest *domain.WorkspaceArchive,
+) (*domain.RawWorkspace, error) {
+	// Every opened file is closed once the workspace is created
+	opened := make([]io.Closer, 0)
+	defer func() {
+		for _, file := range opened {
+			file.Close()
+		}
+	}()
+	// The size is known up front so the entries are streamed rather than buffered to be measured
+	open := func(name string) (io.Reader, error) {
+		file, err := files[name].Open()
+		if err != nil {
+			return nil, errs.New(errs.ErrInvalidArchive, "cannot open %s in workspace archive", name, err)
+		}
+		opened = append(opened, file)
+		return &domain.SizedReader{Reader: file, Size: int64(files[name].UncompressedSize64)}, nil
+	}
+
+	var profile io.Reader
+	if manifest.Workspace.ProfileFile != nil {
+		file, err := open(*manifest.Workspace.ProfileFile)
+		if err != nil {
+			return nil, err
+		}
+		profile = file
+	}
+
+	workspace, err := u.workspaceUsecase.Create(userId, &domain.CreateWorkspace{
+		Name:             manifest.Workspace.Name,
+		Profile:          profile,
+		IsOpenScoreboard: manifest.Workspace.IsOpenScoreboard,
+		IsJoinApproval:   manifest.Workspace.IsJoinApproval,
+		IsDiscoverable:   manifest.Workspace.IsDiscoverable,
+	})
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create workspace to import", err)
+	}
+
+	for i, assignment := range manifest.Assignments {
+		if err := u.createAssignment(userId, workspace.Id, &assignment, open); err != nil {
+			err = discardWorkspace(u.seaweedfs, u.trashRepository, workspace.Id, err)
+			return nil, errs.New(errs.SameCode, "cannot import assignment %d", i+1, err)
+		}
+	}
+	return workspace, nil
+}
+
+func (u *archiveUsecase) createAssignment(
+	userId string,
+	workspaceId int,
+	assignment *domain.ArchiveAssignment,
+	open func(name string) (io.Reader, error),
+) error {
+	detail, err := open(assignment.DetailFile)
+	if err != nil {
+		return err
+	}
+	mimeType := "text/plain"
+	if strings.HasSuffix(assignment.DetailFile, ".pdf") {
+		mimeType = "application/pdf"
+	}
+
+	testcaseFiles := make([]domain.TestcaseFile, len(assignment.Testcases))
+	for i, testcase := range assignment.Testcases {
+		input, err := open(testcase.InputFile)
+		if err != nil {
+			return err
+		}
+		output, err := open(testcase.OutputFile)
+		if err != nil {
+			return err
+		}
+		testcaseFiles[i] = domain.TestcaseFile{Input: input, Output: output}
+	}
+
+	return u.assignmentUsecase.Create(userId, workspaceId, &domain.CreateAssignment{
+		Name:              assignment.Name,
+		Description:       assignment.Description,
+		MemoryLimit:       assignment.MemoryLimit,
+		TimeLimit:         assignment.TimeLimit,
+		Level:             assignment.Level,
+		PublishDate:       assignment.PublishDate,
+		DueDate:           assignment.DueDate,
+		DetailFile:        &domain.File{Reader: detail, MimeType: mimeType},
+		TestcaseFiles:     testcaseFiles,
+		IsAutoTrimEnabled: assignment.IsAutoTrimEnabled,
+	})
+}
+
+// readTestcaseBytes counts the bytes the testcase files actually hold rather than the sizes
+// the archive declares, reading stops as soon as more than limit bytes are read
+func readTestcaseBytes(
+	files map[string]*zip.File,
+	manifest *domain.WorkspaceArchive,
+	limit int64,
+) (int64, error) {
+	var total int64
+	for _, assignment := range manifest.Assignments {
+		for _, testcase := range assignment.Testcases {
+			for _, name := range []string{testcase.InputFile, testcase.OutputFile} {
+				file := files[name]
+				if file == nil {
+					continue
+				}
+
+				reader, err := file.Open()
+				if err != nil {
+					return total, fmt.Errorf("cannot open testcase file %s: %w", name, err)
+				}
+				n, err := io.Copy(io.Discard, io.LimitReader(reader, limit-total+1))
+				reader.Close()
+				total += n
+				if err != nil {
+					return total, fmt.Errorf("cannot read testcase file %s: %w", name, err)
+				} else if total > limit {
+					return total, nil
+				}
+			}
+		}
+	}
+	return total, nil
+}
+
+// checkArchiveSize rejects an archive that would decompress to more than the limits before
+// any entry is read, the zip reader fails an entry holding more than its declared size
+func checkArchiveSize(files []*zip.File) error {
+	var total uint64
+	for _, file := range files {
+		if file.UncompressedSize64 > uint64(constant.MaxArchiveFileSize) {
+			return errs.New(
+				errs.ErrInvalidArchive,
+				"%s in workspace archive is larger than %d bytes", file.Name, constant.MaxArchiveFileSize,
+			)
+		}
+		total += file.UncompressedSize64
+	}
+	if total > uint64(constant.MaxArchiveSize) {
+		return errs.New(errs.ErrInvalidArchive, "workspace archive is larger than %d bytes", constant.MaxArchiveSize)
+	}
+	return nil
+}
+
+func readZipJson(file *zip.File, data interface{}) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return json.NewDecoder(reader).Decode(data)
+}
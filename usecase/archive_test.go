@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"archive/zip"
+	"testing"
+
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+)
+
+func TestCheckArchiveSize(t *testing.T) {
+	entry := func(size int) *zip.File {
+		return &zip.File{FileHeader: zip.FileHeader{Name: "entry", UncompressedSize64: uint64(size)}}
+	}
+	repeat := func(count int, size int) []*zip.File {
+		files := make([]*zip.File, count)
+		for i := range files {
+			files[i] = entry(size)
+		}
+		return files
+	}
+	perArchive := constant.MaxArchiveSize / constant.MaxArchiveFileSize
+
+	tests := []struct {
+		name    string
+		files   []*zip.File
+		isValid bool
+	}{
+		{"empty", nil, true},
+		{"max entry", []*zip.File{entry(constant.MaxArchiveFileSize)}, true},
+		{"entry over the limit", []*zip.File{entry(constant.MaxArchiveFileSize + 1)}, false},
+		{"max archive", repeat(perArchive, constant.MaxArchiveFileSize), true},
+		{"archive over the limit", append(repeat(perArchive, constant.MaxArchiveFileSize), entry(1)), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkArchiveSize(test.files)
+			if test.isValid && err != nil {
+				t.Fatalf("checkArchiveSize returned error: %v", err)
+			} else if !test.isValid && !errs.HasCode(err, errs.ErrInvalidArchive) {
+				t.Fatalf("checkArchiveSize error = %v, want code %d", err, errs.ErrInvalidArchive)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,6 +19,9 @@ type assignmentUsecase struct {
 	assignmentRepository domain.AssignmentRepository
 	gradingPublisher     domain.GradingPublisher
 	workspaceUsecase     domain.WorkspaceUsecase
+	userUsecase          domain.UserUsecase
+	quotaUsecase         domain.QuotaUsecase
+	groupUsecase         domain.GroupUsecase
 }
 
 func NewAssignmentUsecase(
@@ -25,12 +29,18 @@ func NewAssignmentUsecase(
 	assignmentRepository domain.AssignmentRepository,
 	gradingPublisher domain.GradingPublisher,
 	workspaceUsecase domain.WorkspaceUsecase,
+	userUsecase domain.UserUsecase,
+	quotaUsecase domain.QuotaUsecase,
+	groupUsecase domain.GroupUsecase,
 ) domain.AssignmentUsecase {
 	return &assignmentUsecase{
 		seaweedfs:            seaweedfs,
 		assignmentRepository: assignmentRepository,
 		gradingPublisher:     gradingPublisher,
 		workspaceUsecase:     workspaceUsecase,
+		userUsecase:          userUsecase,
+		quotaUsecase:         quotaUsecase,
+		groupUsecase:         groupUsecase,
 	}
 }
 
@@ -39,14 +49,22 @@ func (u *assignmentUsecase) Create(
 	workspaceId int,
 	ca *domain.CreateAssignment,
 ) error {
-	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, workspaceId)
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageAssignmentPerm)
 	if err != nil {
-		return errs.New(errs.SameCode, "cannot get workspace role while creating assignment", err)
+		return errs.New(errs.SameCode, "cannot get workspace permission while creating assignment", err)
 	}
 	if !isAuthorized {
 		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
 	}
 
+	maxAssignments, err := u.quotaUsecase.CheckAssignment(workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot create assignment in workspace id %d", workspaceId, err)
+	}
+	if _, err := u.checkTestcaseQuota(workspaceId, 0, ca.TestcaseFiles); err != nil {
+		return errs.New(errs.SameCode, "cannot create assignment in workspace id %d", workspaceId, err)
+	}
+
 	fileExt := "md"
 	if ca.DetailFile.MimeType == "application/pdf" {
 		fileExt = "pdf"
@@ -69,10 +87,18 @@ func (u *assignmentUsecase) Create(
 		Level:       ca.Level,
 		PublishDate: ca.PublishDate,
 		DueDate:     ca.DueDate,
+
+		IsAutoTrimEnabled: ca.IsAutoTrimEnabled,
 	}
 
-	if err := u.assignmentRepository.Create(assignment); err != nil {
+	isCreated, err := u.assignmentRepository.Create(assignment, maxAssignments)
+	if err != nil {
 		return errs.New(errs.ErrCreateAssignment, "cannot create assignment", err)
+	} else if !isCreated {
+		return errs.New(
+			errs.ErrAssignmentQuota,
+			"workspace id %d has reached the limit of %d assignments", workspaceId, maxAssignments,
+		)
 	}
 
 	// TODO: retry strategy, error
@@ -100,9 +126,9 @@ func (u *assignmentUsecase) Update(
 		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
 	}
 
-	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, assignment.WorkspaceId, domain.ManageAssignmentPerm)
 	if err != nil {
-		return errs.New(errs.SameCode, "cannot get workspace role while updating assignment", err)
+		return errs.New(errs.SameCode, "cannot get workspace permission while updating assignment", err)
 	}
 	if !isAuthorized {
 		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
@@ -165,9 +191,9 @@ func (u *assignmentUsecase) Delete(userId string, id int) error {
 		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", id)
 	}
 
-	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, assignment.WorkspaceId, domain.ManageAssignmentPerm)
 	if err != nil {
-		return errs.New(errs.SameCode, "cannot get workspace role while deleting assignment", err)
+		return errs.New(errs.SameCode, "cannot get workspace permission while deleting assignment", err)
 	}
 	if !isAuthorized {
 		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
@@ -190,6 +216,11 @@ func (u *assignmentUsecase) CreateTestcases(assignmentId int, files []domain.Tes
 		return errs.New(errs.SameCode, "cannot get assignment id %d while creating testcase", assignmentId)
 	}
 
+	maxBytes, err := u.checkTestcaseQuota(assignment.WorkspaceId, assignmentId, files)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot create testcase of assignment id %d", assignmentId, err)
+	}
+
 	testcases := make([]domain.Testcase, len(files))
 	for i, file := range files {
 		id := generator.GetId()
@@ -209,6 +240,7 @@ func (u *assignmentUsecase) CreateTestcases(assignmentId int, files []domain.Tes
 			AssignmentId:  assignmentId,
 			InputFileUrl:  inputFilePath,
 			OutputFileUrl: outputFilePath,
+			Size:          file.Size,
 		}
 
 		// TODO: retry strategy, error
@@ -220,8 +252,11 @@ func (u *assignmentUsecase) CreateTestcases(assignmentId int, files []domain.Tes
 		}
 	}
 
-	if err := u.assignmentRepository.CreateTestcases(testcases); err != nil {
+	isCreated, err := u.assignmentRepository.CreateTestcases(testcases, maxBytes)
+	if err != nil {
 		return errs.New(errs.ErrCreateTestcase, "cannot create testcase", err)
+	} else if !isCreated {
+		return errs.New(errs.ErrTestcaseQuota, "testcases exceed the storage limit of %d bytes", maxBytes)
 	}
 	return nil
 }
@@ -232,6 +267,11 @@ func (u *assignmentUsecase) UpdateTestcases(assignmentId int, testcaseFiles []do
 		return errs.New(errs.SameCode, "cannot get assignment id %d while updating testcase", assignmentId, err)
 	}
 
+	// Check before the current testcases are deleted
+	if _, err := u.checkTestcaseQuota(assignment.WorkspaceId, assignmentId, testcaseFiles); err != nil {
+		return errs.New(errs.SameCode, "cannot update testcase of assignment id %d", assignmentId, err)
+	}
+
 	testcaseFileUrl := fmt.Sprintf("/workspaces/%d/assignments/%d/testcase/", assignment.WorkspaceId, assignment.Id)
 
 	if err := u.seaweedfs.DeleteDirectory(testcaseFileUrl); err != nil {
@@ -252,6 +292,18 @@ func (u *assignmentUsecase) CreateSubmission(
 	language string,
 	file io.Reader,
 ) error {
+	isEmailVerified, err := u.userUsecase.IsEmailVerified(userId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check email verification of user id %s while submitting", userId, err)
+	} else if !isEmailVerified {
+		return errs.New(errs.ErrEmailNotVerified, "user id %s must verify email before submitting", userId)
+	}
+
+	maxSubmissions, err := u.quotaUsecase.CheckDailySubmission(userId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot submit assignment id %d", assignmentId, err)
+	}
+
 	id := generator.GetId()
 	filePath := fmt.Sprintf(
 		"/workspaces/%d/assignments/%d/submissions/%s/%d",
@@ -276,8 +328,13 @@ func (u *assignmentUsecase) CreateSubmission(
 		return errs.New(errs.ErrAssignmentNoTestcase, "invalid assignment id %d", assignmentId)
 	}
 
-	if err := u.assignmentRepository.CreateSubmission(submission, assignment.Testcases); err != nil {
+	isCreated, err := u.assignmentRepository.CreateSubmission(
+		submission, assignment.Testcases, maxSubmissions, startOfDay(time.Now()),
+	)
+	if err != nil {
 		return errs.New(errs.ErrCreateSubmission, "cannot create submission", err)
+	} else if !isCreated {
+		return errs.New(errs.ErrSubmissionQuota, "user id %s can submit up to %d times a day", userId, maxSubmissions)
 	}
 
 	// TODO: retry strategy, error
@@ -341,12 +398,24 @@ func (u *assignmentUsecase) GetWithStatus(id int, userId string) (*domain.Assign
 	}
 	assignment.MaxScore = assignment.GetMaxScore()
 
-	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, assignment.WorkspaceId, domain.ManageAssignmentPerm)
 	if err != nil {
-		return nil, errs.New(errs.SameCode, "cannot get workspace role while get assignment with status", err)
+		return nil, errs.New(errs.SameCode, "cannot get workspace permission while get assignment with status", err)
 	}
 
-	if !isAuthorized && time.Now().Before(assignment.PublishDate) {
+	if isAuthorized {
+		return assignment, nil
+	}
+
+	schedules, err := u.groupUsecase.GetScheduleByUser(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get group schedule while get assignment with status", err)
+	}
+	if schedule, ok := schedules[assignment.Id]; ok {
+		schedule.Apply(&assignment.Assignment)
+	}
+
+	if time.Now().Before(assignment.PublishDate) {
 		return nil, errs.New(errs.ErrGetAssignment, "invalid assignment id %d", id, err)
 	}
 
@@ -370,17 +439,26 @@ func (u *assignmentUsecase) List(userId string, workspaceId int) ([]domain.Assig
 		assignments[i].MaxScore = assignments[i].GetMaxScore()
 	}
 
-	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, workspaceId)
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageAssignmentPerm)
 	if err != nil {
-		return nil, errs.New(errs.SameCode, "cannot get workspace role while list assignment with status", err)
+		return nil, errs.New(errs.SameCode, "cannot get workspace permission while list assignment with status", err)
 	}
 
 	if isAuthorized {
 		return assignments, nil
 	}
 
+	// Members see the dates set for their group
+	schedules, err := u.groupUsecase.GetScheduleByUser(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get group schedule while list assignment with status", err)
+	}
+
 	filteredAssignments := make([]domain.AssignmentWithStatus, 0, len(assignments))
 	for _, assignment := range assignments {
+		if schedule, ok := schedules[assignment.Id]; ok {
+			schedule.Apply(&assignment.Assignment)
+		}
 		if time.Now().After(assignment.PublishDate) {
 			filteredAssignments = append(filteredAssignments, assignment)
 		}
@@ -389,7 +467,7 @@ func (u *assignmentUsecase) List(userId string, workspaceId int) ([]domain.Assig
 }
 
 func (u *assignmentUsecase) ListSubmission(userId string, assignmentId int) ([]domain.Submission, error) {
-	submissions, err := u.assignmentRepository.ListSubmission(&userId, &assignmentId)
+	submissions, err := u.assignmentRepository.ListSubmission(&userId, &assignmentId, nil)
 	if err != nil {
 		return nil, errs.New(errs.ErrListSubmission, "cannot list submission", err)
 	}
@@ -400,18 +478,88 @@ func (u *assignmentUsecase) ListAllSubmission(
 	userId string,
 	workspaceId int,
 	assignmentId int,
+	groupId *int,
 ) ([]domain.Submission, error) {
-	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, workspaceId)
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ViewAllSubmissionPerm)
 	if err != nil {
-		return nil, errs.New(errs.SameCode, "cannot get workspace role while list all submission", err)
+		return nil, errs.New(errs.SameCode, "cannot get workspace permission while list all submission", err)
 	}
 	if !isAuthorized {
 		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
 	}
 
-	submissions, err := u.assignmentRepository.ListSubmission(nil, &assignmentId)
+	// ADMINs managing groups only see the submissions of their groups
+	groupIds, err := u.groupUsecase.GetManagedScope(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get managed group while list all submission", err)
+	}
+	if groupId != nil {
+		if groupIds != nil && !slices.Contains(groupIds, *groupId) {
+			return nil, errs.New(errs.ErrWorkspaceNoPerm, "user id %s does not manage group id %d", userId, *groupId)
+		}
+		groupIds = []int{*groupId}
+	}
+
+	submissions, err := u.assignmentRepository.ListSubmission(nil, &assignmentId, groupIds)
 	if err != nil {
 		return nil, errs.New(errs.ErrListSubmission, "cannot list all submission", err)
 	}
 	return submissions, nil
 }
+
+// CanViewSubmission allows the submitter and the participants seeing all submissions,
+// ADMINs managing groups are filtered the same way as in ListAllSubmission
+func (u *assignmentUsecase) CanViewSubmission(
+	userId string,
+	workspaceId int,
+	assignmentId int,
+	submissionId int,
+) (bool, error) {
+	submission, err := u.GetSubmission(submissionId)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot get submission to check permission", err)
+	} else if submission == nil || submission.AssignmentId != assignmentId {
+		return false, nil
+	} else if submission.SubmitterId == userId {
+		return true, nil
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ViewAllSubmissionPerm)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot get workspace permission while checking submission permission", err)
+	} else if !isAuthorized {
+		return false, nil
+	}
+
+	groupIds, err := u.groupUsecase.GetManagedScope(userId, workspaceId)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot get managed group while checking submission permission", err)
+	} else if groupIds == nil {
+		return true, nil
+	}
+
+	submissions, err := u.assignmentRepository.ListSubmission(&submission.SubmitterId, &assignmentId, groupIds)
+	if err != nil {
+		return false, errs.New(errs.ErrListSubmission, "cannot list submission of managed group", err)
+	}
+	return slices.ContainsFunc(submissions, func(s domain.Submission) bool {
+		return s.Id == submissionId
+	}), nil
+}
+
+// checkTestcaseQuota measures the testcase files and checks them against the storage quota
+// of the workspace owner, the measured size is kept in the files
+func (u *assignmentUsecase) checkTestcaseQuota(
+	workspaceId int,
+	assignmentId int,
+	files []domain.TestcaseFile,
+) (int64, error) {
+	var size int64
+	for i := range files {
+		if err := files[i].Measure(); err != nil {
+			return 0, errs.New(errs.ErrCreateTestcase, "cannot measure testcase file size", err)
+		}
+		size += files[i].Size
+	}
+	return u.quotaUsecase.CheckTestcaseBytes(workspaceId, assignmentId, size)
+}
@@ -1,27 +1,58 @@
 package usecase
 
 import (
+	"strings"
+
 	"github.com/codern-org/codern/domain"
 	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/config"
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type authUsecase struct {
-	googleUsecase  domain.GoogleUsecase
-	sessionUsecase domain.SessionUsecase
-	userUsecase    domain.UserUsecase
+	cfg                      *config.Config
+	logger                   *zap.Logger
+	googleUsecase            domain.GoogleUsecase
+	sessionUsecase           domain.SessionUsecase
+	userUsecase              domain.UserUsecase
+	emailVerificationUsecase domain.EmailVerificationUsecase
+	twoFactorUsecase         domain.TwoFactorUsecase
+	apiTokenUsecase          domain.ApiTokenUsecase
+	oidcUsecase              domain.OidcUsecase
+	identityUsecase          domain.IdentityUsecase
+	signInThrottleUsecase    domain.SignInThrottleUsecase
+	rosterUsecase            domain.RosterUsecase
 }
 
 func NewAuthUsecase(
+	cfg *config.Config,
+	logger *zap.Logger,
 	googleUsecase domain.GoogleUsecase,
 	sessionUsecase domain.SessionUsecase,
 	userUsecase domain.UserUsecase,
+	emailVerificationUsecase domain.EmailVerificationUsecase,
+	twoFactorUsecase domain.TwoFactorUsecase,
+	apiTokenUsecase domain.ApiTokenUsecase,
+	oidcUsecase domain.OidcUsecase,
+	identityUsecase domain.IdentityUsecase,
+	signInThrottleUsecase domain.SignInThrottleUsecase,
+	rosterUsecase domain.RosterUsecase,
 ) domain.AuthUsecase {
 	return &authUsecase{
-		googleUsecase:  googleUsecase,
-		sessionUsecase: sessionUsecase,
-		userUsecase:    userUsecase,
+		cfg:                      cfg,
+		logger:                   logger,
+		googleUsecase:            googleUsecase,
+		sessionUsecase:           sessionUsecase,
+		userUsecase:              userUsecase,
+		emailVerificationUsecase: emailVerificationUsecase,
+		twoFactorUsecase:         twoFactorUsecase,
+		apiTokenUsecase:          apiTokenUsecase,
+		oidcUsecase:              oidcUsecase,
+		identityUsecase:          identityUsecase,
+		signInThrottleUsecase:    signInThrottleUsecase,
+		rosterUsecase:            rosterUsecase,
 	}
 }
 
@@ -34,31 +65,169 @@ func (u *authUsecase) Authenticate(header string) (*domain.User, error) {
 	user, err := u.userUsecase.GetBySessionId(session.Id)
 	if err != nil {
 		return nil, errs.New(errs.SameCode, "cannot get user to authenticate", err)
+	} else if user != nil && user.IsDisabled {
+		return nil, errs.New(errs.ErrUserDisabled, "account of user id %s is disabled", user.Id)
 	}
 	return user, nil
 }
 
+func (u *authUsecase) AuthenticateApiToken(token string) (*domain.User, *domain.ApiToken, error) {
+	apiToken, err := u.apiTokenUsecase.Validate(token)
+	if err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot authenticate api token", err)
+	}
+
+	user, err := u.userUsecase.Get(apiToken.UserId)
+	if err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot get user to authenticate api token", err)
+	} else if user == nil {
+		return nil, nil, errs.New(errs.ErrApiTokenInvalid, "owner of api token id %d not found", apiToken.Id)
+	} else if user.IsDisabled {
+		return nil, nil, errs.New(errs.ErrUserDisabled, "account of user id %s is disabled", user.Id)
+	}
+	return user, apiToken, nil
+}
+
 func (u *authUsecase) SignIn(
 	email string, password string, ipAddress string, userAgent string,
-) (*fiber.Cookie, error) {
+) (*fiber.Cookie, *domain.TwoFactorChallenge, error) {
+	if err := u.signInThrottleUsecase.Check(email, ipAddress, userAgent); err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot sign in", err)
+	}
+
 	user, err := u.userUsecase.GetByEmail(email, domain.SelfAuth)
 	if err != nil {
-		return nil, errs.New(errs.SameCode, "cannot get user data to sign in", err)
+		return nil, nil, errs.New(errs.SameCode, "cannot get user data to sign in", err)
 	} else if user == nil {
-		return nil, errs.New(errs.ErrUserNotFound, "account with email %s is not registered", email)
+		if err := u.signInThrottleUsecase.RecordFailure(email, ipAddress, userAgent, domain.UserNotFoundFailure); err != nil {
+			return nil, nil, errs.New(errs.SameCode, "cannot record failed sign in", err)
+		}
+		return nil, nil, errs.New(errs.ErrUserNotFound, "account with email %s is not registered", email)
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errs.New(errs.ErrUserPassword, "password is incorrect", err)
+		if err := u.signInThrottleUsecase.RecordFailure(email, ipAddress, userAgent, domain.WrongPasswordFailure); err != nil {
+			return nil, nil, errs.New(errs.SameCode, "cannot record failed sign in", err)
+		}
+		return nil, nil, errs.New(errs.ErrUserPassword, "password is incorrect", err)
+	}
+
+	if user.IsDisabled {
+		return nil, nil, errs.New(errs.ErrUserDisabled, "account with email %s is disabled", email)
+	}
+
+	// Session is created and the sign in recorded as a success only after
+	// the challenge is passed in SignInWithTwoFactor
+	if user.IsTwoFactorEnabled {
+		challenge, err := u.twoFactorUsecase.CreateChallenge(user.Id)
+		if err != nil {
+			return nil, nil, errs.New(errs.SameCode, "cannot create two-factor challenge to sign in", err)
+		}
+		return nil, challenge, nil
+	}
+
+	if err := u.signInThrottleUsecase.RecordSuccess(email, ipAddress, userAgent); err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot record sign in", err)
+	}
+
+	cookie, err := u.sessionUsecase.Create(user.Id, ipAddress, userAgent)
+	if err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot create session to sign in", err)
+	}
+	return cookie, nil, nil
+}
+
+func (u *authUsecase) SignInWithTwoFactor(
+	token string, code string, ipAddress string, userAgent string,
+) (*fiber.Cookie, error) {
+	challenge, err := u.twoFactorUsecase.GetChallenge(token)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get two-factor challenge to sign in", err)
+	}
+
+	user, err := u.userUsecase.Get(challenge.UserId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get user data to sign in with two-factor", err)
+	} else if user == nil {
+		return nil, errs.New(errs.ErrTwoFactorChallenge, "two-factor challenge is invalid")
+	}
+
+	// Wrong codes count as failed sign in of the email, so the password
+	// holder is locked out the same way as someone guessing the password
+	if err := u.signInThrottleUsecase.Check(user.Email, ipAddress, userAgent); err != nil {
+		return nil, errs.New(errs.SameCode, "cannot sign in with two-factor", err)
+	}
+
+	userId, err := u.twoFactorUsecase.VerifyChallenge(token, code)
+	if errs.HasCode(err, errs.ErrTwoFactorCode) {
+		if err := u.signInThrottleUsecase.RecordFailure(user.Email, ipAddress, userAgent, domain.WrongTwoFactorFailure); err != nil {
+			return nil, errs.New(errs.SameCode, "cannot record failed sign in with two-factor", err)
+		}
+		return nil, errs.New(errs.SameCode, "cannot verify two-factor to sign in", err)
+	} else if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot verify two-factor to sign in", err)
+	}
+
+	// The account may have been disabled while the challenge was pending
+	if user.IsDisabled {
+		return nil, errs.New(errs.ErrUserDisabled, "account with email %s is disabled", user.Email)
+	}
+
+	if err := u.signInThrottleUsecase.RecordSuccess(user.Email, ipAddress, userAgent); err != nil {
+		return nil, errs.New(errs.SameCode, "cannot record sign in with two-factor", err)
+	}
+
+	cookie, err := u.sessionUsecase.Create(userId, ipAddress, userAgent)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create session to sign in with two-factor", err)
+	}
+	return cookie, nil
+}
+
+func (u *authUsecase) SignUp(
+	email string, password string, ipAddress string, userAgent string,
+) (*fiber.Cookie, error) {
+	if !u.isEmailDomainAllowed(email) {
+		return nil, errs.New(errs.ErrEmailDomain, "email domain of %s is not allowed to sign up", email)
+	}
+
+	user, err := u.userUsecase.Create(email, password)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create user to sign up", err)
 	}
 
+	// The user can request a new verification email if this one fails
+	go func() {
+		if err := u.emailVerificationUsecase.Send(user.Id); err != nil {
+			u.logger.Error("Cannot send verification email", zap.String("user_id", user.Id), zap.Error(err))
+		}
+	}()
+
 	cookie, err := u.sessionUsecase.Create(user.Id, ipAddress, userAgent)
 	if err != nil {
-		return nil, errs.New(errs.SameCode, "cannot create session to sign in", err)
+		return nil, errs.New(errs.SameCode, "cannot create session to sign up", err)
 	}
 	return cookie, nil
 }
 
+func (u *authUsecase) isEmailDomainAllowed(email string) bool {
+	allowedDomains := u.cfg.Auth.SignUp.AllowedEmailDomains
+	if len(allowedDomains) == 0 {
+		return true
+	}
+
+	_, emailDomain, found := strings.Cut(email, "@")
+	if !found {
+		return false
+	}
+	for _, allowedDomain := range allowedDomains {
+		if strings.EqualFold(emailDomain, allowedDomain) {
+			return true
+		}
+	}
+	return false
+}
+
 func (u *authUsecase) SignInWithGoogle(
 	code string, ipAddress string, userAgent string,
 ) (*fiber.Cookie, error) {
@@ -71,16 +240,31 @@ func (u *authUsecase) SignInWithGoogle(
 		return nil, errs.New(errs.SameCode, "cannot sign in with google", err)
 	}
 
-	user, err := u.userUsecase.GetByEmail(googleUser.Email, domain.GoogleAuth)
+	user, err := u.identityUsecase.GetUser(domain.GoogleAuth, googleUser.Id, googleUser.Email)
 	if err != nil {
 		return nil, errs.New(errs.SameCode, "cannot get user data to sign in with google", err)
 	}
 
 	if user == nil {
-		user, err = u.userUsecase.CreateFromGoogle(googleUser.Id, googleUser.Email, googleUser.Name)
+		user, err = u.userUsecase.CreateFromGoogle(
+			googleUser.Id,
+			googleUser.Email,
+			googleUser.Name,
+			googleUser.IsEmailVerified,
+		)
 		if err != nil {
 			return nil, errs.New(errs.SameCode, "cannot create user to sign in with google", err)
 		}
+		if err := u.identityUsecase.Link(
+			user.Id, domain.GoogleAuth, googleUser.Id, googleUser.Email, googleUser.IsEmailVerified,
+		); err != nil {
+			return nil, errs.New(errs.SameCode, "cannot create identity to sign in with google", err)
+		}
+		go u.claimInvitation(user.Id)
+	}
+
+	if user.IsDisabled {
+		return nil, errs.New(errs.ErrUserDisabled, "account with email %s is disabled", user.Email)
 	}
 
 	cookie, err := u.sessionUsecase.Create(user.Id, ipAddress, userAgent)
@@ -90,6 +274,48 @@ func (u *authUsecase) SignInWithGoogle(
 	return cookie, nil
 }
 
+func (u *authUsecase) SignInWithOidc(
+	provider domain.AuthProvider, code string, state string, ipAddress string, userAgent string,
+) (*fiber.Cookie, error) {
+	oidcUser, err := u.oidcUsecase.GetUser(provider, code, state)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot sign in with oidc provider %s", provider, err)
+	}
+
+	user, err := u.identityUsecase.GetUser(provider, oidcUser.Id, oidcUser.Email)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get user data to sign in with oidc provider %s", provider, err)
+	}
+
+	if user == nil {
+		user, err = u.userUsecase.CreateFromOidc(
+			provider,
+			oidcUser.Email,
+			oidcUser.Name,
+			oidcUser.IsEmailVerified,
+		)
+		if err != nil {
+			return nil, errs.New(errs.SameCode, "cannot create user to sign in with oidc provider %s", provider, err)
+		}
+		if err := u.identityUsecase.Link(
+			user.Id, provider, oidcUser.Id, oidcUser.Email, oidcUser.IsEmailVerified,
+		); err != nil {
+			return nil, errs.New(errs.SameCode, "cannot create identity to sign in with oidc provider %s", provider, err)
+		}
+		go u.claimInvitation(user.Id)
+	}
+
+	if user.IsDisabled {
+		return nil, errs.New(errs.ErrUserDisabled, "account with email %s is disabled", user.Email)
+	}
+
+	cookie, err := u.sessionUsecase.Create(user.Id, ipAddress, userAgent)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create session to sign in with oidc provider %s", provider, err)
+	}
+	return cookie, nil
+}
+
 func (u *authUsecase) SignOut(header string) (*fiber.Cookie, error) {
 	session, err := u.sessionUsecase.Validate(header)
 	if err != nil {
@@ -102,3 +328,10 @@ func (u *authUsecase) SignOut(header string) (*fiber.Cookie, error) {
 	}
 	return cookie, nil
 }
+
+// claimInvitation runs in the background so a failed claim does not fail the sign in
+func (u *authUsecase) claimInvitation(userId string) {
+	if err := u.rosterUsecase.Claim(userId); err != nil {
+		u.logger.Error("Cannot claim pending invitation", zap.String("user_id", userId), zap.Error(err))
+	}
+}
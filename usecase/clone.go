@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/platform"
+)
+
+type cloneUsecase struct {
+	seaweedfs            *platform.SeaweedFs
+	trashRepository      domain.TrashRepository
+	assignmentRepository domain.AssignmentRepository
+	customRoleRepository domain.CustomRoleRepository
+	workspaceUsecase     domain.WorkspaceUsecase
+	assignmentUsecase    domain.AssignmentUsecase
+}
+
+func NewCloneUsecase(
+	seaweedfs *platform.SeaweedFs,
+	trashRepository domain.TrashRepository,
+	assignmentRepository domain.AssignmentRepository,
+	customRoleRepository domain.CustomRoleRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+	assignmentUsecase domain.AssignmentUsecase,
+) domain.CloneUsecase {
+	return &cloneUsecase{
+		seaweedfs:            seaweedfs,
+		trashRepository:      trashRepository,
+		assignmentRepository: assignmentRepository,
+		customRoleRepository: customRoleRepository,
+		workspaceUsecase:     workspaceUsecase,
+		assignmentUsecase:    assignmentUsecase,
+	}
+}
+
+// Clone creates a workspace owned by the user with the profile, settings and assignments
+// of the source workspace, submissions, groups and invitations are not copied
+func (u *cloneUsecase) Clone(
+	userId string,
+	workspaceId int,
+	cw *domain.CloneWorkspace,
+) (*domain.RawWorkspace, error) {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageWorkspacePerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check permission of user id %s to clone workspace", userId, err)
+	} else if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot clone workspace id %d", userId, workspaceId)
+	}
+
+	source, err := u.workspaceUsecase.GetRaw(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace id %d to clone", workspaceId, err)
+	} else if source == nil || source.IsDeleted {
+		return nil, errs.New(errs.ErrWorkspaceNotFound, "workspace id %d not found", workspaceId)
+	}
+
+	name := cw.Name
+	if name == "" {
+		name = source.Name
+	}
+
+	// The profile is generated again when the source one cannot be read
+	var profile io.Reader
+	if content, err := u.download(source.ProfileUrl); err == nil {
+		profile = content
+	}
+
+	workspace, err := u.workspaceUsecase.Create(userId, &domain.CreateWorkspace{
+		Name:             name,
+		Profile:          profile,
+		IsOpenScoreboard: source.IsOpenScoreboard,
+		IsJoinApproval:   source.IsJoinApproval,
+		IsDiscoverable:   source.IsDiscoverable,
+	})
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create workspace to clone workspace id %d", workspaceId, err)
+	}
+
+	if err := u.copy(userId, source.Id, workspace.Id, cw); err != nil {
+		err = discardWorkspace(u.seaweedfs, u.trashRepository, workspace.Id, err)
+		return nil, errs.New(errs.SameCode, "cannot clone workspace id %d", workspaceId, err)
+	}
+	return workspace, nil
+}
+
+func (u *cloneUsecase) copy(userId string, sourceId int, targetId int, cw *domain.CloneWorkspace) error {
+	assignments, err := u.assignmentRepository.ListWithDeleted(sourceId)
+	if err != nil {
+		return errs.New(errs.ErrListAssignment, "cannot list assignment of workspace id %d", sourceId, err)
+	}
+	for _, assignment := range assignments {
+		if assignment.IsDeleted {
+			continue
+		}
+		if err := u.copyAssignment(userId, targetId, assignment.Id, cw); err != nil {
+			return err
+		}
+	}
+
+	if !cw.IncludeAdmins {
+		return nil
+	}
+
+	roles, err := u.customRoleRepository.List(sourceId)
+	if err != nil {
+		return errs.New(errs.ErrCustomRole, "cannot list custom role of workspace id %d", sourceId, err)
+	}
+	roleIds := make(map[int]int)
+	for _, role := range roles {
+		roleIds[role.Id] = generator.GetId()
+		role.Id = roleIds[role.Id]
+		role.WorkspaceId = targetId
+		if err := u.customRoleRepository.Create(&role); err != nil {
+			return errs.New(errs.ErrCustomRole, "cannot copy custom role to workspace id %d", targetId, err)
+		}
+	}
+
+	participants, err := u.workspaceUsecase.ListParticipant(sourceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot list participant of workspace id %d", sourceId, err)
+	}
+	for _, participant := range participants {
+		if participant.Role != domain.AdminRole || participant.UserId == userId {
+			continue
+		}
+		err := u.workspaceUsecase.CreateParticipant(targetId, participant.UserId, domain.AdminRole)
+		if err != nil {
+			return errs.New(errs.SameCode, "cannot copy admin id %s to workspace id %d", participant.UserId, targetId, err)
+		}
+		if participant.CustomRoleId == nil {
+			continue
+		}
+		roleId := roleIds[*participant.CustomRoleId]
+		if err := u.customRoleRepository.SetParticipant(targetId, participant.UserId, &roleId); err != nil {
+			return errs.New(errs.ErrCustomRole, "cannot copy custom role of admin id %s", participant.UserId, err)
+		}
+	}
+	return nil
+}
+
+// copyAssignment creates the assignment again with its detail file and the latest revision of its testcases
+func (u *cloneUsecase) copyAssignment(userId string, workspaceId int, assignmentId int, cw *domain.CloneWorkspace) error {
+	assignment, err := u.assignmentRepository.Get(assignmentId)
+	if err != nil {
+		return errs.New(errs.ErrGetAssignment, "cannot get assignment id %d to clone", assignmentId, err)
+	} else if assignment == nil {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	detail, err := u.download(assignment.DetailUrl)
+	if err != nil {
+		return errs.New(errs.ErrCloneWorkspace, "cannot download detail of assignment id %d", assignmentId, err)
+	}
+	mimeType := "text/plain"
+	if strings.HasSuffix(assignment.DetailUrl, ".pdf") {
+		mimeType = "application/pdf"
+	}
+
+	testcaseFiles := make([]domain.TestcaseFile, len(assignment.Testcases))
+	for i, testcase := range assignment.Testcases {
+		input, err := u.download(testcase.InputFileUrl)
+		if err != nil {
+			return errs.New(errs.ErrCloneWorkspace, "cannot download testcase id %d input", testcase.Id, err)
+		}
+		output, err := u.download(testcase.OutputFileUrl)
+		if err != nil {
+			return errs.New(errs.ErrCloneWorkspace, "cannot download testcase id %d output", testcase.Id, err)
+		}
+		testcaseFiles[i] = domain.TestcaseFile{Input: input, Output: output}
+	}
+
+	var dueDate *time.Time
+	if assignment.DueDate != nil {
+		shifted := assignment.DueDate.Add(cw.DateOffset)
+		dueDate = &shifted
+	}
+
+	err = u.assignmentUsecase.Create(userId, workspaceId, &domain.CreateAssignment{
+		Name:              assignment.Name,
+		Description:       assignment.Description,
+		MemoryLimit:       assignment.MemoryLimit,
+		TimeLimit:         assignment.TimeLimit,
+		Level:             assignment.Level,
+		PublishDate:       assignment.PublishDate.Add(cw.DateOffset),
+		DueDate:           dueDate,
+		DetailFile:        &domain.File{Reader: detail, MimeType: mimeType},
+		TestcaseFiles:     testcaseFiles,
+		IsAutoTrimEnabled: assignment.IsAutoTrimEnabled,
+	})
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot copy assignment id %d", assignmentId, err)
+	}
+	return nil
+}
+
+func (u *cloneUsecase) download(path string) (*bytes.Reader, error) {
+	var content []byte
+	err := u.seaweedfs.Download(path, func(r io.Reader) error {
+		var err error
+		content, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(content), nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/generator"
+)
+
+type customRoleUsecase struct {
+	customRoleRepository domain.CustomRoleRepository
+	workspaceUsecase     domain.WorkspaceUsecase
+}
+
+func NewCustomRoleUsecase(
+	customRoleRepository domain.CustomRoleRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+) domain.CustomRoleUsecase {
+	return &customRoleUsecase{
+		customRoleRepository: customRoleRepository,
+		workspaceUsecase:     workspaceUsecase,
+	}
+}
+
+func (u *customRoleUsecase) Create(
+	userId string,
+	workspaceId int,
+	name string,
+	permissions domain.WorkspacePermissions,
+) (*domain.WorkspaceCustomRole, error) {
+	if err := u.checkOwner(userId, workspaceId); err != nil {
+		return nil, err
+	}
+	if err := validatePermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	role := &domain.WorkspaceCustomRole{
+		Id:          generator.GetId(),
+		WorkspaceId: workspaceId,
+		Name:        name,
+		Permissions: permissions,
+		CreatedAt:   time.Now(),
+	}
+	if err := u.customRoleRepository.Create(role); err != nil {
+		return nil, errs.New(errs.ErrCustomRole, "cannot create custom role in workspace id %d", workspaceId, err)
+	}
+	return role, nil
+}
+
+func (u *customRoleUsecase) List(userId string, workspaceId int) ([]domain.WorkspaceCustomRole, error) {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageParticipantPerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check permission of user id %s to list custom role", userId, err)
+	} else if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot list custom role of workspace id %d", userId, workspaceId)
+	}
+
+	roles, err := u.customRoleRepository.List(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrCustomRole, "cannot list custom role of workspace id %d", workspaceId, err)
+	}
+	return roles, nil
+}
+
+func (u *customRoleUsecase) Update(
+	userId string,
+	workspaceId int,
+	id int,
+	name string,
+	permissions domain.WorkspacePermissions,
+) error {
+	if err := u.checkOwner(userId, workspaceId); err != nil {
+		return err
+	}
+	if err := validatePermissions(permissions); err != nil {
+		return err
+	}
+	role, err := u.get(workspaceId, id)
+	if err != nil {
+		return err
+	}
+
+	role.Name = name
+	role.Permissions = permissions
+	if err := u.customRoleRepository.Update(role); err != nil {
+		return errs.New(errs.ErrCustomRole, "cannot update custom role id %d", id, err)
+	}
+	return nil
+}
+
+// Delete refuses roles still given to a participant, dropping them silently
+// would give every permission back to those ADMINs
+func (u *customRoleUsecase) Delete(userId string, workspaceId int, id int) error {
+	if err := u.checkOwner(userId, workspaceId); err != nil {
+		return err
+	}
+	if _, err := u.get(workspaceId, id); err != nil {
+		return err
+	}
+
+	count, err := u.customRoleRepository.CountParticipant(id)
+	if err != nil {
+		return errs.New(errs.ErrCustomRole, "cannot count participant of custom role id %d", id, err)
+	} else if count > 0 {
+		return errs.New(errs.ErrCustomRoleInUse, "custom role id %d is given to %d participants", id, count)
+	}
+
+	if err := u.customRoleRepository.Delete(id); err != nil {
+		return errs.New(errs.ErrCustomRole, "cannot delete custom role id %d", id, err)
+	}
+	return nil
+}
+
+// Assign gives a custom role to an ADMIN of the workspace, a nil id gives every permission back
+func (u *customRoleUsecase) Assign(userId string, workspaceId int, participantId string, id *int) error {
+	if err := u.checkOwner(userId, workspaceId); err != nil {
+		return err
+	}
+
+	role, err := u.workspaceUsecase.GetRole(participantId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get role of user id %s to assign custom role", participantId, err)
+	} else if role == nil || *role != domain.AdminRole {
+		return errs.New(errs.ErrCustomRoleTarget, "custom role can only be given to an admin")
+	}
+
+	if id != nil {
+		if _, err := u.get(workspaceId, *id); err != nil {
+			return err
+		}
+	}
+
+	if err := u.customRoleRepository.SetParticipant(workspaceId, participantId, id); err != nil {
+		return errs.New(errs.ErrCustomRole, "cannot assign custom role to user id %s", participantId, err)
+	}
+	return nil
+}
+
+func (u *customRoleUsecase) get(workspaceId int, id int) (*domain.WorkspaceCustomRole, error) {
+	role, err := u.customRoleRepository.Get(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrCustomRole, "cannot get custom role id %d", id, err)
+	} else if role == nil || role.WorkspaceId != workspaceId {
+		return nil, errs.New(errs.ErrCustomRoleNotFound, "custom role id %d not found", id)
+	}
+	return role, nil
+}
+
+func (u *customRoleUsecase) checkOwner(userId string, workspaceId int) error {
+	isAuthorized, err := u.workspaceUsecase.CheckPermRole(userId, workspaceId, []domain.WorkspaceRole{domain.OwnerRole})
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to manage custom role", userId, err)
+	} else if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot manage custom role of workspace id %d", userId, workspaceId)
+	}
+	return nil
+}
+
+func validatePermissions(permissions domain.WorkspacePermissions) error {
+	seen := make(map[domain.WorkspacePermission]bool)
+	for _, permission := range permissions {
+		if !domain.WorkspacePermissionMap[permission] {
+			return errs.New(errs.ErrInvalidPermission, "invalid permission %s", permission)
+		} else if seen[permission] {
+			return errs.New(errs.ErrInvalidPermission, "duplicated permission %s", permission)
+		}
+		seen[permission] = true
+	}
+	return nil
+}
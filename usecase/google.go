@@ -13,26 +13,64 @@ import (
 	"github.com/codern-org/codern/domain"
 	errs "github.com/codern-org/codern/domain/error"
 	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
 )
 
 type googleUsecase struct {
-	cfg        *config.Config
-	httpClient *http.Client
+	cfg            *config.Config
+	oidcRepository domain.OidcRepository
+	httpClient     *http.Client
 }
 
-func NewGoogleUsecase(cfg *config.Config) domain.GoogleUsecase {
+func NewGoogleUsecase(cfg *config.Config, oidcRepository domain.OidcRepository) domain.GoogleUsecase {
 	return &googleUsecase{
-		cfg:        cfg,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cfg:            cfg,
+		oidcRepository: oidcRepository,
+		httpClient:     &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
-func (u *googleUsecase) GetOAuthUrl() string {
+// GetAuthRequest issues a state the same way as the oidc providers,
+// which has to come back with the code when linking the account
+func (u *googleUsecase) GetAuthRequest() (*domain.OidcAuthRequest, error) {
+	state, err := generator.RandToken(32)
+	if err != nil {
+		return nil, errs.New(errs.ErrGoogleAuth, "cannot generate google auth state", err)
+	}
+
+	createdAt := time.Now()
+	expiredAt := createdAt.Add(constant.OidcStateMaxAge)
+	if err := u.oidcRepository.CreateState(&domain.OidcState{
+		Id:        generator.HashToken(state),
+		Provider:  domain.GoogleAuth,
+		CreatedAt: createdAt,
+		ExpiredAt: expiredAt,
+	}); err != nil {
+		return nil, errs.New(errs.ErrGoogleAuth, "cannot create google auth state", err)
+	}
+
+	return &domain.OidcAuthRequest{
+		Url:       u.getOAuthUrl(state),
+		State:     state,
+		ExpiredAt: expiredAt,
+	}, nil
+}
+
+func (u *googleUsecase) VerifyState(state string) error {
+	if _, err := useOidcState(u.oidcRepository, domain.GoogleAuth, state); err != nil {
+		return errs.New(errs.SameCode, "cannot verify google auth state", err)
+	}
+	return nil
+}
+
+func (u *googleUsecase) getOAuthUrl(state string) string {
 	query := url.Values{}
 	query.Add("client_id", u.cfg.Google.ClientId)
 	query.Add("redirect_uri", u.cfg.Google.RedirectUri)
 	query.Add("response_type", "code")
 	query.Add("prompt", "consent")
+	query.Add("state", state)
 	query.Add("scope", strings.Join([]string{
 		"openid",
 		"email",
@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"slices"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/generator"
+)
+
+type groupUsecase struct {
+	groupRepository  domain.GroupRepository
+	workspaceUsecase domain.WorkspaceUsecase
+}
+
+func NewGroupUsecase(
+	groupRepository domain.GroupRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+) domain.GroupUsecase {
+	return &groupUsecase{
+		groupRepository:  groupRepository,
+		workspaceUsecase: workspaceUsecase,
+	}
+}
+
+func (u *groupUsecase) Create(userId string, workspaceId int, name string) (*domain.WorkspaceGroup, error) {
+	if err := u.checkWorkspacePerm(userId, workspaceId); err != nil {
+		return nil, err
+	}
+
+	group := &domain.WorkspaceGroup{
+		Id:          generator.GetId(),
+		WorkspaceId: workspaceId,
+		Name:        name,
+		CreatedAt:   time.Now(),
+	}
+	if err := u.groupRepository.Create(group); err != nil {
+		return nil, errs.New(errs.ErrCreateGroup, "cannot create group in workspace id %d", workspaceId, err)
+	}
+	return group, nil
+}
+
+func (u *groupUsecase) List(userId string, workspaceId int) ([]domain.WorkspaceGroup, error) {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageParticipantPerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check permission of user id %s to list group", userId, err)
+	} else if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot list group of workspace id %d", userId, workspaceId)
+	}
+
+	groups, err := u.groupRepository.List(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetGroup, "cannot list group of workspace id %d", workspaceId, err)
+	}
+
+	scope, err := u.GetManagedScope(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot list group of workspace id %d", workspaceId, err)
+	} else if scope == nil {
+		return groups, nil
+	}
+
+	managedGroups := make([]domain.WorkspaceGroup, 0, len(scope))
+	for _, group := range groups {
+		if slices.Contains(scope, group.Id) {
+			managedGroups = append(managedGroups, group)
+		}
+	}
+	return managedGroups, nil
+}
+
+func (u *groupUsecase) Update(userId string, workspaceId int, groupId int, name string) error {
+	if err := u.checkWorkspacePerm(userId, workspaceId); err != nil {
+		return err
+	}
+	group, err := u.getGroup(workspaceId, groupId)
+	if err != nil {
+		return err
+	}
+
+	group.Name = name
+	if err := u.groupRepository.Update(group); err != nil {
+		return errs.New(errs.ErrUpdateGroup, "cannot update group id %d", groupId, err)
+	}
+	return nil
+}
+
+func (u *groupUsecase) Delete(userId string, workspaceId int, groupId int) error {
+	if err := u.checkWorkspacePerm(userId, workspaceId); err != nil {
+		return err
+	}
+	if _, err := u.getGroup(workspaceId, groupId); err != nil {
+		return err
+	}
+
+	if err := u.groupRepository.Delete(groupId); err != nil {
+		return errs.New(errs.ErrDeleteGroup, "cannot delete group id %d", groupId, err)
+	}
+	return nil
+}
+
+func (u *groupUsecase) ListMember(userId string, workspaceId int, groupId int) ([]domain.GroupMember, error) {
+	if err := u.checkGroupPerm(userId, workspaceId, groupId, domain.ManageParticipantPerm); err != nil {
+		return nil, err
+	}
+
+	members, err := u.groupRepository.ListMember(groupId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGroupMember, "cannot list member of group id %d", groupId, err)
+	}
+	return members, nil
+}
+
+// AddMember puts a MEMBER of the workspace in the group, a member already in
+// another group of the workspace is moved
+func (u *groupUsecase) AddMember(userId string, workspaceId int, groupId int, memberId string) error {
+	if err := u.checkGroupPerm(userId, workspaceId, groupId, domain.ManageParticipantPerm); err != nil {
+		return err
+	}
+	if err := u.checkRole(memberId, workspaceId, domain.MemberRole); err != nil {
+		return err
+	}
+
+	if err := u.groupRepository.SetMember(workspaceId, groupId, memberId); err != nil {
+		return errs.New(errs.ErrGroupMember, "cannot add user id %s to group id %d", memberId, groupId, err)
+	}
+	return nil
+}
+
+func (u *groupUsecase) RemoveMember(userId string, workspaceId int, groupId int, memberId string) error {
+	if err := u.checkGroupPerm(userId, workspaceId, groupId, domain.ManageParticipantPerm); err != nil {
+		return err
+	}
+
+	if err := u.groupRepository.DeleteMember(groupId, memberId); err != nil {
+		return errs.New(errs.ErrGroupMember, "cannot remove user id %s from group id %d", memberId, groupId, err)
+	}
+	return nil
+}
+
+// AddManager restricts an ADMIN to the groups they manage, an ADMIN without
+// any group keeps access to the whole workspace
+func (u *groupUsecase) AddManager(userId string, workspaceId int, groupId int, managerId string) error {
+	if err := u.checkWorkspacePerm(userId, workspaceId); err != nil {
+		return err
+	}
+	if _, err := u.getGroup(workspaceId, groupId); err != nil {
+		return err
+	}
+	if err := u.checkRole(managerId, workspaceId, domain.AdminRole); err != nil {
+		return err
+	}
+
+	if err := u.groupRepository.CreateManager(groupId, managerId); err != nil {
+		return errs.New(errs.ErrGroupMember, "cannot add manager id %s to group id %d", managerId, groupId, err)
+	}
+	return nil
+}
+
+func (u *groupUsecase) RemoveManager(userId string, workspaceId int, groupId int, managerId string) error {
+	if err := u.checkWorkspacePerm(userId, workspaceId); err != nil {
+		return err
+	}
+	if _, err := u.getGroup(workspaceId, groupId); err != nil {
+		return err
+	}
+
+	if err := u.groupRepository.DeleteManager(groupId, managerId); err != nil {
+		return errs.New(errs.ErrGroupMember, "cannot remove manager id %s from group id %d", managerId, groupId, err)
+	}
+	return nil
+}
+
+func (u *groupUsecase) SetSchedule(userId string, workspaceId int, schedule *domain.GroupSchedule) error {
+	if err := u.checkGroupPerm(userId, workspaceId, schedule.GroupId, domain.ManageAssignmentPerm); err != nil {
+		return err
+	}
+
+	hasAssignment, err := u.workspaceUsecase.HasAssignment(schedule.AssignmentId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check assignment id %d of group schedule", schedule.AssignmentId, err)
+	} else if !hasAssignment {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", schedule.AssignmentId)
+	}
+
+	if schedule.PublishDate != nil && schedule.DueDate != nil && schedule.DueDate.Before(*schedule.PublishDate) {
+		return errs.New(errs.ErrGroupInvalidDate, "due date must be after publish date")
+	}
+
+	if err := u.groupRepository.SetSchedule(schedule); err != nil {
+		return errs.New(errs.ErrGroupSchedule, "cannot set schedule of group id %d", schedule.GroupId, err)
+	}
+	return nil
+}
+
+func (u *groupUsecase) DeleteSchedule(userId string, workspaceId int, groupId int, assignmentId int) error {
+	if err := u.checkGroupPerm(userId, workspaceId, groupId, domain.ManageAssignmentPerm); err != nil {
+		return err
+	}
+
+	if err := u.groupRepository.DeleteSchedule(groupId, assignmentId); err != nil {
+		return errs.New(errs.ErrGroupSchedule, "cannot delete schedule of group id %d", groupId, err)
+	}
+	return nil
+}
+
+func (u *groupUsecase) ListSchedule(userId string, workspaceId int, groupId int) ([]domain.GroupSchedule, error) {
+	if err := u.checkGroupPerm(userId, workspaceId, groupId, domain.ManageAssignmentPerm); err != nil {
+		return nil, err
+	}
+
+	schedules, err := u.groupRepository.ListSchedule(groupId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGroupSchedule, "cannot list schedule of group id %d", groupId, err)
+	}
+	return schedules, nil
+}
+
+// GetScheduleByUser returns the schedules of the group of the user by assignment id
+func (u *groupUsecase) GetScheduleByUser(userId string, workspaceId int) (map[int]domain.GroupSchedule, error) {
+	schedules, err := u.groupRepository.ListScheduleByUser(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGroupSchedule, "cannot list schedule of user id %s", userId, err)
+	}
+
+	scheduleByAssignment := make(map[int]domain.GroupSchedule, len(schedules))
+	for _, schedule := range schedules {
+		scheduleByAssignment[schedule.AssignmentId] = schedule
+	}
+	return scheduleByAssignment, nil
+}
+
+// GetManagedScope returns the ids of the groups an ADMIN is restricted to,
+// nil means the user is not restricted to any group
+func (u *groupUsecase) GetManagedScope(userId string, workspaceId int) ([]int, error) {
+	role, err := u.workspaceUsecase.GetRole(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get role of user id %s", userId, err)
+	} else if role == nil || *role != domain.AdminRole {
+		return nil, nil
+	}
+
+	groupIds, err := u.groupRepository.ListManagedGroupId(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetGroup, "cannot list managed group of user id %s", userId, err)
+	} else if len(groupIds) == 0 {
+		return nil, nil
+	}
+	return groupIds, nil
+}
+
+func (u *groupUsecase) getGroup(workspaceId int, groupId int) (*domain.WorkspaceGroup, error) {
+	group, err := u.groupRepository.Get(groupId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetGroup, "cannot get group id %d", groupId, err)
+	} else if group == nil || group.WorkspaceId != workspaceId {
+		return nil, errs.New(errs.ErrGroupNotFound, "group id %d not found", groupId)
+	}
+	return group, nil
+}
+
+// checkWorkspacePerm allows participants managing participants who are not restricted to groups
+func (u *groupUsecase) checkWorkspacePerm(userId string, workspaceId int) error {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageParticipantPerm)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to manage group", userId, err)
+	} else if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot manage group of workspace id %d", userId, workspaceId)
+	}
+
+	scope, err := u.GetManagedScope(userId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to manage group", userId, err)
+	} else if scope != nil {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s is restricted to the groups they manage", userId)
+	}
+	return nil
+}
+
+// checkGroupPerm also allows the ADMINs who manage the group
+func (u *groupUsecase) checkGroupPerm(
+	userId string,
+	workspaceId int,
+	groupId int,
+	permission domain.WorkspacePermission,
+) error {
+	if _, err := u.getGroup(workspaceId, groupId); err != nil {
+		return err
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, permission)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to manage group", userId, err)
+	} else if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot manage group id %d", userId, groupId)
+	}
+
+	scope, err := u.GetManagedScope(userId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to manage group", userId, err)
+	} else if scope != nil && !slices.Contains(scope, groupId) {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s does not manage group id %d", userId, groupId)
+	}
+	return nil
+}
+
+func (u *groupUsecase) checkRole(userId string, workspaceId int, expected domain.WorkspaceRole) error {
+	role, err := u.workspaceUsecase.GetRole(userId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get role of user id %s", userId, err)
+	} else if role == nil || *role != expected {
+		return errs.New(errs.ErrGroupInvalidRole, "user id %s is not a %s of workspace id %d", userId, expected, workspaceId)
+	}
+	return nil
+}
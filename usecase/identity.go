@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+)
+
+type identityUsecase struct {
+	identityRepository domain.IdentityRepository
+	userRepository     domain.UserRepository
+	googleUsecase      domain.GoogleUsecase
+	oidcUsecase        domain.OidcUsecase
+}
+
+func NewIdentityUsecase(
+	identityRepository domain.IdentityRepository,
+	userRepository domain.UserRepository,
+	googleUsecase domain.GoogleUsecase,
+	oidcUsecase domain.OidcUsecase,
+) domain.IdentityUsecase {
+	return &identityUsecase{
+		identityRepository: identityRepository,
+		userRepository:     userRepository,
+		googleUsecase:      googleUsecase,
+		oidcUsecase:        oidcUsecase,
+	}
+}
+
+func (u *identityUsecase) GetUser(
+	provider domain.AuthProvider,
+	subject string,
+	email string,
+) (*domain.User, error) {
+	identity, err := u.get(provider, subject, email)
+	if err != nil {
+		return nil, err
+	} else if identity == nil {
+		return nil, nil
+	}
+
+	user, err := u.userRepository.Get(identity.UserId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUser, "cannot get user id %s of identity", identity.UserId, err)
+	}
+	return user, nil
+}
+
+func (u *identityUsecase) List(userId string) ([]domain.UserIdentity, error) {
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUser, "cannot get user id %s", userId, err)
+	} else if user == nil {
+		return nil, errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+
+	identities, err := u.identityRepository.ListByUserId(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetIdentity, "cannot list identity of user id %s", userId, err)
+	}
+
+	// Password sign in is not stored as an identity but is listed for completeness
+	if user.Provider == domain.SelfAuth && user.Password != "" {
+		identities = append([]domain.UserIdentity{{
+			Provider:        domain.SelfAuth,
+			Email:           user.Email,
+			IsEmailVerified: user.IsEmailVerified,
+			UserId:          user.Id,
+			CreatedAt:       user.CreatedAt,
+		}}, identities...)
+	}
+
+	for i := range identities {
+		identities[i].IsPrimary = isPrimaryIdentity(user, identities[i].Provider, identities[i].Email)
+	}
+	return identities, nil
+}
+
+// Link stores whether the provider verified the email, only verified identities
+// are trusted as an address of the user such as when claiming invitations
+func (u *identityUsecase) Link(
+	userId string,
+	provider domain.AuthProvider,
+	subject string,
+	email string,
+	isEmailVerified bool,
+) error {
+	if provider == domain.SelfAuth {
+		return errs.New(errs.ErrIdentityProvider, "cannot link %s provider", provider)
+	}
+
+	identity, err := u.get(provider, subject, email)
+	if err != nil {
+		return err
+	} else if identity != nil {
+		if identity.UserId != userId {
+			return errs.New(errs.ErrIdentityLinked, "%s account %s is already linked to another user", provider, email)
+		}
+		if identity.Email != email || identity.IsEmailVerified != isEmailVerified {
+			if err := u.identityRepository.UpdateEmail(provider, subject, email, isEmailVerified); err != nil {
+				return errs.New(errs.ErrCreateIdentity, "cannot update %s identity of email %s", provider, email, err)
+			}
+		}
+		return nil
+	}
+
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return errs.New(errs.ErrGetUser, "cannot get user id %s", userId, err)
+	} else if user == nil {
+		return errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+
+	// An account the user signed up with is theirs whatever the provider says,
+	// any other account could belong to someone else unless its email is verified
+	if !isEmailVerified && !isPrimaryIdentity(user, provider, email) {
+		return errs.New(errs.ErrIdentityUnverified, "email of %s account %s is not verified", provider, email)
+	}
+
+	if err := u.identityRepository.Create(&domain.UserIdentity{
+		Provider:        provider,
+		Subject:         &subject,
+		Email:           email,
+		IsEmailVerified: isEmailVerified,
+		UserId:          userId,
+		CreatedAt:       time.Now(),
+	}); err != nil {
+		return errs.New(errs.ErrCreateIdentity, "cannot link %s account %s to user id %s", provider, email, userId, err)
+	}
+	return nil
+}
+
+func (u *identityUsecase) LinkGoogle(userId string, code string, state string) error {
+	if err := u.googleUsecase.VerifyState(state); err != nil {
+		return errs.New(errs.SameCode, "cannot link google account", err)
+	}
+
+	token, err := u.googleUsecase.GetToken(code)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot link google account", err)
+	}
+	googleUser, err := u.googleUsecase.GetUser(token)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot link google account", err)
+	}
+
+	if err := u.Link(userId, domain.GoogleAuth, googleUser.Id, googleUser.Email, googleUser.IsEmailVerified); err != nil {
+		return errs.New(errs.SameCode, "cannot link google account", err)
+	}
+	return nil
+}
+
+func (u *identityUsecase) LinkOidc(userId string, provider domain.AuthProvider, code string, state string) error {
+	oidcUser, err := u.oidcUsecase.GetUser(provider, code, state)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot link oidc provider %s", provider, err)
+	}
+
+	if err := u.Link(userId, provider, oidcUser.Id, oidcUser.Email, oidcUser.IsEmailVerified); err != nil {
+		return errs.New(errs.SameCode, "cannot link oidc provider %s", provider, err)
+	}
+	return nil
+}
+
+func (u *identityUsecase) Unlink(userId string, provider domain.AuthProvider, email string) error {
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return errs.New(errs.ErrGetUser, "cannot get user id %s", userId, err)
+	} else if user == nil {
+		return errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+
+	// Unlinking the primary identity would lock the user out as it is what the user signed up with
+	if isPrimaryIdentity(user, provider, email) {
+		return errs.New(errs.ErrIdentityPrimary, "cannot unlink the primary %s account", provider)
+	}
+
+	identities, err := u.identityRepository.ListByUserId(userId)
+	if err != nil {
+		return errs.New(errs.ErrGetIdentity, "cannot list identity of user id %s", userId, err)
+	}
+	isLinked := false
+	for _, identity := range identities {
+		if identity.Provider == provider && identity.Email == email {
+			isLinked = true
+			break
+		}
+	}
+	if !isLinked {
+		return errs.New(errs.ErrIdentityNotFound, "%s account %s is not linked", provider, email)
+	}
+
+	if err := u.identityRepository.Delete(userId, provider, email); err != nil {
+		return errs.New(errs.ErrDeleteIdentity, "cannot unlink %s account %s", provider, email, err)
+	}
+	return nil
+}
+
+// get finds the identity by the subject of the provider, an identity stored before
+// subjects were recorded is matched by its email once and gets the subject from then on
+func (u *identityUsecase) get(
+	provider domain.AuthProvider,
+	subject string,
+	email string,
+) (*domain.UserIdentity, error) {
+	identity, err := u.identityRepository.Get(provider, subject)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetIdentity, "cannot get %s identity of subject %s", provider, subject, err)
+	} else if identity != nil {
+		return identity, nil
+	}
+
+	identity, err = u.identityRepository.GetWithoutSubject(provider, email)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetIdentity, "cannot get %s identity of email %s", provider, email, err)
+	} else if identity == nil {
+		return nil, nil
+	}
+
+	if err := u.identityRepository.UpdateSubject(provider, email, subject); err != nil {
+		return nil, errs.New(errs.ErrCreateIdentity, "cannot update subject of %s identity of email %s", provider, email, err)
+	}
+	identity.Subject = &subject
+	return identity, nil
+}
+
+func isPrimaryIdentity(user *domain.User, provider domain.AuthProvider, email string) bool {
+	return user.Provider == provider && user.Email == email
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type impersonationUsecase struct {
+	impersonationRepository domain.ImpersonationRepository
+	userRepository          domain.UserRepository
+	workspaceUsecase        domain.WorkspaceUsecase
+	sessionUsecase          domain.SessionUsecase
+}
+
+func NewImpersonationUsecase(
+	impersonationRepository domain.ImpersonationRepository,
+	userRepository domain.UserRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+	sessionUsecase domain.SessionUsecase,
+) domain.ImpersonationUsecase {
+	return &impersonationUsecase{
+		impersonationRepository: impersonationRepository,
+		userRepository:          userRepository,
+		workspaceUsecase:        workspaceUsecase,
+		sessionUsecase:          sessionUsecase,
+	}
+}
+
+func (u *impersonationUsecase) Start(
+	impersonatorId string,
+	workspaceId int,
+	userId string,
+	ipAddress string,
+	userAgent string,
+) (*fiber.Cookie, error) {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(impersonatorId, workspaceId, domain.ManageParticipantPerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check permission to impersonate", err)
+	} else if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot impersonate in workspace id %d", impersonatorId, workspaceId)
+	}
+
+	if impersonatorId == userId {
+		return nil, errs.New(errs.ErrImpersonationTarget, "cannot impersonate oneself")
+	}
+	role, err := u.workspaceUsecase.GetRole(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get role of user id %s to impersonate", userId, err)
+	} else if role == nil || *role != domain.MemberRole {
+		return nil, errs.New(errs.ErrImpersonationTarget, "user id %s is not a member of workspace id %d", userId, workspaceId)
+	}
+
+	createdAt := time.Now()
+	impersonation := &domain.Impersonation{
+		Id:             uuid.NewString(),
+		ImpersonatorId: impersonatorId,
+		UserId:         userId,
+		WorkspaceId:    workspaceId,
+		IpAddress:      ipAddress,
+		UserAgent:      userAgent,
+		CreatedAt:      createdAt,
+		ExpiredAt:      createdAt.Add(constant.ImpersonationMaxAge),
+	}
+	if err := u.impersonationRepository.Create(impersonation); err != nil {
+		return nil, errs.New(errs.ErrCreateImpersonation, "cannot create impersonation of user id %s", userId, err)
+	}
+
+	return &fiber.Cookie{
+		Name:     constant.ImpersonationCookieName,
+		Value:    u.sessionUsecase.Sign(impersonation.Id),
+		HTTPOnly: true,
+		Expires:  impersonation.ExpiredAt,
+	}, nil
+}
+
+// Authenticate returns the impersonated user, the impersonation only stays valid
+// with the session of the impersonator and while they can still manage the workspace
+func (u *impersonationUsecase) Authenticate(
+	header string,
+	impersonatorId string,
+) (*domain.User, *domain.Impersonation, error) {
+	id, err := u.sessionUsecase.Unsign(header)
+	if err != nil {
+		return nil, nil, errs.New(errs.ErrImpersonationInvalid, "cannot unsign impersonation", err)
+	}
+
+	impersonation, err := u.impersonationRepository.Get(id)
+	if err != nil {
+		return nil, nil, errs.New(errs.ErrGetImpersonation, "cannot get impersonation", err)
+	} else if impersonation == nil || impersonation.ImpersonatorId != impersonatorId {
+		return nil, nil, errs.New(errs.ErrImpersonationInvalid, "impersonation is invalid")
+	} else if impersonation.EndedAt != nil || !time.Now().Before(impersonation.ExpiredAt) {
+		return nil, nil, errs.New(errs.ErrImpersonationInvalid, "impersonation has ended")
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(impersonatorId, impersonation.WorkspaceId, domain.ManageParticipantPerm)
+	if err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot check permission of impersonator", err)
+	} else if !isAuthorized {
+		return nil, nil, errs.New(errs.ErrImpersonationInvalid, "impersonator can no longer manage the workspace")
+	}
+
+	user, err := u.userRepository.Get(impersonation.UserId)
+	if err != nil {
+		return nil, nil, errs.New(errs.ErrGetUser, "cannot get impersonated user id %s", impersonation.UserId, err)
+	} else if user == nil {
+		return nil, nil, errs.New(errs.ErrImpersonationInvalid, "impersonated user not found")
+	}
+	return user, impersonation, nil
+}
+
+func (u *impersonationUsecase) Stop(header string) (*fiber.Cookie, error) {
+	cookie := &fiber.Cookie{
+		Name:     constant.ImpersonationCookieName,
+		HTTPOnly: true,
+		Expires:  time.Unix(0, 0),
+	}
+
+	// The cookie is cleared anyway so a stale one does not lock the impersonator out
+	id, err := u.sessionUsecase.Unsign(header)
+	if err != nil {
+		return cookie, nil
+	}
+
+	if err := u.impersonationRepository.End(id, time.Now()); err != nil {
+		return nil, errs.New(errs.ErrCreateImpersonation, "cannot end impersonation", err)
+	}
+	return cookie, nil
+}
+
+func (u *impersonationUsecase) Log(impersonationId string, method string, path string, isBlocked bool) error {
+	log := &domain.ImpersonationLog{
+		Id:              generator.GetId(),
+		ImpersonationId: impersonationId,
+		Method:          method,
+		Path:            path,
+		IsBlocked:       isBlocked,
+		CreatedAt:       time.Now(),
+	}
+	if err := u.impersonationRepository.CreateLog(log); err != nil {
+		return errs.New(errs.ErrCreateImpersonation, "cannot create log of impersonation", err)
+	}
+	return nil
+}
+
+func (u *impersonationUsecase) List(userId string, workspaceId int) ([]domain.Impersonation, error) {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageParticipantPerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check permission to list impersonation", err)
+	} else if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot list impersonation of workspace id %d", userId, workspaceId)
+	}
+
+	impersonations, err := u.impersonationRepository.ListByWorkspaceId(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetImpersonation, "cannot list impersonation of workspace id %d", workspaceId, err)
+	}
+	return impersonations, nil
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/platform"
+)
+
+type joinRequestUsecase struct {
+	wsHub                 *platform.WebSocketHub
+	joinRequestRepository domain.JoinRequestRepository
+	workspaceUsecase      domain.WorkspaceUsecase
+	userUsecase           domain.UserUsecase
+}
+
+func NewJoinRequestUsecase(
+	wsHub *platform.WebSocketHub,
+	joinRequestRepository domain.JoinRequestRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+	userUsecase domain.UserUsecase,
+) domain.JoinRequestUsecase {
+	return &joinRequestUsecase{
+		wsHub:                 wsHub,
+		joinRequestRepository: joinRequestRepository,
+		workspaceUsecase:      workspaceUsecase,
+		userUsecase:           userUsecase,
+	}
+}
+
+// Join redeems the invitation right away unless the workspace requires approval,
+// a pending request is returned instead of the workspace in that case
+func (u *joinRequestUsecase) Join(
+	userId string,
+	invitationCode string,
+) (*domain.Workspace, *domain.JoinRequest, error) {
+	invitation, err := u.workspaceUsecase.ValidateInvitation(userId, invitationCode)
+	if err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot validate invitation id %s", invitationCode, err)
+	}
+
+	workspace, err := u.workspaceUsecase.GetRaw(invitation.WorkspaceId)
+	if err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot get workspace id %d while joining", invitation.WorkspaceId, err)
+	} else if workspace == nil || workspace.IsDeleted {
+		return nil, nil, errs.New(errs.ErrWorkspaceNotFound, "workspace id %d not found", invitation.WorkspaceId)
+	}
+
+	if workspace.IsJoinApproval {
+		request, err := u.create(userId, invitation.WorkspaceId, &invitation.Id, invitation.Role)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, request, nil
+	}
+
+	if err := u.workspaceUsecase.RedeemInvitation(userId, invitation); err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot redeem invitation id %s", invitationCode, err)
+	}
+
+	joined, err := u.workspaceUsecase.Get(invitation.WorkspaceId, userId)
+	if err != nil {
+		return nil, nil, errs.New(errs.SameCode, "cannot get workspace while joining", err)
+	}
+	return joined, nil, nil
+}
+
+// Request asks to join a workspace found in the public listing
+func (u *joinRequestUsecase) Request(userId string, workspaceId int) (*domain.JoinRequest, error) {
+	isEmailVerified, err := u.userUsecase.IsEmailVerified(userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check email verification of user id %s while requesting", userId, err)
+	} else if !isEmailVerified {
+		return nil, errs.New(errs.ErrEmailNotVerified, "user id %s must verify email before joining workspace", userId)
+	}
+
+	workspace, err := u.workspaceUsecase.GetRaw(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace id %d while requesting", workspaceId, err)
+	} else if workspace == nil || workspace.IsDeleted {
+		return nil, errs.New(errs.ErrWorkspaceNotFound, "workspace id %d not found", workspaceId)
+	} else if !workspace.IsDiscoverable || workspace.IsArchived {
+		return nil, errs.New(errs.ErrWorkspaceNotDiscoverable, "workspace id %d is not discoverable", workspaceId)
+	}
+
+	isUserAlreadyJoined, err := u.workspaceUsecase.HasUser(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check if user id %s is in workspace while requesting", userId, err)
+	} else if isUserAlreadyJoined {
+		return nil, errs.New(errs.ErrWorkspaceAlreadyJoin, "user id %s is already in workspace", userId)
+	}
+
+	return u.create(userId, workspaceId, nil, domain.MemberRole)
+}
+
+func (u *joinRequestUsecase) List(
+	userId string,
+	workspaceId int,
+	status *domain.JoinRequestStatus,
+) ([]domain.JoinRequest, error) {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return nil, err
+	}
+
+	requests, err := u.joinRequestRepository.List(workspaceId, status)
+	if err != nil {
+		return nil, errs.New(errs.ErrJoinRequest, "cannot list join request of workspace id %d", workspaceId, err)
+	}
+	return requests, nil
+}
+
+// Approve adds the requester to the workspace, an invitation still existing is
+// redeemed so its use limit and redemption log stay accurate. The request is claimed
+// before the requester is added so a concurrent review cannot decide it the other way.
+func (u *joinRequestUsecase) Approve(userId string, workspaceId int, id int) error {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return err
+	}
+	request, err := u.get(workspaceId, id)
+	if err != nil {
+		return err
+	}
+
+	var invitation *domain.WorkspaceInvitation
+	if request.InvitationId != nil {
+		invitation, err = u.workspaceUsecase.GetInvitation(*request.InvitationId)
+		if err != nil {
+			return errs.New(errs.SameCode, "cannot get invitation of join request id %d", id, err)
+		}
+	}
+
+	if err := u.review(request, userId, domain.ApprovedJoinRequest); err != nil {
+		return err
+	}
+
+	if invitation != nil {
+		err = u.workspaceUsecase.RedeemInvitation(request.UserId, invitation)
+	} else {
+		err = u.workspaceUsecase.CreateParticipant(workspaceId, request.UserId, request.Role)
+	}
+	if err != nil && !errs.HasCode(err, errs.ErrWorkspaceAlreadyJoin) {
+		err = errs.New(errs.SameCode, "cannot add user id %s from join request id %d", request.UserId, id, err)
+		if reopenErr := u.joinRequestRepository.Reopen(id); reopenErr != nil {
+			return errors.Join(err, errs.New(errs.ErrJoinRequest, "cannot reopen join request id %d", id, reopenErr))
+		}
+		return err
+	}
+
+	u.notify(request)
+	return nil
+}
+
+func (u *joinRequestUsecase) Reject(userId string, workspaceId int, id int) error {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return err
+	}
+	request, err := u.get(workspaceId, id)
+	if err != nil {
+		return err
+	}
+	if err := u.review(request, userId, domain.RejectedJoinRequest); err != nil {
+		return err
+	}
+
+	u.notify(request)
+	return nil
+}
+
+func (u *joinRequestUsecase) create(
+	userId string,
+	workspaceId int,
+	invitationId *string,
+	role domain.WorkspaceRole,
+) (*domain.JoinRequest, error) {
+	pending, err := u.joinRequestRepository.GetPending(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrJoinRequest, "cannot get pending join request of user id %s", userId, err)
+	} else if pending != nil {
+		return nil, errs.New(errs.ErrJoinRequestPending, "user id %s already requested to join workspace id %d", userId, workspaceId)
+	}
+
+	request := &domain.JoinRequest{
+		Id:           generator.GetId(),
+		WorkspaceId:  workspaceId,
+		UserId:       userId,
+		InvitationId: invitationId,
+		Role:         role,
+		Status:       domain.PendingJoinRequest,
+		CreatedAt:    time.Now(),
+	}
+	if err := u.joinRequestRepository.Create(request); err != nil {
+		return nil, errs.New(errs.ErrJoinRequest, "cannot create join request of user id %s", userId, err)
+	}
+	return request, nil
+}
+
+func (u *joinRequestUsecase) review(request *domain.JoinRequest, reviewerId string, status domain.JoinRequestStatus) error {
+	now := time.Now()
+	request.Status = status
+	request.ReviewerId = &reviewerId
+	request.ReviewedAt = &now
+
+	isReviewed, err := u.joinRequestRepository.Review(request)
+	if err != nil {
+		return errs.New(errs.ErrJoinRequest, "cannot review join request id %d", request.Id, err)
+	} else if !isReviewed {
+		return errs.New(errs.ErrJoinRequestReviewed, "join request id %d is already reviewed", request.Id)
+	}
+	return nil
+}
+
+// notify tells the requester about the decision, they may not be connected
+// and will find out on their next visit
+func (u *joinRequestUsecase) notify(request *domain.JoinRequest) {
+	go u.wsHub.SendMessage(request.UserId, "onJoinRequestUpdate", request)
+}
+
+func (u *joinRequestUsecase) get(workspaceId int, id int) (*domain.JoinRequest, error) {
+	request, err := u.joinRequestRepository.Get(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrJoinRequest, "cannot get join request id %d", id, err)
+	} else if request == nil || request.WorkspaceId != workspaceId {
+		return nil, errs.New(errs.ErrJoinRequestNotFound, "join request id %d not found", id)
+	} else if request.Status != domain.PendingJoinRequest {
+		return nil, errs.New(errs.ErrJoinRequestReviewed, "join request id %d is already reviewed", id)
+	}
+	return request, nil
+}
+
+func (u *joinRequestUsecase) checkPerm(userId string, workspaceId int) error {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageParticipantPerm)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to review join request", userId, err)
+	} else if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot review join request of workspace id %d", userId, workspaceId)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/internal/oidc"
+)
+
+type oidcProvider struct {
+	cfg    config.ConfigAuthOidc
+	client *oidc.Provider
+}
+
+type oidcUsecase struct {
+	oidcRepository domain.OidcRepository
+	providers      map[domain.AuthProvider]*oidcProvider
+	providerList   []domain.OidcProvider
+}
+
+func NewOidcUsecase(
+	cfg *config.Config,
+	oidcRepository domain.OidcRepository,
+) domain.OidcUsecase {
+	providers := make(map[domain.AuthProvider]*oidcProvider)
+	providerList := make([]domain.OidcProvider, 0, len(cfg.Auth.Oidc))
+
+	for _, providerCfg := range cfg.Auth.Oidc {
+		scopes := providerCfg.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+
+		provider := domain.AuthProvider(providerCfg.Provider)
+		providers[provider] = &oidcProvider{
+			cfg: providerCfg,
+			client: oidc.NewProvider(
+				providerCfg.Issuer,
+				providerCfg.ClientId,
+				providerCfg.ClientSecret,
+				providerCfg.RedirectUri,
+				scopes,
+			),
+		}
+		providerList = append(providerList, domain.OidcProvider{
+			Provider: provider,
+			Name:     providerCfg.Name,
+		})
+	}
+
+	return &oidcUsecase{
+		oidcRepository: oidcRepository,
+		providers:      providers,
+		providerList:   providerList,
+	}
+}
+
+func (u *oidcUsecase) ListProviders() []domain.OidcProvider {
+	return u.providerList
+}
+
+func (u *oidcUsecase) GetAuthRequest(provider domain.AuthProvider) (*domain.OidcAuthRequest, error) {
+	oidcProvider, ok := u.providers[provider]
+	if !ok {
+		return nil, errs.New(errs.ErrOidcProvider, "oidc provider %s not found", provider)
+	}
+
+	state, err := generator.RandToken(32)
+	if err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot generate oidc state", err)
+	}
+	nonce, err := generator.RandToken(32)
+	if err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot generate oidc nonce", err)
+	}
+	codeVerifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot generate pkce code verifier", err)
+	}
+
+	authUrl, err := oidcProvider.client.AuthCodeUrl(state, nonce, codeVerifier)
+	if err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot get auth url of oidc provider %s", provider, err)
+	}
+
+	createdAt := time.Now()
+	expiredAt := createdAt.Add(constant.OidcStateMaxAge)
+	if err := u.oidcRepository.CreateState(&domain.OidcState{
+		Id:           generator.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		CreatedAt:    createdAt,
+		ExpiredAt:    expiredAt,
+	}); err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot create oidc state", err)
+	}
+
+	return &domain.OidcAuthRequest{
+		Url:       authUrl,
+		State:     state,
+		ExpiredAt: expiredAt,
+	}, nil
+}
+
+func (u *oidcUsecase) GetUser(
+	provider domain.AuthProvider,
+	code string,
+	state string,
+) (*domain.OidcUser, error) {
+	oidcProvider, ok := u.providers[provider]
+	if !ok {
+		return nil, errs.New(errs.ErrOidcProvider, "oidc provider %s not found", provider)
+	}
+
+	oidcState, err := useOidcState(u.oidcRepository, provider, state)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot verify oidc state", err)
+	}
+
+	token, err := oidcProvider.client.Exchange(code, oidcState.CodeVerifier)
+	if err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot exchange code with oidc provider %s", provider, err)
+	}
+
+	claims, err := oidcProvider.client.VerifyIdToken(token.IdToken, oidcState.Nonce)
+	if err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot verify id token from oidc provider %s", provider, err)
+	}
+
+	claimNames := oidcProvider.cfg.Claims
+	idClaim := valueOrDefault(claimNames.Id, "sub")
+	emailClaim := valueOrDefault(claimNames.Email, "email")
+	nameClaim := valueOrDefault(claimNames.Name, "name")
+	emailVerifiedClaim := valueOrDefault(claimNames.EmailVerified, "email_verified")
+
+	// Some providers only put the profile claims in the userinfo response
+	if claims.String(emailClaim) == "" && token.AccessToken != "" {
+		userInfo, err := oidcProvider.client.GetUserInfo(token.AccessToken)
+		if err != nil {
+			return nil, errs.New(errs.ErrOidcAuth, "cannot get userinfo from oidc provider %s", provider, err)
+		}
+		if userInfo.String("sub") != claims.String("sub") {
+			return nil, errs.New(errs.ErrOidcClaim, "userinfo subject does not match id token")
+		}
+		for name, value := range userInfo {
+			if _, ok := claims[name]; !ok {
+				claims[name] = value
+			}
+		}
+	}
+
+	user := &domain.OidcUser{
+		Id:              claims.String(idClaim),
+		Email:           claims.String(emailClaim),
+		Name:            claims.String(nameClaim),
+		IsEmailVerified: claims.Bool(emailVerifiedClaim),
+	}
+	if user.Id == "" || user.Email == "" {
+		return nil, errs.New(errs.ErrOidcClaim, "oidc provider %s did not return id or email claim", provider)
+	}
+	if user.Name == "" {
+		user.Name = user.Email
+	}
+	return user, nil
+}
+
+func valueOrDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// useOidcState consumes the state issued to the browser starting the flow,
+// the state is single use whether the flow succeeds or not
+func useOidcState(
+	oidcRepository domain.OidcRepository,
+	provider domain.AuthProvider,
+	state string,
+) (*domain.OidcState, error) {
+	id := generator.HashToken(state)
+	oidcState, err := oidcRepository.GetState(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot get oidc state", err)
+	} else if oidcState == nil || oidcState.Provider != provider {
+		return nil, errs.New(errs.ErrOidcState, "oidc state is invalid")
+	}
+
+	if err := oidcRepository.DeleteState(id); err != nil {
+		return nil, errs.New(errs.ErrOidcAuth, "cannot delete oidc state", err)
+	}
+	if !time.Now().Before(oidcState.ExpiredAt) {
+		return nil, errs.New(errs.ErrOidcState, "oidc state expired")
+	}
+	return oidcState, nil
+}
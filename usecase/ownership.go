@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/platform"
+)
+
+type ownershipTransferUsecase struct {
+	wsHub                       *platform.WebSocketHub
+	ownershipTransferRepository domain.OwnershipTransferRepository
+	workspaceUsecase            domain.WorkspaceUsecase
+	quotaUsecase                domain.QuotaUsecase
+}
+
+func NewOwnershipTransferUsecase(
+	wsHub *platform.WebSocketHub,
+	ownershipTransferRepository domain.OwnershipTransferRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+	quotaUsecase domain.QuotaUsecase,
+) domain.OwnershipTransferUsecase {
+	return &ownershipTransferUsecase{
+		wsHub:                       wsHub,
+		ownershipTransferRepository: ownershipTransferRepository,
+		workspaceUsecase:            workspaceUsecase,
+		quotaUsecase:                quotaUsecase,
+	}
+}
+
+// Offer proposes the ownership of the workspace to another participant,
+// a workspace has at most one pending offer at a time
+func (u *ownershipTransferUsecase) Offer(
+	userId string,
+	workspaceId int,
+	targetId string,
+) (*domain.OwnershipTransfer, error) {
+	if err := u.checkOwner(userId, workspaceId); err != nil {
+		return nil, err
+	}
+	if userId == targetId {
+		return nil, errs.New(errs.ErrOwnershipTransferTarget, "cannot transfer ownership to yourself")
+	}
+
+	isParticipant, err := u.workspaceUsecase.HasUser(targetId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check if target id %s is in workspace", targetId, err)
+	} else if !isParticipant {
+		return nil, errs.New(errs.ErrOwnershipTransferTarget, "target id %s is not in workspace", targetId)
+	}
+
+	pending, err := u.ownershipTransferRepository.GetPending(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrOwnershipTransfer, "cannot get pending ownership transfer of workspace id %d", workspaceId, err)
+	} else if pending != nil {
+		return nil, errs.New(errs.ErrOwnershipTransferPending, "workspace id %d already has a pending ownership transfer", workspaceId)
+	}
+
+	now := time.Now()
+	transfer := &domain.OwnershipTransfer{
+		Id:          generator.GetId(),
+		WorkspaceId: workspaceId,
+		FromUserId:  userId,
+		ToUserId:    targetId,
+		Status:      domain.PendingOwnershipTransfer,
+		CreatedAt:   now,
+		ExpiredAt:   now.Add(constant.OwnershipTransferMaxAge),
+	}
+	if err := u.ownershipTransferRepository.Create(transfer); err != nil {
+		return nil, errs.New(errs.ErrOwnershipTransfer, "cannot create ownership transfer of workspace id %d", workspaceId, err)
+	}
+
+	go u.notify(transfer.ToUserId, transfer)
+	return transfer, nil
+}
+
+// Accept makes the user the OWNER of the workspace and demotes the previous owner to ADMIN
+func (u *ownershipTransferUsecase) Accept(userId string, workspaceId int, id int) error {
+	transfer, err := u.getPending(workspaceId, id)
+	if err != nil {
+		return err
+	} else if transfer.ToUserId != userId {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot accept ownership transfer id %d", userId, id)
+	}
+
+	maxOwned, err := u.quotaUsecase.CheckOwnedWorkspace(userId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot accept ownership transfer id %d", id, err)
+	}
+
+	now := time.Now()
+	transfer.Status = domain.AcceptedOwnershipTransfer
+	transfer.RespondedAt = &now
+
+	isAccepted, err := u.ownershipTransferRepository.Accept(transfer, maxOwned)
+	if err != nil {
+		return errs.New(errs.ErrOwnershipTransfer, "cannot accept ownership transfer id %d", id, err)
+	} else if !isAccepted {
+		// Another workspace may have taken the last of the quota since it was checked
+		if _, err := u.quotaUsecase.CheckOwnedWorkspace(userId); err != nil {
+			return errs.New(errs.SameCode, "cannot accept ownership transfer id %d", id, err)
+		}
+		return errs.New(errs.ErrOwnershipTransferTarget, "ownership transfer id %d is no longer applicable", id)
+	}
+
+	go u.notify(transfer.FromUserId, transfer)
+	return nil
+}
+
+func (u *ownershipTransferUsecase) Decline(userId string, workspaceId int, id int) error {
+	transfer, err := u.getPending(workspaceId, id)
+	if err != nil {
+		return err
+	} else if transfer.ToUserId != userId {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot decline ownership transfer id %d", userId, id)
+	}
+
+	if err := u.respond(transfer, domain.DeclinedOwnershipTransfer); err != nil {
+		return err
+	}
+
+	go u.notify(transfer.FromUserId, transfer)
+	return nil
+}
+
+func (u *ownershipTransferUsecase) Cancel(userId string, workspaceId int, id int) error {
+	transfer, err := u.getPending(workspaceId, id)
+	if err != nil {
+		return err
+	} else if transfer.FromUserId != userId {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot cancel ownership transfer id %d", userId, id)
+	}
+
+	if err := u.respond(transfer, domain.CancelledOwnershipTransfer); err != nil {
+		return err
+	}
+
+	go u.notify(transfer.ToUserId, transfer)
+	return nil
+}
+
+// List returns every ownership transfer of the workspace as an audit trail of its owners
+func (u *ownershipTransferUsecase) List(userId string, workspaceId int) ([]domain.OwnershipTransfer, error) {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageWorkspacePerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check permission of user id %s to list ownership transfer", userId, err)
+	}
+
+	transfers, err := u.ownershipTransferRepository.List(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrOwnershipTransfer, "cannot list ownership transfer of workspace id %d", workspaceId, err)
+	}
+
+	// A member only sees the offers made to them
+	if !isAuthorized {
+		offered := make([]domain.OwnershipTransfer, 0)
+		for _, transfer := range transfers {
+			if transfer.ToUserId == userId {
+				offered = append(offered, transfer)
+			}
+		}
+		return offered, nil
+	}
+	return transfers, nil
+}
+
+func (u *ownershipTransferUsecase) respond(
+	transfer *domain.OwnershipTransfer,
+	status domain.OwnershipTransferStatus,
+) error {
+	now := time.Now()
+	transfer.Status = status
+	transfer.RespondedAt = &now
+
+	isResponded, err := u.ownershipTransferRepository.Respond(transfer)
+	if err != nil {
+		return errs.New(errs.ErrOwnershipTransfer, "cannot respond to ownership transfer id %d", transfer.Id, err)
+	} else if !isResponded {
+		return errs.New(errs.ErrOwnershipTransferNotFound, "ownership transfer id %d is not pending", transfer.Id)
+	}
+	return nil
+}
+
+func (u *ownershipTransferUsecase) getPending(workspaceId int, id int) (*domain.OwnershipTransfer, error) {
+	transfer, err := u.ownershipTransferRepository.Get(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrOwnershipTransfer, "cannot get ownership transfer id %d", id, err)
+	} else if transfer == nil || transfer.WorkspaceId != workspaceId ||
+		transfer.Status != domain.PendingOwnershipTransfer {
+		return nil, errs.New(errs.ErrOwnershipTransferNotFound, "pending ownership transfer id %d not found", id)
+	} else if transfer.ExpiredAt.Before(time.Now()) {
+		return nil, errs.New(errs.ErrOwnershipTransferExpired, "ownership transfer id %d is expired", id)
+	}
+	return transfer, nil
+}
+
+func (u *ownershipTransferUsecase) checkOwner(userId string, workspaceId int) error {
+	isAuthorized, err := u.workspaceUsecase.CheckPermRole(userId, workspaceId, []domain.WorkspaceRole{domain.OwnerRole})
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to transfer ownership", userId, err)
+	} else if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s is not the owner of workspace id %d", userId, workspaceId)
+	}
+	return nil
+}
+
+// notify tells the other side of the transfer, they may not be connected
+func (u *ownershipTransferUsecase) notify(userId string, transfer *domain.OwnershipTransfer) {
+	u.wsHub.SendMessage(userId, "onOwnershipTransferUpdate", transfer)
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/platform"
+)
+
+type passwordResetUsecase struct {
+	cfg                     *config.Config
+	mailSender              platform.MailSender
+	passwordResetRepository domain.PasswordResetRepository
+	userUsecase             domain.UserUsecase
+}
+
+func NewPasswordResetUsecase(
+	cfg *config.Config,
+	mailSender platform.MailSender,
+	passwordResetRepository domain.PasswordResetRepository,
+	userUsecase domain.UserUsecase,
+) domain.PasswordResetUsecase {
+	return &passwordResetUsecase{
+		cfg:                     cfg,
+		mailSender:              mailSender,
+		passwordResetRepository: passwordResetRepository,
+		userUsecase:             userUsecase,
+	}
+}
+
+func (u *passwordResetUsecase) Request(email string) error {
+	count, err := u.passwordResetRepository.CountByEmail(email, time.Now().Add(-constant.PasswordResetRateWindow))
+	if err != nil {
+		return errs.New(errs.ErrCreatePasswordReset, "cannot count password reset of email %s", email, err)
+	} else if count >= constant.PasswordResetRateLimit {
+		return errs.New(errs.ErrPasswordResetLimit, "too many password reset requests for email %s", email)
+	}
+
+	user, err := u.userUsecase.GetByEmail(email, domain.SelfAuth)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get user to request password reset", err)
+	} else if user == nil {
+		// Do not reveal whether the email is registered
+		return nil
+	}
+
+	token, err := generator.RandToken(32)
+	if err != nil {
+		return errs.New(errs.ErrCreatePasswordReset, "cannot generate password reset token", err)
+	}
+
+	createdAt := time.Now()
+	reset := &domain.PasswordReset{
+		Id:        generator.HashToken(token),
+		UserId:    user.Id,
+		Email:     user.Email,
+		CreatedAt: createdAt,
+		ExpiredAt: createdAt.Add(constant.PasswordResetMaxAge),
+	}
+	if err := u.passwordResetRepository.Create(reset); err != nil {
+		return errs.New(errs.ErrCreatePasswordReset, "cannot create password reset of user id %s", user.Id, err)
+	}
+
+	link, err := url.JoinPath(u.cfg.Client.Frontend.BaseUrl, u.cfg.Client.Frontend.Path.ResetPassword)
+	if err != nil {
+		return errs.New(errs.ErrCreateUrlPath, "invalid password reset url", err)
+	}
+	link = link + "?" + url.Values{"token": {token}}.Encode()
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one.\n\n%s\n\n"+
+			"The link will expire in %s. If you did not request this, you can ignore this email.",
+		user.DisplayName, link, constant.PasswordResetMaxAge,
+	)
+	if err := u.mailSender.Send(user.Email, "Reset your password", body); err != nil {
+		return errs.New(errs.ErrSendMail, "cannot send password reset to user id %s", user.Id, err)
+	}
+	return nil
+}
+
+func (u *passwordResetUsecase) Reset(token string, newPassword string) error {
+	id := generator.HashToken(token)
+
+	reset, err := u.passwordResetRepository.Get(id)
+	if err != nil {
+		return errs.New(errs.ErrResetPassword, "cannot get password reset", err)
+	} else if reset == nil || reset.UsedAt != nil {
+		return errs.New(errs.ErrVerificationToken, "password reset token is invalid")
+	}
+
+	if !time.Now().Before(reset.ExpiredAt) {
+		return errs.New(errs.ErrVerificationExpired, "password reset token expired")
+	}
+
+	// Validate before consuming so a weak password does not burn the token
+	if err := validatePassword(newPassword); err != nil {
+		return errs.New(errs.SameCode, "cannot reset password", err)
+	}
+
+	isUsed, err := u.passwordResetRepository.Use(id, time.Now())
+	if err != nil {
+		return errs.New(errs.ErrResetPassword, "cannot use password reset token", err)
+	} else if !isUsed {
+		return errs.New(errs.ErrVerificationToken, "password reset token is invalid")
+	}
+
+	if err := u.userUsecase.ResetPassword(reset.UserId, newPassword); err != nil {
+		return errs.New(errs.SameCode, "cannot reset password of user id %s", reset.UserId, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"bytes"
+	"image"
+	"io"
+
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/avatar"
+	"github.com/codern-org/codern/platform"
+)
+
+func decodeProfile(r io.Reader) (image.Image, error) {
+	img, err := avatar.Decode(r)
+	if err != nil {
+		return nil, errs.New(errs.ErrInvalidImage, "cannot decode profile image", err)
+	}
+	return img, nil
+}
+
+// uploadProfile stores the image in every avatar size, uploads are re-encoded
+// so metadata like the location of a photo is not kept
+func uploadProfile(seaweedfs *platform.SeaweedFs, img image.Image, profileUrl string) error {
+	for _, size := range avatar.Sizes {
+		content, err := avatar.EncodePng(avatar.Resize(img, size))
+		if err != nil {
+			return errs.New(errs.ErrProcessImage, "cannot encode profile image in size %d", size, err)
+		}
+		if err := seaweedfs.Upload(bytes.NewReader(content), len(content), avatar.Path(profileUrl, size)); err != nil {
+			return errs.New(errs.ErrProcessImage, "cannot upload profile image in size %d", size, err)
+		}
+	}
+	return nil
+}
+
+func uploadIdenticon(seaweedfs *platform.SeaweedFs, seed string, profileUrl string) error {
+	return uploadProfile(seaweedfs, avatar.Identicon(seed, avatar.Sizes[0]), profileUrl)
+}
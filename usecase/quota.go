@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/config"
+)
+
+type quotaUsecase struct {
+	cfg             *config.Config
+	quotaRepository domain.QuotaRepository
+	userRepository  domain.UserRepository
+}
+
+func NewQuotaUsecase(
+	cfg *config.Config,
+	quotaRepository domain.QuotaRepository,
+	userRepository domain.UserRepository,
+) domain.QuotaUsecase {
+	return &quotaUsecase{
+		cfg:             cfg,
+		quotaRepository: quotaRepository,
+		userRepository:  userRepository,
+	}
+}
+
+func (u *quotaUsecase) Get(accountType domain.AccountType) domain.Quota {
+	limit := u.cfg.Quota.Free
+	if accountType == domain.ProAccount {
+		limit = u.cfg.Quota.Pro
+	}
+	return domain.Quota{
+		OwnedWorkspaces:          limit.OwnedWorkspaces,
+		ParticipantsPerWorkspace: limit.ParticipantsPerWorkspace,
+		AssignmentsPerWorkspace:  limit.AssignmentsPerWorkspace,
+		TestcaseBytes:            limit.TestcaseBytes,
+		DailySubmissions:         limit.DailySubmissions,
+	}
+}
+
+func (u *quotaUsecase) GetUsage(userId string) (*domain.Usage, error) {
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUser, "cannot get user id %s to get usage", userId, err)
+	} else if user == nil {
+		return nil, errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+
+	ownedWorkspaces, err := u.quotaRepository.CountOwnedWorkspace(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUsage, "cannot count owned workspace of user id %s", userId, err)
+	}
+	testcaseBytes, err := u.quotaRepository.SumTestcaseSize(userId, 0)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUsage, "cannot sum testcase size of user id %s", userId, err)
+	}
+	dailySubmissions, err := u.quotaRepository.CountSubmission(userId, startOfDay(time.Now()))
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUsage, "cannot count submission of user id %s", userId, err)
+	}
+	workspaces, err := u.quotaRepository.ListWorkspaceUsage(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUsage, "cannot list workspace usage of user id %s", userId, err)
+	}
+
+	return &domain.Usage{
+		AccountType:      user.Type,
+		Quota:            u.Get(user.Type),
+		OwnedWorkspaces:  ownedWorkspaces,
+		TestcaseBytes:    testcaseBytes,
+		DailySubmissions: dailySubmissions,
+		Workspaces:       workspaces,
+	}, nil
+}
+
+// The checks below reject a request early with the usage it would go over, the returned
+// limit is enforced again by the repository in the transaction inserting the counted row
+
+func (u *quotaUsecase) CheckOwnedWorkspace(userId string) (int, error) {
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return 0, errs.New(errs.ErrGetUser, "cannot get user id %s to check workspace quota", userId, err)
+	} else if user == nil {
+		return 0, errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+
+	limit := u.Get(user.Type).OwnedWorkspaces
+	if limit == domain.UnlimitedQuota {
+		return limit, nil
+	}
+
+	count, err := u.quotaRepository.CountOwnedWorkspace(userId)
+	if err != nil {
+		return 0, errs.New(errs.ErrGetUsage, "cannot count owned workspace of user id %s", userId, err)
+	} else if count >= limit {
+		return 0, errs.New(errs.ErrWorkspaceQuota, "%s account can own up to %d workspaces", user.Type, limit)
+	}
+	return limit, nil
+}
+
+func (u *quotaUsecase) CheckParticipant(workspaceId int) (int, error) {
+	quota, err := u.getWorkspaceQuota(workspaceId)
+	if err != nil {
+		return 0, err
+	} else if quota.ParticipantsPerWorkspace == domain.UnlimitedQuota {
+		return quota.ParticipantsPerWorkspace, nil
+	}
+
+	count, err := u.quotaRepository.CountParticipant(workspaceId)
+	if err != nil {
+		return 0, errs.New(errs.ErrGetUsage, "cannot count participant of workspace id %d", workspaceId, err)
+	} else if count >= quota.ParticipantsPerWorkspace {
+		return 0, errs.New(
+			errs.ErrParticipantQuota,
+			"workspace id %d has reached the limit of %d participants", workspaceId, quota.ParticipantsPerWorkspace,
+		)
+	}
+	return quota.ParticipantsPerWorkspace, nil
+}
+
+func (u *quotaUsecase) CheckAssignment(workspaceId int) (int, error) {
+	quota, err := u.getWorkspaceQuota(workspaceId)
+	if err != nil {
+		return 0, err
+	} else if quota.AssignmentsPerWorkspace == domain.UnlimitedQuota {
+		return quota.AssignmentsPerWorkspace, nil
+	}
+
+	count, err := u.quotaRepository.CountAssignment(workspaceId)
+	if err != nil {
+		return 0, errs.New(errs.ErrGetUsage, "cannot count assignment of workspace id %d", workspaceId, err)
+	} else if count >= quota.AssignmentsPerWorkspace {
+		return 0, errs.New(
+			errs.ErrAssignmentQuota,
+			"workspace id %d has reached the limit of %d assignments", workspaceId, quota.AssignmentsPerWorkspace,
+		)
+	}
+	return quota.AssignmentsPerWorkspace, nil
+}
+
+// CheckTestcaseBytes checks the testcases of the assignment being replaced by the given size,
+// the storage is shared between all workspaces of the owner
+func (u *quotaUsecase) CheckTestcaseBytes(workspaceId int, assignmentId int, size int64) (int64, error) {
+	owner, err := u.quotaRepository.GetWorkspaceOwner(workspaceId)
+	if err != nil {
+		return 0, errs.New(errs.ErrGetUsage, "cannot get owner of workspace id %d", workspaceId, err)
+	} else if owner == nil {
+		return 0, errs.New(errs.ErrWorkspaceNotFound, "owner of workspace id %d not found", workspaceId)
+	}
+
+	limit := u.Get(owner.Type).TestcaseBytes
+	if limit == domain.UnlimitedQuota {
+		return limit, nil
+	}
+
+	used, err := u.quotaRepository.SumTestcaseSize(owner.Id, assignmentId)
+	if err != nil {
+		return 0, errs.New(errs.ErrGetUsage, "cannot sum testcase size of user id %s", owner.Id, err)
+	} else if used+size > limit {
+		return 0, errs.New(
+			errs.ErrTestcaseQuota,
+			"testcases exceed the storage limit of %d bytes, %d bytes are used", limit, used,
+		)
+	}
+	return limit, nil
+}
+
+func (u *quotaUsecase) CheckDailySubmission(userId string) (int, error) {
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return 0, errs.New(errs.ErrGetUser, "cannot get user id %s to check submission quota", userId, err)
+	} else if user == nil {
+		return 0, errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+
+	limit := u.Get(user.Type).DailySubmissions
+	if limit == domain.UnlimitedQuota {
+		return limit, nil
+	}
+
+	count, err := u.quotaRepository.CountSubmission(userId, startOfDay(time.Now()))
+	if err != nil {
+		return 0, errs.New(errs.ErrGetUsage, "cannot count submission of user id %s", userId, err)
+	} else if count >= limit {
+		return 0, errs.New(errs.ErrSubmissionQuota, "%s account can submit up to %d times a day", user.Type, limit)
+	}
+	return limit, nil
+}
+
+func (u *quotaUsecase) getWorkspaceQuota(workspaceId int) (*domain.Quota, error) {
+	owner, err := u.quotaRepository.GetWorkspaceOwner(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUsage, "cannot get owner of workspace id %d", workspaceId, err)
+	} else if owner == nil {
+		return nil, errs.New(errs.ErrWorkspaceNotFound, "owner of workspace id %d not found", workspaceId)
+	}
+
+	quota := u.Get(owner.Type)
+	return &quota, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
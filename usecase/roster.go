@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+)
+
+type rosterUsecase struct {
+	rosterRepository   domain.RosterRepository
+	userRepository     domain.UserRepository
+	identityRepository domain.IdentityRepository
+	workspaceUsecase   domain.WorkspaceUsecase
+}
+
+func NewRosterUsecase(
+	rosterRepository domain.RosterRepository,
+	userRepository domain.UserRepository,
+	identityRepository domain.IdentityRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+) domain.RosterUsecase {
+	return &rosterUsecase{
+		rosterRepository:   rosterRepository,
+		userRepository:     userRepository,
+		identityRepository: identityRepository,
+		workspaceUsecase:   workspaceUsecase,
+	}
+}
+
+// Import reads a csv of email and an optional role per line, users with a verified email are
+// added right away and the other emails get a pending invitation. Rows never fail the whole import,
+// the outcome of each row is in the report.
+func (u *rosterUsecase) Import(importerId string, workspaceId int, roster io.Reader) (*domain.RosterReport, error) {
+	if err := u.checkPerm(importerId, workspaceId); err != nil {
+		return nil, err
+	}
+	role, err := u.workspaceUsecase.GetRole(importerId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get role of user id %s to import roster", importerId, err)
+	}
+
+	rows, err := parseRoster(roster)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.RosterReport{Rows: rows}
+	seen := make(map[string]bool)
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		u.importRow(importerId, workspaceId, *role == domain.OwnerRole, row, seen)
+
+		switch row.Status {
+		case domain.RosterAdded:
+			report.Added++
+		case domain.RosterInvited:
+			report.Invited++
+		case domain.RosterInvalid, domain.RosterFailed:
+			report.Failed++
+		default:
+			report.Skipped++
+		}
+	}
+	return report, nil
+}
+
+func (u *rosterUsecase) importRow(
+	importerId string,
+	workspaceId int,
+	isOwner bool,
+	row *domain.RosterRow,
+	seen map[string]bool,
+) {
+	if address, err := mail.ParseAddress(row.Email); err != nil || address.Address != row.Email {
+		row.Status, row.Reason = domain.RosterInvalid, "invalid email"
+		return
+	}
+	if row.Role != domain.MemberRole && row.Role != domain.AdminRole {
+		row.Status, row.Reason = domain.RosterInvalid, "role must be MEMBER or ADMIN"
+		return
+	}
+	if row.Role == domain.AdminRole && !isOwner {
+		row.Status, row.Reason = domain.RosterInvalid, "only the owner can add admins"
+		return
+	}
+	if seen[row.Email] {
+		row.Status = domain.RosterDuplicated
+		return
+	}
+	seen[row.Email] = true
+
+	userId, err := u.rosterRepository.GetVerifiedUserIdByEmail(row.Email)
+	if err != nil {
+		row.Status, row.Reason = domain.RosterFailed, "cannot look up user"
+		return
+	}
+
+	if userId != nil {
+		err := u.workspaceUsecase.CreateParticipant(workspaceId, *userId, row.Role)
+		switch {
+		case err == nil:
+			row.Status = domain.RosterAdded
+		case errs.HasCode(err, errs.ErrWorkspaceAlreadyJoin):
+			row.Status = domain.RosterAlreadyJoined
+		case errs.HasCode(err, errs.ErrParticipantQuota):
+			row.Status, row.Reason = domain.RosterFailed, "participant quota reached"
+		default:
+			row.Status, row.Reason = domain.RosterFailed, "cannot add participant"
+		}
+		return
+	}
+
+	isInvited, err := u.rosterRepository.HasPendingInvitation(workspaceId, row.Email)
+	if err != nil {
+		row.Status, row.Reason = domain.RosterFailed, "cannot check pending invitation"
+		return
+	} else if isInvited {
+		row.Status = domain.RosterAlreadyInvited
+		return
+	}
+
+	if err := u.rosterRepository.CreatePendingInvitation(&domain.PendingInvitation{
+		Id:          generator.GetId(),
+		WorkspaceId: workspaceId,
+		Email:       row.Email,
+		Role:        row.Role,
+		InviterId:   importerId,
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		row.Status, row.Reason = domain.RosterFailed, "cannot create pending invitation"
+		return
+	}
+	row.Status = domain.RosterInvited
+}
+
+func (u *rosterUsecase) ListPendingInvitation(userId string, workspaceId int) ([]domain.PendingInvitation, error) {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return nil, err
+	}
+
+	invitations, err := u.rosterRepository.ListPendingInvitation(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrPendingInvitation, "cannot list pending invitation of workspace id %d", workspaceId, err)
+	}
+	return invitations, nil
+}
+
+func (u *rosterUsecase) DeletePendingInvitation(userId string, workspaceId int, id int) error {
+	if err := u.checkPerm(userId, workspaceId); err != nil {
+		return err
+	}
+
+	invitation, err := u.rosterRepository.GetPendingInvitation(id)
+	if err != nil {
+		return errs.New(errs.ErrPendingInvitation, "cannot get pending invitation id %d", id, err)
+	} else if invitation == nil || invitation.WorkspaceId != workspaceId {
+		return errs.New(errs.ErrPendingNotFound, "pending invitation id %d not found", id)
+	}
+
+	if err := u.rosterRepository.DeletePendingInvitation(id); err != nil {
+		return errs.New(errs.ErrPendingInvitation, "cannot delete pending invitation id %d", id, err)
+	}
+	return nil
+}
+
+// Claim joins the user to every workspace with a pending invitation for one of their verified emails,
+// invitations blocked by the participant quota are kept to be claimed later
+func (u *rosterUsecase) Claim(userId string) error {
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return errs.New(errs.ErrGetUser, "cannot get user id %s to claim invitation", userId, err)
+	} else if user == nil {
+		return errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+
+	emails := make([]string, 0)
+	if user.IsEmailVerified {
+		emails = append(emails, strings.ToLower(user.Email))
+	}
+	identities, err := u.identityRepository.ListByUserId(userId)
+	if err != nil {
+		return errs.New(errs.ErrGetIdentity, "cannot list identity of user id %s to claim invitation", userId, err)
+	}
+	for _, identity := range identities {
+		if identity.IsEmailVerified {
+			emails = append(emails, strings.ToLower(identity.Email))
+		}
+	}
+
+	invitations, err := u.rosterRepository.ListPendingInvitationByEmails(emails)
+	if err != nil {
+		return errs.New(errs.ErrPendingInvitation, "cannot list pending invitation of user id %s", userId, err)
+	}
+
+	for _, invitation := range invitations {
+		err := u.workspaceUsecase.CreateParticipant(invitation.WorkspaceId, userId, invitation.Role)
+		if errs.HasCode(err, errs.ErrParticipantQuota) {
+			continue
+		} else if err != nil && !errs.HasCode(err, errs.ErrWorkspaceAlreadyJoin) {
+			return errs.New(errs.SameCode, "cannot claim pending invitation id %d", invitation.Id, err)
+		}
+
+		if err := u.rosterRepository.DeletePendingInvitation(invitation.Id); err != nil {
+			return errs.New(errs.ErrPendingInvitation, "cannot delete claimed invitation id %d", invitation.Id, err)
+		}
+	}
+	return nil
+}
+
+// checkPerm guards the whole roster, the pending invitations it creates are managed
+// with the same permission as the participants it adds
+func (u *rosterUsecase) checkPerm(userId string, workspaceId int) error {
+	isAuthorized, err := u.workspaceUsecase.CheckPermission(userId, workspaceId, domain.ManageParticipantPerm)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to manage roster", userId, err)
+	} else if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s cannot manage roster of workspace id %d", userId, workspaceId)
+	}
+	return nil
+}
+
+// parseRoster reads one row per line, a first line with "email" as its first column is a header
+func parseRoster(roster io.Reader) ([]domain.RosterRow, error) {
+	reader := csv.NewReader(roster)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows := make([]domain.RosterRow, 0)
+	for isFirst := true; ; isFirst = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errs.New(errs.ErrRosterFormat, "cannot read roster", err)
+		}
+
+		email := strings.ToLower(strings.TrimSpace(record[0]))
+		if isFirst && email == "email" {
+			continue
+		}
+		if len(rows) >= constant.MaxRosterRow {
+			return nil, errs.New(errs.ErrRosterTooLarge, "roster must not exceed %d rows", constant.MaxRosterRow)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := domain.RosterRow{Line: line, Email: email, Role: domain.MemberRole}
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			row.Role = domain.WorkspaceRole(strings.ToUpper(strings.TrimSpace(record[1])))
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, errs.New(errs.ErrRosterFormat, "roster is empty")
+	}
+	return rows, nil
+}
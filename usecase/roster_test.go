@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+)
+
+func TestParseRoster(t *testing.T) {
+	tests := []struct {
+		name   string
+		roster string
+		rows   []domain.RosterRow
+		code   int
+	}{
+		{
+			name:   "email only",
+			roster: "a@example.com\nb@example.com\n",
+			rows: []domain.RosterRow{
+				{Line: 1, Email: "a@example.com", Role: domain.MemberRole},
+				{Line: 2, Email: "b@example.com", Role: domain.MemberRole},
+			},
+		},
+		{
+			name:   "header is skipped",
+			roster: "Email,Role\na@example.com,admin\n",
+			rows: []domain.RosterRow{
+				{Line: 2, Email: "a@example.com", Role: domain.AdminRole},
+			},
+		},
+		{
+			name:   "header only on the first line",
+			roster: "a@example.com\nemail\n",
+			rows: []domain.RosterRow{
+				{Line: 1, Email: "a@example.com", Role: domain.MemberRole},
+				{Line: 2, Email: "email", Role: domain.MemberRole},
+			},
+		},
+		{
+			name:   "normalized email and role",
+			roster: "  A@Example.COM ,  Member \n",
+			rows: []domain.RosterRow{
+				{Line: 1, Email: "a@example.com", Role: domain.MemberRole},
+			},
+		},
+		{
+			name:   "empty role defaults to member",
+			roster: "a@example.com,\nb@example.com, ,extra\n",
+			rows: []domain.RosterRow{
+				{Line: 1, Email: "a@example.com", Role: domain.MemberRole},
+				{Line: 2, Email: "b@example.com", Role: domain.MemberRole},
+			},
+		},
+		{
+			name:   "unknown role is kept for the row check",
+			roster: "a@example.com,owner\n",
+			rows: []domain.RosterRow{
+				{Line: 1, Email: "a@example.com", Role: domain.OwnerRole},
+			},
+		},
+		{
+			name:   "blank lines are skipped",
+			roster: "a@example.com\n\n\nb@example.com\n",
+			rows: []domain.RosterRow{
+				{Line: 1, Email: "a@example.com", Role: domain.MemberRole},
+				{Line: 4, Email: "b@example.com", Role: domain.MemberRole},
+			},
+		},
+		{
+			name:   "quoted fields",
+			roster: "\"a@example.com\",\"admin\"\r\n",
+			rows: []domain.RosterRow{
+				{Line: 1, Email: "a@example.com", Role: domain.AdminRole},
+			},
+		},
+		{name: "empty", roster: "", code: errs.ErrRosterFormat},
+		{name: "header only", roster: "email,role\n", code: errs.ErrRosterFormat},
+		{name: "unterminated quote", roster: "\"a@example.com\n", code: errs.ErrRosterFormat},
+		{name: "too many rows", roster: strings.Repeat("a@example.com\n", constant.MaxRosterRow+1), code: errs.ErrRosterTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := parseRoster(strings.NewReader(test.roster))
+			if test.code != 0 {
+				if !errs.HasCode(err, test.code) {
+					t.Fatalf("parseRoster error = %v, want code %d", err, test.code)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("parseRoster returned error: %v", err)
+			}
+
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Errorf("parseRoster = %+v, want %+v", rows, test.rows)
+			}
+		})
+	}
+}
+
+func TestParseRosterMaxRows(t *testing.T) {
+	roster := "email\n" + strings.Repeat("a@example.com\n", constant.MaxRosterRow)
+
+	rows, err := parseRoster(strings.NewReader(roster))
+	if err != nil {
+		t.Fatalf("parseRoster returned error: %v", err)
+	}
+	if len(rows) != constant.MaxRosterRow {
+		t.Errorf("parseRoster returned %d rows, want %d", len(rows), constant.MaxRosterRow)
+	}
+}
@@ -32,12 +32,10 @@ func NewSessionUsecase(
 	}
 }
 
+// Sign always uses the first secret, the others are only kept to verify
+// the sessions signed before the secret was rotated
 func (u *sessionUsecase) Sign(id string) string {
-	hmac := hmac.New(sha256.New, []byte(u.cfg.Auth.Session.Secret))
-	hmac.Write([]byte(id))
-	regex := regexp.MustCompile(`=+$`)
-	signature := regex.ReplaceAllString(base64.StdEncoding.EncodeToString(hmac.Sum(nil)), "")
-	return u.cfg.Auth.Session.Prefix + ":" + id + "." + signature
+	return u.sign(id, u.cfg.Auth.Session.Secrets[0])
 }
 
 func (u *sessionUsecase) Unsign(header string) (string, error) {
@@ -45,16 +43,31 @@ func (u *sessionUsecase) Unsign(header string) (string, error) {
 		return "", errs.New(errs.ErrSessionPrefix, "prefix mismatch")
 	}
 
-	id := header[len(u.cfg.Auth.Session.Prefix)+1 : strings.LastIndex(header, ".")]
-	expectation := u.Sign(id)
+	separatorIndex := strings.LastIndex(header, ".")
+	if separatorIndex < len(u.cfg.Auth.Session.Prefix)+1 {
+		return "", errs.New(errs.ErrSignatureMismatch, "signature mismatch")
+	}
+	id := header[len(u.cfg.Auth.Session.Prefix)+1 : separatorIndex]
+
+	for _, secret := range u.cfg.Auth.Session.Secrets {
+		expectation := u.sign(id, secret)
 
-	isLengthMatch := len([]byte(header)) == len([]byte(expectation))
-	isInputMatch := subtle.ConstantTimeCompare([]byte(header), []byte(expectation)) == 1
+		isLengthMatch := len([]byte(header)) == len([]byte(expectation))
+		isInputMatch := subtle.ConstantTimeCompare([]byte(header), []byte(expectation)) == 1
 
-	if !isLengthMatch || !isInputMatch {
-		return "", errs.New(errs.ErrSignatureMismatch, "signature mismatch")
+		if isLengthMatch && isInputMatch {
+			return id, nil
+		}
 	}
-	return id, nil
+	return "", errs.New(errs.ErrSignatureMismatch, "signature mismatch")
+}
+
+func (u *sessionUsecase) sign(id string, secret string) string {
+	hmac := hmac.New(sha256.New, []byte(secret))
+	hmac.Write([]byte(id))
+	regex := regexp.MustCompile(`=+$`)
+	signature := regex.ReplaceAllString(base64.StdEncoding.EncodeToString(hmac.Sum(nil)), "")
+	return u.cfg.Auth.Session.Prefix + ":" + id + "." + signature
 }
 
 func (u *sessionUsecase) Create(
@@ -71,7 +84,7 @@ func (u *sessionUsecase) Create(
 	id := uuid.NewString()
 	signedId := u.Sign(id)
 	createdAt := time.Now()
-	expiredAt := createdAt.Add(time.Duration(u.cfg.Auth.Session.MaxAge) * time.Second)
+	expiredAt := u.getExpiredAt(createdAt, createdAt)
 
 	err := u.sessionRepository.Create(&domain.Session{
 		Id:        id,
@@ -89,7 +102,8 @@ func (u *sessionUsecase) Create(
 		Name:     constant.SessionCookieName,
 		Value:    signedId,
 		HTTPOnly: true,
-		Expires:  expiredAt,
+		// The cookie lives until the absolute expiry, the idle expiry is checked on the server
+		Expires: u.getMaxExpiredAt(createdAt),
 	}
 	return cookie, nil
 }
@@ -131,9 +145,71 @@ func (u *sessionUsecase) Validate(header string) (*domain.Session, error) {
 		return nil, errs.New(errs.ErrInvalidSession, "session is invalid")
 	}
 
-	if !time.Now().Before(session.ExpiredAt) {
+	now := time.Now()
+	if !now.Before(session.ExpiredAt) {
 		return nil, errs.New(errs.ErrSessionExpired, "session expired")
 	}
 
+	// Extend the session on activity, skipping the write when it would barely move
+	expiredAt := u.getExpiredAt(session.CreatedAt, now)
+	if expiredAt.Sub(session.ExpiredAt) >= constant.SessionRefreshInterval {
+		if err := u.sessionRepository.UpdateExpiredAt(session.Id, expiredAt); err != nil {
+			return nil, errs.New(errs.ErrUpdateSession, "cannot extend session", err)
+		}
+		session.ExpiredAt = expiredAt
+	}
+
 	return session, nil
 }
+
+func (u *sessionUsecase) List(userId string, currentId string) ([]domain.Session, error) {
+	sessions, err := u.sessionRepository.ListByUserId(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetSession, "cannot list session of user id %s", userId, err)
+	}
+
+	now := time.Now()
+	liveSessions := make([]domain.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if !now.Before(session.ExpiredAt) {
+			continue
+		}
+		session.IsCurrent = session.Id == currentId
+		liveSessions = append(liveSessions, session)
+	}
+	return liveSessions, nil
+}
+
+func (u *sessionUsecase) Revoke(userId string, id string) error {
+	session, err := u.sessionRepository.Get(id)
+	if err != nil {
+		return errs.New(errs.ErrGetSession, "cannot get session to revoke", err)
+	} else if session == nil || session.UserId != userId {
+		return errs.New(errs.ErrSessionNotFound, "session not found")
+	}
+
+	if err := u.sessionRepository.Delete(id); err != nil {
+		return errs.New(errs.ErrDeleteSession, "cannot revoke session of user id %s", userId, err)
+	}
+	return nil
+}
+
+func (u *sessionUsecase) RevokeOthers(userId string, currentId string) error {
+	if err := u.sessionRepository.DeleteByUserIdExcept(userId, currentId); err != nil {
+		return errs.New(errs.ErrDeleteSession, "cannot revoke other session of user id %s", userId, err)
+	}
+	return nil
+}
+
+// getExpiredAt returns the idle expiry from now, capped at the absolute expiry
+func (u *sessionUsecase) getExpiredAt(createdAt time.Time, now time.Time) time.Time {
+	expiredAt := now.Add(time.Duration(u.cfg.Auth.Session.IdleAge) * time.Second)
+	if maxExpiredAt := u.getMaxExpiredAt(createdAt); expiredAt.After(maxExpiredAt) {
+		return maxExpiredAt
+	}
+	return expiredAt
+}
+
+func (u *sessionUsecase) getMaxExpiredAt(createdAt time.Time) time.Time {
+	return createdAt.Add(time.Duration(u.cfg.Auth.Session.MaxAge) * time.Second)
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/constant"
+)
+
+const (
+	testIdleAge = 60 * 60
+	testMaxAge  = 24 * 60 * 60
+)
+
+// fakeSessionRepository keeps the sessions in memory, only Get and UpdateExpiredAt are used by Validate
+type fakeSessionRepository struct {
+	domain.SessionRepository
+	sessions map[string]*domain.Session
+	updated  int
+}
+
+func (r *fakeSessionRepository) Get(id string) (*domain.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *fakeSessionRepository) UpdateExpiredAt(id string, expiredAt time.Time) error {
+	r.sessions[id].ExpiredAt = expiredAt
+	r.updated++
+	return nil
+}
+
+func newTestSessionUsecase(secrets ...string) (*sessionUsecase, *fakeSessionRepository) {
+	cfg := &config.Config{}
+	cfg.Auth.Session = config.ConfigAuthSession{
+		Prefix:  "codern",
+		Secrets: secrets,
+		IdleAge: testIdleAge,
+		MaxAge:  testMaxAge,
+	}
+	repository := &fakeSessionRepository{sessions: make(map[string]*domain.Session)}
+	return NewSessionUsecase(cfg, repository).(*sessionUsecase), repository
+}
+
+func TestSessionUnsign(t *testing.T) {
+	old, _ := newTestSessionUsecase("old")
+	other, _ := newTestSessionUsecase("other")
+	rotated, _ := newTestSessionUsecase("new", "old")
+
+	signed := old.Sign("id")
+
+	tests := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"signed by the current secret", rotated.Sign("id"), 0},
+		{"signed by a rotated secret", signed, 0},
+		{"signed by an unknown secret", other.Sign("id"), errs.ErrSignatureMismatch},
+		{"tampered id", strings.Replace(signed, ":id.", ":di.", 1), errs.ErrSignatureMismatch},
+		{"tampered signature", signed[:len(signed)-1], errs.ErrSignatureMismatch},
+		{"no signature", "codern:id", errs.ErrSignatureMismatch},
+		{"other prefix", strings.Replace(signed, "codern:", "other:", 1), errs.ErrSessionPrefix},
+		{"empty", "", errs.ErrSessionPrefix},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := rotated.Unsign(test.header)
+			if test.code == 0 {
+				if err != nil {
+					t.Fatalf("Unsign returned error: %v", err)
+				}
+				if id != "id" {
+					t.Errorf("Unsign id = %s, want id", id)
+				}
+			} else if !errs.HasCode(err, test.code) {
+				t.Errorf("Unsign error = %v, want code %d", err, test.code)
+			}
+		})
+	}
+}
+
+func TestSessionSignUsesFirstSecret(t *testing.T) {
+	current, _ := newTestSessionUsecase("new")
+	rotated, _ := newTestSessionUsecase("new", "old")
+
+	if rotated.Sign("id") != current.Sign("id") {
+		t.Error("Sign does not use the first secret")
+	}
+}
+
+func TestSessionGetExpiredAt(t *testing.T) {
+	u, _ := newTestSessionUsecase("secret")
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	idleAge := testIdleAge * time.Second
+	maxExpiredAt := createdAt.Add(testMaxAge * time.Second)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"at sign in", createdAt, createdAt.Add(idleAge)},
+		{"on activity", createdAt.Add(time.Hour), createdAt.Add(time.Hour + idleAge)},
+		{"capped near the absolute expiry", maxExpiredAt.Add(-time.Minute), maxExpiredAt},
+		{"idle expiry right at the absolute expiry", maxExpiredAt.Add(-idleAge), maxExpiredAt},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := u.getExpiredAt(createdAt, test.now); !got.Equal(test.want) {
+				t.Errorf("getExpiredAt = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSessionValidate(t *testing.T) {
+	now := time.Now()
+	idleAge := testIdleAge * time.Second
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		expiredAt time.Time
+		code      int
+		isUpdated bool
+		want      time.Time
+	}{
+		{
+			name:      "extended on activity",
+			createdAt: now.Add(-time.Hour),
+			expiredAt: now.Add(time.Minute),
+			isUpdated: true,
+			want:      now.Add(idleAge),
+		},
+		{
+			name:      "recently extended",
+			createdAt: now.Add(-time.Hour),
+			expiredAt: now.Add(idleAge - constant.SessionRefreshInterval/2),
+			want:      now.Add(idleAge - constant.SessionRefreshInterval/2),
+		},
+		{
+			name:      "capped at the absolute expiry",
+			createdAt: now.Add(-testMaxAge*time.Second + 30*time.Minute),
+			expiredAt: now.Add(time.Minute),
+			isUpdated: true,
+			want:      now.Add(30 * time.Minute),
+		},
+		{
+			name:      "idle expired",
+			createdAt: now.Add(-2 * time.Hour),
+			expiredAt: now.Add(-time.Second),
+			code:      errs.ErrSessionExpired,
+		},
+		{
+			name:      "absolute expired",
+			createdAt: now.Add(-testMaxAge * time.Second),
+			expiredAt: now,
+			code:      errs.ErrSessionExpired,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, repository := newTestSessionUsecase("secret")
+			repository.sessions["id"] = &domain.Session{
+				Id:        "id",
+				UserId:    "user",
+				CreatedAt: test.createdAt,
+				ExpiredAt: test.expiredAt,
+			}
+
+			session, err := u.Validate(u.Sign("id"))
+			if test.code != 0 {
+				if !errs.HasCode(err, test.code) {
+					t.Fatalf("Validate error = %v, want code %d", err, test.code)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Validate returned error: %v", err)
+			}
+
+			if isUpdated := repository.updated > 0; isUpdated != test.isUpdated {
+				t.Errorf("Validate updated = %v, want %v", isUpdated, test.isUpdated)
+			}
+			// The expiry is computed from the time of the call, allow for the test to run
+			if diff := session.ExpiredAt.Sub(test.want); diff < 0 || diff > time.Second {
+				t.Errorf("Validate expiredAt = %v, want %v", session.ExpiredAt, test.want)
+			}
+			if !repository.sessions["id"].ExpiredAt.Equal(session.ExpiredAt) {
+				t.Errorf("stored expiredAt = %v, want %v", repository.sessions["id"].ExpiredAt, session.ExpiredAt)
+			}
+		})
+	}
+}
+
+func TestSessionValidateUnknown(t *testing.T) {
+	u, _ := newTestSessionUsecase("secret")
+
+	if _, err := u.Validate(u.Sign("unknown")); !errs.HasCode(err, errs.ErrInvalidSession) {
+		t.Errorf("Validate error = %v, want code %d", err, errs.ErrInvalidSession)
+	}
+}
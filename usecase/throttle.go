@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+)
+
+type signInThrottleUsecase struct {
+	signInAttemptRepository domain.SignInAttemptRepository
+}
+
+func NewSignInThrottleUsecase(
+	signInAttemptRepository domain.SignInAttemptRepository,
+) domain.SignInThrottleUsecase {
+	return &signInThrottleUsecase{
+		signInAttemptRepository: signInAttemptRepository,
+	}
+}
+
+// Check returns ErrSignInLocked when the email or the ip has too many failures
+// within the sliding window. The lockout starts at SignInLockoutBase and doubles
+// with every further failure up to SignInLockoutMax.
+func (u *signInThrottleUsecase) Check(email string, ipAddress string, userAgent string) error {
+	since := time.Now().Add(-constant.SignInThrottleWindow)
+
+	emailStat, err := u.signInAttemptRepository.GetFailureStatByEmail(email, since)
+	if err != nil {
+		return errs.New(errs.ErrCheckSignInAttempt, "cannot get sign in failure of email %s", email, err)
+	}
+	ipStat, err := u.signInAttemptRepository.GetFailureStatByIp(ipAddress, since)
+	if err != nil {
+		return errs.New(errs.ErrCheckSignInAttempt, "cannot get sign in failure of ip %s", ipAddress, err)
+	}
+
+	lockedUntil := getLockedUntil(emailStat, constant.SignInEmailMaxFailure)
+	if ipLockedUntil := getLockedUntil(ipStat, constant.SignInIpMaxFailure); ipLockedUntil != nil &&
+		(lockedUntil == nil || ipLockedUntil.After(*lockedUntil)) {
+		lockedUntil = ipLockedUntil
+	}
+	if lockedUntil == nil {
+		return nil
+	}
+
+	if err := u.RecordFailure(email, ipAddress, userAgent, domain.LockedFailure); err != nil {
+		return errs.New(errs.SameCode, "cannot record locked sign in attempt", err)
+	}
+	return errs.New(
+		errs.ErrSignInLocked,
+		"too many failed sign in attempts, try again in %s",
+		time.Until(*lockedUntil).Round(time.Second),
+	)
+}
+
+func (u *signInThrottleUsecase) RecordFailure(
+	email string,
+	ipAddress string,
+	userAgent string,
+	reason domain.SignInFailureReason,
+) error {
+	return u.record(email, ipAddress, userAgent, false, &reason)
+}
+
+func (u *signInThrottleUsecase) RecordSuccess(email string, ipAddress string, userAgent string) error {
+	return u.record(email, ipAddress, userAgent, true, nil)
+}
+
+func (u *signInThrottleUsecase) ListFailure(filter *domain.SignInAttemptFilter) ([]domain.SignInAttempt, error) {
+	attempts, err := u.signInAttemptRepository.ListFailure(filter)
+	if err != nil {
+		return nil, errs.New(errs.ErrListSignInAttempt, "cannot list sign in failure", err)
+	}
+	return attempts, nil
+}
+
+func (u *signInThrottleUsecase) record(
+	email string,
+	ipAddress string,
+	userAgent string,
+	isSuccess bool,
+	reason *domain.SignInFailureReason,
+) error {
+	attempt := &domain.SignInAttempt{
+		Id:        generator.GetId(),
+		Email:     email,
+		IpAddress: ipAddress,
+		UserAgent: userAgent,
+		IsSuccess: isSuccess,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if err := u.signInAttemptRepository.Create(attempt); err != nil {
+		return errs.New(errs.ErrCheckSignInAttempt, "cannot create sign in attempt of email %s", email, err)
+	}
+	return nil
+}
+
+func getLockedUntil(stat *domain.SignInFailureStat, maxFailure int) *time.Time {
+	if stat.Count < maxFailure || stat.LastFailedAt == nil {
+		return nil
+	}
+
+	lockout := constant.SignInLockoutMax
+	if exponent := stat.Count - maxFailure; exponent < 16 {
+		lockout = min(constant.SignInLockoutBase<<exponent, constant.SignInLockoutMax)
+	}
+
+	lockedUntil := stat.LastFailedAt.Add(lockout)
+	if !time.Now().Before(lockedUntil) {
+		return nil
+	}
+	return &lockedUntil
+}
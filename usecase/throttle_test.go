@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/internal/constant"
+)
+
+func TestGetLockedUntil(t *testing.T) {
+	const maxFailure = 5
+	now := time.Now()
+	recently := now.Add(-time.Second)
+
+	tests := []struct {
+		name         string
+		count        int
+		lastFailedAt *time.Time
+		lockout      time.Duration // Zero when not locked
+	}{
+		{"below the limit", maxFailure - 1, &recently, 0},
+		{"no failure time", maxFailure, nil, 0},
+		{"at the limit", maxFailure, &recently, constant.SignInLockoutBase},
+		{"one over the limit", maxFailure + 1, &recently, 2 * constant.SignInLockoutBase},
+		{"two over the limit", maxFailure + 2, &recently, 4 * constant.SignInLockoutBase},
+		{"capped", maxFailure + 10, &recently, constant.SignInLockoutMax},
+		{"shift overflow", maxFailure + 64, &recently, constant.SignInLockoutMax},
+		{"lockout passed", maxFailure, timePtr(now.Add(-constant.SignInLockoutBase)), 0},
+		{"longer lockout not passed", maxFailure + 1, timePtr(now.Add(-constant.SignInLockoutBase)), 2 * constant.SignInLockoutBase},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stat := &domain.SignInFailureStat{Count: test.count, LastFailedAt: test.lastFailedAt}
+			lockedUntil := getLockedUntil(stat, maxFailure)
+
+			if test.lockout == 0 {
+				if lockedUntil != nil {
+					t.Fatalf("getLockedUntil = %v, want not locked", *lockedUntil)
+				}
+				return
+			}
+			if lockedUntil == nil {
+				t.Fatal("getLockedUntil = not locked, want locked")
+			}
+			if want := test.lastFailedAt.Add(test.lockout); !lockedUntil.Equal(want) {
+				t.Errorf("getLockedUntil = %v, want %v", *lockedUntil, want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
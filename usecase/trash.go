@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/platform"
+)
+
+type trashUsecase struct {
+	seaweedfs            *platform.SeaweedFs
+	trashRepository      domain.TrashRepository
+	workspaceRepository  domain.WorkspaceRepository
+	assignmentRepository domain.AssignmentRepository
+	workspaceUsecase     domain.WorkspaceUsecase
+	quotaUsecase         domain.QuotaUsecase
+}
+
+func NewTrashUsecase(
+	seaweedfs *platform.SeaweedFs,
+	trashRepository domain.TrashRepository,
+	workspaceRepository domain.WorkspaceRepository,
+	assignmentRepository domain.AssignmentRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+	quotaUsecase domain.QuotaUsecase,
+) domain.TrashUsecase {
+	return &trashUsecase{
+		seaweedfs:            seaweedfs,
+		trashRepository:      trashRepository,
+		workspaceRepository:  workspaceRepository,
+		assignmentRepository: assignmentRepository,
+		workspaceUsecase:     workspaceUsecase,
+		quotaUsecase:         quotaUsecase,
+	}
+}
+
+// List returns what the user deleted from the workspaces they own and can still restore
+func (u *trashUsecase) List(userId string) (*domain.Trash, error) {
+	since := time.Now().Add(-constant.TrashRetention)
+
+	workspaces, err := u.trashRepository.ListWorkspace(userId, since)
+	if err != nil {
+		return nil, errs.New(errs.ErrTrash, "cannot list trashed workspace of user id %s", userId, err)
+	}
+	for i := range workspaces {
+		workspaces[i].PurgeAt = workspaces[i].DeletedAt.Add(constant.TrashRetention)
+	}
+
+	assignments, err := u.trashRepository.ListAssignment(userId, since)
+	if err != nil {
+		return nil, errs.New(errs.ErrTrash, "cannot list trashed assignment of user id %s", userId, err)
+	}
+	for i := range assignments {
+		assignments[i].PurgeAt = assignments[i].DeletedAt.Add(constant.TrashRetention)
+	}
+
+	return &domain.Trash{Workspaces: workspaces, Assignments: assignments}, nil
+}
+
+func (u *trashUsecase) RestoreWorkspace(userId string, workspaceId int) error {
+	if err := u.checkOwner(userId, workspaceId); err != nil {
+		return err
+	}
+
+	workspace, err := u.trashRepository.GetWorkspace(workspaceId)
+	if err != nil {
+		return errs.New(errs.ErrTrash, "cannot get trashed workspace id %d", workspaceId, err)
+	} else if workspace == nil {
+		return errs.New(errs.ErrTrashNotFound, "workspace id %d is not in trash", workspaceId)
+	} else if isExpired(workspace.DeletedAt) {
+		return errs.New(errs.ErrTrashExpired, "workspace id %d is past the retention period", workspaceId)
+	}
+
+	// A restored workspace counts toward the owner quota again
+	maxOwned, err := u.quotaUsecase.CheckOwnedWorkspace(userId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot restore workspace id %d", workspaceId, err)
+	}
+
+	isRestored, err := u.workspaceRepository.Restore(workspaceId, maxOwned)
+	if err != nil {
+		return errs.New(errs.ErrRestoreWorkspace, "cannot restore workspace id %d", workspaceId, err)
+	} else if !isRestored {
+		return errs.New(errs.ErrWorkspaceQuota, "user id %s can own up to %d workspaces", userId, maxOwned)
+	}
+	return nil
+}
+
+func (u *trashUsecase) RestoreAssignment(userId string, workspaceId int, assignmentId int) error {
+	if err := u.checkOwner(userId, workspaceId); err != nil {
+		return err
+	}
+
+	assignment, err := u.trashRepository.GetAssignment(assignmentId)
+	if err != nil {
+		return errs.New(errs.ErrTrash, "cannot get trashed assignment id %d", assignmentId, err)
+	} else if assignment == nil || assignment.WorkspaceId != workspaceId {
+		return errs.New(errs.ErrTrashNotFound, "assignment id %d is not in trash of workspace id %d", assignmentId, workspaceId)
+	} else if isExpired(assignment.DeletedAt) {
+		return errs.New(errs.ErrTrashExpired, "assignment id %d is past the retention period", assignmentId)
+	}
+
+	workspace, err := u.trashRepository.GetWorkspace(workspaceId)
+	if err != nil {
+		return errs.New(errs.ErrTrash, "cannot get trashed workspace id %d", workspaceId, err)
+	} else if workspace != nil {
+		return errs.New(errs.ErrWorkspaceNotFound, "workspace id %d must be restored before its assignments", workspaceId)
+	}
+
+	maxAssignments, err := u.quotaUsecase.CheckAssignment(workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot restore assignment id %d", assignmentId, err)
+	}
+
+	isRestored, err := u.assignmentRepository.Restore(assignmentId, maxAssignments)
+	if err != nil {
+		return errs.New(errs.ErrRestoreAssignment, "cannot restore assignment id %d", assignmentId, err)
+	} else if !isRestored {
+		return errs.New(
+			errs.ErrAssignmentQuota,
+			"workspace id %d has reached the limit of %d assignments", workspaceId, maxAssignments,
+		)
+	}
+	return nil
+}
+
+// Purge hard deletes the items past the retention period with their files,
+// a failing item is left for the next run and does not stop the others
+func (u *trashUsecase) Purge() (int, error) {
+	before := time.Now().Add(-constant.TrashRetention)
+
+	workspaces, err := u.trashRepository.ListExpiredWorkspace(before)
+	if err != nil {
+		return 0, errs.New(errs.ErrTrash, "cannot list expired trashed workspace", err)
+	}
+	assignments, err := u.trashRepository.ListExpiredAssignment(before)
+	if err != nil {
+		return 0, errs.New(errs.ErrTrash, "cannot list expired trashed assignment", err)
+	}
+
+	purged := 0
+	var purgeErr error
+
+	for _, workspace := range workspaces {
+		isPurged, err := u.trashRepository.PurgeWorkspace(workspace.Id, before)
+		if err != nil {
+			purgeErr = errs.New(errs.ErrTrash, "cannot purge workspace id %d", workspace.Id, err)
+			continue
+		} else if !isPurged {
+			continue
+		}
+		purged++
+
+		path := fmt.Sprintf("/workspaces/%d/", workspace.Id)
+		if err := u.seaweedfs.DeleteDirectory(path); err != nil {
+			purgeErr = errs.New(errs.ErrFileSystem, "cannot delete files of purged workspace id %d", workspace.Id, err)
+		}
+	}
+
+	// The assignments of a workspace purged above are already gone and skipped
+	for _, assignment := range assignments {
+		isPurged, err := u.trashRepository.PurgeAssignment(assignment.Id, before)
+		if err != nil {
+			purgeErr = errs.New(errs.ErrTrash, "cannot purge assignment id %d", assignment.Id, err)
+			continue
+		} else if !isPurged {
+			continue
+		}
+		purged++
+
+		path := fmt.Sprintf("/workspaces/%d/assignments/%d/", assignment.WorkspaceId, assignment.Id)
+		if err := u.seaweedfs.DeleteDirectory(path); err != nil {
+			purgeErr = errs.New(errs.ErrFileSystem, "cannot delete files of purged assignment id %d", assignment.Id, err)
+		}
+	}
+
+	return purged, purgeErr
+}
+
+func (u *trashUsecase) checkOwner(userId string, workspaceId int) error {
+	isAuthorized, err := u.workspaceUsecase.CheckPermRole(userId, workspaceId, []domain.WorkspaceRole{domain.OwnerRole})
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot check permission of user id %s to restore", userId, err)
+	} else if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "user id %s is not the owner of workspace id %d", userId, workspaceId)
+	}
+	return nil
+}
+
+func isExpired(deletedAt time.Time) bool {
+	return deletedAt.Add(constant.TrashRetention).Before(time.Now())
+}
+
+// discardWorkspace hard deletes a workspace a failed clone or import left half filled,
+// it skips the trash as the user never had the workspace. The cause is returned
+// along with the error of the cleanup if the workspace cannot be removed
+func discardWorkspace(
+	seaweedfs *platform.SeaweedFs,
+	trashRepository domain.TrashRepository,
+	workspaceId int,
+	cause error,
+) error {
+	if err := trashRepository.DeleteWorkspace(workspaceId); err != nil {
+		return errors.Join(cause, errs.New(errs.ErrTrash, "cannot discard workspace id %d", workspaceId, err))
+	}
+
+	path := fmt.Sprintf("/workspaces/%d/", workspaceId)
+	if err := seaweedfs.DeleteDirectory(path); err != nil {
+		return errors.Join(cause, errs.New(errs.ErrFileSystem, "cannot delete files of discarded workspace id %d", workspaceId, err))
+	}
+	return cause
+}
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/internal/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type twoFactorUsecase struct {
+	cfg                 *config.Config
+	twoFactorRepository domain.TwoFactorRepository
+	userRepository      domain.UserRepository
+}
+
+func NewTwoFactorUsecase(
+	cfg *config.Config,
+	twoFactorRepository domain.TwoFactorRepository,
+	userRepository domain.UserRepository,
+) domain.TwoFactorUsecase {
+	return &twoFactorUsecase{
+		cfg:                 cfg,
+		twoFactorRepository: twoFactorRepository,
+		userRepository:      userRepository,
+	}
+}
+
+func (u *twoFactorUsecase) Enroll(userId string) (*domain.TwoFactorEnrollment, error) {
+	user, err := u.getUser(userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get user to enroll two-factor", err)
+	} else if user.Provider != domain.SelfAuth {
+		return nil, errs.New(errs.ErrTwoFactorProvider, "two-factor is only available for %s account", domain.SelfAuth)
+	} else if user.IsTwoFactorEnabled {
+		return nil, errs.New(errs.ErrTwoFactorEnabled, "two-factor of user id %s is already enabled", userId)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errs.New(errs.ErrUpdateTwoFactor, "cannot generate totp secret", err)
+	}
+
+	// The secret is pending until the user proves it was added to an authenticator
+	user.TotpSecret = &secret
+	if err := u.userRepository.Update(user); err != nil {
+		return nil, errs.New(errs.ErrUpdateTwoFactor, "cannot save totp secret of user id %s", userId, err)
+	}
+
+	return &domain.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningUri: totp.GetProvisioningUri(u.cfg.Metadata.Name, user.Email, secret),
+	}, nil
+}
+
+func (u *twoFactorUsecase) Activate(userId string, code string) ([]string, error) {
+	user, err := u.getUser(userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get user to activate two-factor", err)
+	} else if user.IsTwoFactorEnabled {
+		return nil, errs.New(errs.ErrTwoFactorEnabled, "two-factor of user id %s is already enabled", userId)
+	} else if user.TotpSecret == nil {
+		return nil, errs.New(errs.ErrTwoFactorNotEnabled, "two-factor of user id %s is not enrolled", userId)
+	}
+
+	isValid, err := u.verifyTotp(user, code)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot verify code to activate two-factor", err)
+	} else if !isValid {
+		return nil, errs.New(errs.ErrTwoFactorCode, "two-factor code is invalid")
+	}
+
+	recoveryCodes, err := u.createRecoveryCodes(userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot activate two-factor", err)
+	}
+
+	user.IsTwoFactorEnabled = true
+	if err := u.userRepository.Update(user); err != nil {
+		return nil, errs.New(errs.ErrUpdateTwoFactor, "cannot enable two-factor of user id %s", userId, err)
+	}
+	return recoveryCodes, nil
+}
+
+func (u *twoFactorUsecase) Disable(userId string, password string, code string) error {
+	user, err := u.getUser(userId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get user to disable two-factor", err)
+	} else if !user.IsTwoFactorEnabled {
+		return errs.New(errs.ErrTwoFactorNotEnabled, "two-factor of user id %s is not enabled", userId)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return errs.New(errs.ErrUserPassword, "password is incorrect", err)
+	}
+
+	isValid, err := u.verifyCode(user, code)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot verify code to disable two-factor", err)
+	} else if !isValid {
+		return errs.New(errs.ErrTwoFactorCode, "two-factor code is invalid")
+	}
+
+	user.TotpSecret = nil
+	user.IsTwoFactorEnabled = false
+	if err := u.userRepository.Update(user); err != nil {
+		return errs.New(errs.ErrUpdateTwoFactor, "cannot disable two-factor of user id %s", userId, err)
+	}
+
+	if err := u.twoFactorRepository.DeleteRecoveryCodes(userId); err != nil {
+		return errs.New(errs.ErrUpdateTwoFactor, "cannot delete recovery code of user id %s", userId, err)
+	}
+	return nil
+}
+
+func (u *twoFactorUsecase) RegenerateRecoveryCodes(userId string, code string) ([]string, error) {
+	user, err := u.getUser(userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get user to regenerate recovery code", err)
+	} else if !user.IsTwoFactorEnabled {
+		return nil, errs.New(errs.ErrTwoFactorNotEnabled, "two-factor of user id %s is not enabled", userId)
+	}
+
+	isValid, err := u.verifyTotp(user, code)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot verify code to regenerate recovery code", err)
+	} else if !isValid {
+		return nil, errs.New(errs.ErrTwoFactorCode, "two-factor code is invalid")
+	}
+
+	recoveryCodes, err := u.createRecoveryCodes(userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot regenerate recovery code", err)
+	}
+	return recoveryCodes, nil
+}
+
+// CreateChallenge issues a challenge to pass with a code, the number of challenges is capped
+// so the attempt limit of a single challenge cannot be bypassed by asking for more of them
+func (u *twoFactorUsecase) CreateChallenge(userId string) (*domain.TwoFactorChallenge, error) {
+	count, err := u.twoFactorRepository.CountChallenge(userId, time.Now().Add(-constant.TwoFactorChallengeMaxAge))
+	if err != nil {
+		return nil, errs.New(errs.ErrGetChallenge, "cannot count two-factor challenge of user id %s", userId, err)
+	} else if count >= constant.TwoFactorChallengeMaxPerUser {
+		return nil, errs.New(errs.ErrSignInLocked, "too many two-factor challenges, try again in %s", constant.TwoFactorChallengeMaxAge)
+	}
+
+	token, err := generator.RandToken(32)
+	if err != nil {
+		return nil, errs.New(errs.ErrCreateChallenge, "cannot generate two-factor challenge token", err)
+	}
+
+	createdAt := time.Now()
+	challenge := &domain.TwoFactorChallenge{
+		Id:        generator.HashToken(token),
+		UserId:    userId,
+		Attempt:   0,
+		CreatedAt: createdAt,
+		ExpiredAt: createdAt.Add(constant.TwoFactorChallengeMaxAge),
+	}
+	if err := u.twoFactorRepository.CreateChallenge(challenge); err != nil {
+		return nil, errs.New(errs.ErrCreateChallenge, "cannot create two-factor challenge for user id %s", userId, err)
+	}
+
+	challenge.Token = token
+	return challenge, nil
+}
+
+func (u *twoFactorUsecase) GetChallenge(token string) (*domain.TwoFactorChallenge, error) {
+	challenge, err := u.twoFactorRepository.GetChallenge(generator.HashToken(token))
+	if err != nil {
+		return nil, errs.New(errs.ErrGetChallenge, "cannot get two-factor challenge", err)
+	} else if challenge == nil {
+		return nil, errs.New(errs.ErrTwoFactorChallenge, "two-factor challenge is invalid")
+	}
+	return challenge, nil
+}
+
+func (u *twoFactorUsecase) VerifyChallenge(token string, code string) (string, error) {
+	id := generator.HashToken(token)
+
+	challenge, err := u.GetChallenge(token)
+	if err != nil {
+		return "", err
+	}
+
+	// The attempt is counted before the code is checked so concurrent guesses cannot exceed the limit
+	isCounted, err := u.twoFactorRepository.IncreaseChallengeAttempt(
+		id, constant.TwoFactorChallengeMaxAttempt, time.Now(),
+	)
+	if err != nil {
+		return "", errs.New(errs.ErrUpdateChallenge, "cannot increase two-factor challenge attempt", err)
+	} else if !isCounted {
+		// An exhausted challenge is kept until it expires so it still counts toward the per user cap
+		return "", errs.New(errs.ErrTwoFactorChallenge, "two-factor challenge expired, please sign in again")
+	}
+
+	user, err := u.getUser(challenge.UserId)
+	if err != nil {
+		return "", errs.New(errs.SameCode, "cannot get user to verify two-factor challenge", err)
+	}
+
+	isValid, err := u.verifyCode(user, code)
+	if err != nil {
+		return "", errs.New(errs.SameCode, "cannot verify two-factor challenge", err)
+	} else if !isValid {
+		return "", errs.New(errs.ErrTwoFactorCode, "two-factor code is invalid")
+	}
+
+	// Only the request deleting the challenge passes it, so one challenge cannot create two sessions
+	isDeleted, err := u.twoFactorRepository.DeleteChallenge(id)
+	if err != nil {
+		return "", errs.New(errs.ErrDeleteChallenge, "cannot delete two-factor challenge", err)
+	} else if !isDeleted {
+		return "", errs.New(errs.ErrTwoFactorChallenge, "two-factor challenge is already used")
+	}
+	return user.Id, nil
+}
+
+func (u *twoFactorUsecase) getUser(userId string) (*domain.User, error) {
+	user, err := u.userRepository.Get(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetUser, "cannot get user id %s", userId, err)
+	} else if user == nil {
+		return nil, errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	}
+	return user, nil
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code
+func (u *twoFactorUsecase) verifyCode(user *domain.User, code string) (bool, error) {
+	isValid, err := u.verifyTotp(user, code)
+	if err != nil {
+		return false, err
+	} else if isValid {
+		return true, nil
+	}
+
+	recoveryCode := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(recoveryCode) != constant.RecoveryCodeLength {
+		return false, nil
+	}
+
+	isUsed, err := u.twoFactorRepository.UseRecoveryCode(user.Id, generator.HashToken(recoveryCode), time.Now())
+	if err != nil {
+		return false, errs.New(errs.ErrUpdateTwoFactor, "cannot use recovery code of user id %s", user.Id, err)
+	}
+	return isUsed, nil
+}
+
+// verifyTotp accepts a code only once, a code whose time step is not after
+// the last accepted one is rejected even though it is still in the window
+func (u *twoFactorUsecase) verifyTotp(user *domain.User, code string) (bool, error) {
+	if user.TotpSecret == nil {
+		return false, nil
+	}
+
+	step, isValid := totp.Validate(*user.TotpSecret, code, time.Now())
+	if !isValid {
+		return false, nil
+	}
+
+	isUsed, err := u.twoFactorRepository.UseTotpStep(user.Id, step)
+	if err != nil {
+		return false, errs.New(errs.ErrUpdateTwoFactor, "cannot use totp step of user id %s", user.Id, err)
+	}
+	return isUsed, nil
+}
+
+// createRecoveryCodes replaces all recovery codes of the user and returns the plain codes,
+// which are shown only once since only the hashes are stored
+func (u *twoFactorUsecase) createRecoveryCodes(userId string) ([]string, error) {
+	plainCodes := make([]string, constant.RecoveryCodeCount)
+	codes := make([]domain.RecoveryCode, constant.RecoveryCodeCount)
+
+	for i := range codes {
+		code, err := generator.RandSecureStr(constant.RecoveryCodeLength)
+		if err != nil {
+			return nil, errs.New(errs.ErrUpdateTwoFactor, "cannot generate recovery code", err)
+		}
+		half := constant.RecoveryCodeLength / 2
+		plainCodes[i] = code[:half] + "-" + code[half:]
+		codes[i] = domain.RecoveryCode{
+			Id:        generator.HashToken(code),
+			UserId:    userId,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	if err := u.twoFactorRepository.CreateRecoveryCodes(userId, codes); err != nil {
+		return nil, errs.New(errs.ErrUpdateTwoFactor, "cannot create recovery code of user id %s", userId, err)
+	}
+	return plainCodes, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/mail"
 	"time"
+	"unicode"
 
 	"github.com/codern-org/codern/domain"
 	errs "github.com/codern-org/codern/domain/error"
@@ -48,22 +49,31 @@ func (u *userUsecase) Create(email string, password string) (*domain.User, error
 		)
 	}
 
+	if err := validatePassword(password); err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create user with email %s", email, err)
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
 	if err != nil {
 		return nil, errs.New(errs.ErrCreateUser, "cannot create user with invalid password", err)
 	}
 
-	// TODO: profile generation
+	id := uuid.NewString()
+	profileUrl := getUserProfileUrl(id)
+	if err := uploadIdenticon(u.seaweedfs, id, profileUrl); err != nil {
+		return nil, errs.New(errs.ErrCreateUser, "cannot generate profile of user with email %s", email, err)
+	}
 
 	user = &domain.User{
-		Id:          uuid.NewString(),
-		Email:       email,
-		Password:    string(hashedPassword),
-		DisplayName: email,
-		ProfileUrl:  "",
-		Type:        domain.FreeAccount,
-		Provider:    domain.SelfAuth,
-		CreatedAt:   time.Now(),
+		Id:              id,
+		Email:           email,
+		Password:        string(hashedPassword),
+		DisplayName:     email,
+		ProfileUrl:      profileUrl,
+		Type:            domain.FreeAccount,
+		Provider:        domain.SelfAuth,
+		IsEmailVerified: false,
+		CreatedAt:       time.Now(),
 	}
 
 	if err = u.userRepository.Create(user); err != nil {
@@ -72,22 +82,58 @@ func (u *userUsecase) Create(email string, password string) (*domain.User, error
 	return user, nil
 }
 
-func (u *userUsecase) CreateFromGoogle(id string, email string, name string) (*domain.User, error) {
-	// TODO: profile generation
+func (u *userUsecase) CreateFromGoogle(
+	id string,
+	email string,
+	name string,
+	isEmailVerified bool,
+) (*domain.User, error) {
+	user, err := u.createFromProvider(domain.GoogleAuth, email, name, isEmailVerified)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create user from google auth", err)
+	}
+	return user, nil
+}
+
+func (u *userUsecase) CreateFromOidc(
+	provider domain.AuthProvider,
+	email string,
+	name string,
+	isEmailVerified bool,
+) (*domain.User, error) {
+	user, err := u.createFromProvider(provider, email, name, isEmailVerified)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create user from oidc auth", err)
+	}
+	return user, nil
+}
+
+func (u *userUsecase) createFromProvider(
+	provider domain.AuthProvider,
+	email string,
+	name string,
+	isEmailVerified bool,
+) (*domain.User, error) {
+	id := uuid.NewString()
+	profileUrl := getUserProfileUrl(id)
+	if err := uploadIdenticon(u.seaweedfs, id, profileUrl); err != nil {
+		return nil, errs.New(errs.ErrCreateUser, "cannot generate profile of user from %s auth", provider, err)
+	}
 
 	user := &domain.User{
-		Id:          uuid.NewString(),
-		Email:       email,
-		Password:    "",
-		DisplayName: name,
-		ProfileUrl:  "",
-		Type:        domain.FreeAccount,
-		Provider:    domain.GoogleAuth,
-		CreatedAt:   time.Now(),
+		Id:              id,
+		Email:           email,
+		Password:        "",
+		DisplayName:     name,
+		ProfileUrl:      profileUrl,
+		Type:            domain.FreeAccount,
+		Provider:        provider,
+		IsEmailVerified: isEmailVerified,
+		CreatedAt:       time.Now(),
 	}
 
 	if err := u.userRepository.Create(user); err != nil {
-		return nil, errs.New(errs.ErrCreateUser, "cannot create user from google auth email %s", email, err)
+		return nil, errs.New(errs.ErrCreateUser, "cannot create user from %s auth email %s", provider, email, err)
 	}
 	return user, nil
 }
@@ -116,6 +162,16 @@ func (u *userUsecase) GetByEmail(email string, provider domain.AuthProvider) (*d
 	return user, nil
 }
 
+func (u *userUsecase) IsEmailVerified(id string) (bool, error) {
+	user, err := u.Get(id)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot get user id %s to check email verification", id, err)
+	} else if user == nil {
+		return false, errs.New(errs.ErrUserNotFound, "user id %s not found", id)
+	}
+	return user.IsEmailVerified, nil
+}
+
 func (u *userUsecase) Update(userId string, uu *domain.UpdateUser) error {
 	user, err := u.Get(userId)
 	if err != nil {
@@ -129,11 +185,13 @@ func (u *userUsecase) Update(userId string, uu *domain.UpdateUser) error {
 		user.DisplayName = *uu.DisplayName
 	}
 	if uu.Profile != nil {
-		if user.ProfileUrl == "" {
-			user.ProfileUrl = fmt.Sprintf("/user/%s/profile", user.Id)
+		img, err := decodeProfile(uu.Profile)
+		if err != nil {
+			return errs.New(errs.SameCode, "cannot update profile of user id %s", userId, err)
 		}
-		if err := u.seaweedfs.Upload(uu.Profile, 0, user.ProfileUrl); err != nil {
-			return errs.New(errs.ErrUpdateUser, "cannot upload profile of user id %s", userId, err)
+		user.ProfileUrl = getUserProfileUrl(user.Id)
+		if err := uploadProfile(u.seaweedfs, img, user.ProfileUrl); err != nil {
+			return errs.New(errs.SameCode, "cannot upload profile of user id %s", userId, err)
 		}
 	}
 
@@ -143,6 +201,22 @@ func (u *userUsecase) Update(userId string, uu *domain.UpdateUser) error {
 	return nil
 }
 
+func (u *userUsecase) VerifyEmail(id string) error {
+	user, err := u.Get(id)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get user id %s to verify email", id, err)
+	} else if user == nil {
+		return errs.New(errs.ErrUserNotFound, "user id %s not found", id)
+	}
+
+	user.IsEmailVerified = true
+
+	if err := u.userRepository.Update(user); err != nil {
+		return errs.New(errs.ErrVerifyEmail, "cannot verify email of user id %s", id, err)
+	}
+	return nil
+}
+
 func (u *userUsecase) UpdatePassword(userId string, oldPassword string, newPassword string) error {
 	user, err := u.Get(userId)
 	if err != nil {
@@ -155,6 +229,25 @@ func (u *userUsecase) UpdatePassword(userId string, oldPassword string, newPassw
 		return errs.New(errs.ErrUserPassword, "cannot update password due to invalid old password", err)
 	}
 
+	return u.setPassword(user, newPassword)
+}
+
+func (u *userUsecase) ResetPassword(userId string, newPassword string) error {
+	user, err := u.Get(userId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get user id %s to reset password", userId, err)
+	} else if user == nil {
+		return errs.New(errs.ErrUserNotFound, "cannot get user id %s to reset password", userId)
+	}
+	return u.setPassword(user, newPassword)
+}
+
+// setPassword replaces the password of the user and signs the user out from every session
+func (u *userUsecase) setPassword(user *domain.User, newPassword string) error {
+	if err := validatePassword(newPassword); err != nil {
+		return errs.New(errs.SameCode, "cannot update password", err)
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 10)
 	if err != nil {
 		return errs.New(errs.ErrUpdateUser, "cannot generate new password", err)
@@ -165,9 +258,38 @@ func (u *userUsecase) UpdatePassword(userId string, oldPassword string, newPassw
 		return errs.New(errs.SameCode, "cannot update password", err)
 	}
 
-	if _, err = u.sessionUsecase.DestroyByUserId(userId); err != nil {
+	if _, err = u.sessionUsecase.DestroyByUserId(user.Id); err != nil {
 		return errs.New(errs.SameCode, "cannot destroy session while updating the password", err)
 	}
 
 	return nil
 }
+
+func getUserProfileUrl(id string) string {
+	return fmt.Sprintf("/user/%s/profile", id)
+}
+
+// validatePassword checks the password strength. bcrypt only uses the first 72 bytes,
+// so longer passwords are rejected instead of being silently truncated.
+func validatePassword(password string) error {
+	if len(password) < 8 {
+		return errs.New(errs.ErrWeakPassword, "password must be at least 8 characters")
+	}
+	if len(password) > 72 {
+		return errs.New(errs.ErrWeakPassword, "password must not exceed 72 bytes")
+	}
+
+	var hasLetter, hasDigit bool
+	for _, char := range password {
+		switch {
+		case unicode.IsLetter(char):
+			hasLetter = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errs.New(errs.ErrWeakPassword, "password must contain both letters and digits")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/platform"
+	"go.uber.org/zap"
+)
+
+type emailVerificationUsecase struct {
+	cfg                         *config.Config
+	logger                      *zap.Logger
+	mailSender                  platform.MailSender
+	emailVerificationRepository domain.EmailVerificationRepository
+	userUsecase                 domain.UserUsecase
+	rosterUsecase               domain.RosterUsecase
+}
+
+func NewEmailVerificationUsecase(
+	cfg *config.Config,
+	logger *zap.Logger,
+	mailSender platform.MailSender,
+	emailVerificationRepository domain.EmailVerificationRepository,
+	userUsecase domain.UserUsecase,
+	rosterUsecase domain.RosterUsecase,
+) domain.EmailVerificationUsecase {
+	return &emailVerificationUsecase{
+		cfg:                         cfg,
+		logger:                      logger,
+		mailSender:                  mailSender,
+		emailVerificationRepository: emailVerificationRepository,
+		userUsecase:                 userUsecase,
+		rosterUsecase:               rosterUsecase,
+	}
+}
+
+func (u *emailVerificationUsecase) Send(userId string) error {
+	user, err := u.userUsecase.Get(userId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get user id %s to send email verification", userId, err)
+	} else if user == nil {
+		return errs.New(errs.ErrUserNotFound, "user id %s not found", userId)
+	} else if user.IsEmailVerified {
+		return errs.New(errs.ErrEmailVerified, "email of user id %s is already verified", userId)
+	}
+
+	token, err := generator.RandToken(32)
+	if err != nil {
+		return errs.New(errs.ErrCreateVerification, "cannot generate email verification token", err)
+	}
+
+	// Only the latest token is valid
+	if err := u.emailVerificationRepository.DeleteByUserId(userId); err != nil {
+		return errs.New(errs.ErrCreateVerification, "cannot delete previous email verification of user id %s", userId, err)
+	}
+
+	createdAt := time.Now()
+	verification := &domain.EmailVerification{
+		Id:        generator.HashToken(token),
+		UserId:    user.Id,
+		Email:     user.Email,
+		CreatedAt: createdAt,
+		ExpiredAt: createdAt.Add(constant.EmailVerificationMaxAge),
+	}
+	if err := u.emailVerificationRepository.Create(verification); err != nil {
+		return errs.New(errs.ErrCreateVerification, "cannot create email verification of user id %s", userId, err)
+	}
+
+	link, err := url.JoinPath(u.cfg.Client.Frontend.BaseUrl, u.cfg.Client.Frontend.Path.VerifyEmail)
+	if err != nil {
+		return errs.New(errs.ErrCreateUrlPath, "invalid email verification url", err)
+	}
+	link = link + "?" + url.Values{"token": {token}}.Encode()
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nPlease verify your email address by opening the link below.\n\n%s\n\nThe link will expire in %s.",
+		user.DisplayName, link, constant.EmailVerificationMaxAge,
+	)
+	if err := u.mailSender.Send(user.Email, "Verify your email address", body); err != nil {
+		return errs.New(errs.ErrSendMail, "cannot send email verification to user id %s", userId, err)
+	}
+	return nil
+}
+
+func (u *emailVerificationUsecase) Verify(token string) error {
+	verification, err := u.emailVerificationRepository.Get(generator.HashToken(token))
+	if err != nil {
+		return errs.New(errs.ErrVerifyEmail, "cannot get email verification", err)
+	} else if verification == nil {
+		return errs.New(errs.ErrVerificationToken, "email verification token is invalid")
+	}
+
+	if !time.Now().Before(verification.ExpiredAt) {
+		return errs.New(errs.ErrVerificationExpired, "email verification token expired")
+	}
+
+	user, err := u.userUsecase.Get(verification.UserId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get user id %s to verify email", verification.UserId, err)
+	} else if user == nil {
+		return errs.New(errs.ErrUserNotFound, "user id %s not found", verification.UserId)
+	} else if user.Email != verification.Email {
+		return errs.New(errs.ErrVerificationToken, "email verification token was issued for another email")
+	}
+
+	if err := u.userUsecase.VerifyEmail(user.Id); err != nil {
+		return errs.New(errs.SameCode, "cannot verify email", err)
+	}
+
+	if err := u.emailVerificationRepository.DeleteByUserId(user.Id); err != nil {
+		return errs.New(errs.ErrVerifyEmail, "cannot delete email verification of user id %s", user.Id, err)
+	}
+
+	// Workspaces that imported the email are joined once it is verified
+	go func() {
+		if err := u.rosterUsecase.Claim(user.Id); err != nil {
+			u.logger.Error("Cannot claim pending invitation", zap.String("user_id", user.Id), zap.Error(err))
+		}
+	}()
+
+	return nil
+}
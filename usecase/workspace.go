@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/codern-org/codern/domain"
@@ -12,10 +14,12 @@ import (
 )
 
 type workspaceUsecase struct {
-	seaweedfs           *platform.SeaweedFs
-	workspaceRepository domain.WorkspaceRepository
-	userRepository      domain.UserRepository
-	userUsecase         domain.UserUsecase
+	seaweedfs            *platform.SeaweedFs
+	workspaceRepository  domain.WorkspaceRepository
+	userRepository       domain.UserRepository
+	userUsecase          domain.UserUsecase
+	quotaUsecase         domain.QuotaUsecase
+	customRoleRepository domain.CustomRoleRepository
 }
 
 func NewWorkspaceUsecase(
@@ -23,12 +27,16 @@ func NewWorkspaceUsecase(
 	workspaceRepository domain.WorkspaceRepository,
 	userRepository domain.UserRepository,
 	userUsecase domain.UserUsecase,
+	quotaUsecase domain.QuotaUsecase,
+	customRoleRepository domain.CustomRoleRepository,
 ) domain.WorkspaceUsecase {
 	return &workspaceUsecase{
-		seaweedfs:           seaweedfs,
-		workspaceRepository: workspaceRepository,
-		userRepository:      userRepository,
-		userUsecase:         userUsecase,
+		seaweedfs:            seaweedfs,
+		workspaceRepository:  workspaceRepository,
+		userRepository:       userRepository,
+		userUsecase:          userUsecase,
+		quotaUsecase:         quotaUsecase,
+		customRoleRepository: customRoleRepository,
 	}
 }
 
@@ -38,19 +46,27 @@ func (u *workspaceUsecase) Create(creatorId string, cw *domain.CreateWorkspace)
 		return nil, errs.New(errs.SameCode, "cannot get creator id %s role while creating workspace", creatorId, err)
 	} else if creator == nil {
 		return nil, errs.New(errs.ErrWorkspaceNoPerm, "cannot get role of creator id %s", creatorId)
-	} else if creator.Type != domain.ProAccount {
-		return nil, errs.New(errs.ErrWorkspaceNoPerm, "unable to create workspace since creator id %s has %s account", creatorId, creator.Type)
+	}
+
+	maxOwned, err := u.quotaUsecase.CheckOwnedWorkspace(creatorId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "unable to create workspace for creator id %s", creatorId, err)
 	}
 
 	id := generator.GetId()
 
-	var profilePath string
+	profilePath := getWorkspaceProfileUrl(id)
 	if cw.Profile == nil {
-		profilePath = constant.DefaultProfileUrl
+		if err := uploadIdenticon(u.seaweedfs, strconv.Itoa(id), profilePath); err != nil {
+			return nil, errs.New(errs.SameCode, "cannot generate profile of workspace id %d while creating workspace", id, err)
+		}
 	} else {
-		profilePath = fmt.Sprintf("/workspaces/%d/profile", id)
-		if err := u.seaweedfs.Upload(cw.Profile, 0, profilePath); err != nil {
-			return nil, errs.New(errs.ErrCreateWorkspace, "cannot upload profile of workspace id %d while creating workspace", id, err)
+		img, err := decodeProfile(cw.Profile)
+		if err != nil {
+			return nil, errs.New(errs.SameCode, "cannot create workspace id %d", id, err)
+		}
+		if err := uploadProfile(u.seaweedfs, img, profilePath); err != nil {
+			return nil, errs.New(errs.SameCode, "cannot upload profile of workspace id %d while creating workspace", id, err)
 		}
 	}
 
@@ -63,11 +79,16 @@ func (u *workspaceUsecase) Create(creatorId string, cw *domain.CreateWorkspace)
 		OwnerProfileUrl:  creator.ProfileUrl,
 		ParticipantCount: 0,
 		TotalAssignment:  0,
-		IsOpenScoreboard: false,
+		IsOpenScoreboard: cw.IsOpenScoreboard,
+		IsJoinApproval:   cw.IsJoinApproval,
+		IsDiscoverable:   cw.IsDiscoverable,
 	}
 
-	if err := u.workspaceRepository.Create(creator.Id, workspace); err != nil {
+	isCreated, err := u.workspaceRepository.Create(creator.Id, workspace, maxOwned)
+	if err != nil {
 		return nil, errs.New(errs.ErrCreateWorkspace, "cannot create workspace: ", workspace, err)
+	} else if !isCreated {
+		return nil, errs.New(errs.ErrWorkspaceQuota, "creator id %s can own up to %d workspaces", creatorId, maxOwned)
 	}
 	return workspace, nil
 }
@@ -75,22 +96,45 @@ func (u *workspaceUsecase) Create(creatorId string, cw *domain.CreateWorkspace)
 func (u *workspaceUsecase) CreateInvitation(
 	workspaceId int,
 	inviterId string,
-	validAt time.Time,
-	validUntil time.Time,
+	ci *domain.CreateInvitation,
 ) (string, error) {
-	inviterRole, err := u.workspaceRepository.GetRole(inviterId, workspaceId)
+	isAuthorized, err := u.CheckPermission(inviterId, workspaceId, domain.ManageInvitationPerm)
 	if err != nil {
-		return "", errs.New(errs.SameCode, "cannot get inviter id %s role while creating invitation", inviterId, err)
-	} else if inviterRole == nil {
-		return "", errs.New(errs.ErrInvitationNoPerm, "cannot get role of inviter id %s", inviterId)
-	} else if *inviterRole != domain.OwnerRole && *inviterRole != domain.AdminRole {
+		return "", errs.New(errs.SameCode, "cannot get inviter id %s permission while creating invitation", inviterId, err)
+	} else if !isAuthorized {
 		return "", errs.New(errs.ErrInvitationNoPerm, "inviter id %s has no permission to create invitation", inviterId)
 	}
 
-	if validAt.After(validUntil) {
+	if ci.ValidAt.After(ci.ValidUntil) {
 		return "", errs.New(errs.ErrCreateInvitation, "valid at date must be before valid until date")
 	}
 
+	role := ci.Role
+	if role == "" {
+		role = domain.MemberRole
+	}
+	if role != domain.MemberRole && role != domain.AdminRole {
+		return "", errs.New(errs.ErrInvalidRole, "invitation role must be MEMBER or ADMIN")
+	}
+	if role == domain.AdminRole {
+		isOwner, err := u.CheckPermRole(inviterId, workspaceId, []domain.WorkspaceRole{domain.OwnerRole})
+		if err != nil {
+			return "", errs.New(errs.SameCode, "cannot get inviter id %s role while creating invitation", inviterId, err)
+		} else if !isOwner {
+			return "", errs.New(errs.ErrInvitationNoPerm, "only the owner can invite admins")
+		}
+	}
+
+	var allowedDomain *string
+	if ci.AllowedDomain != nil {
+		domainName := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(*ci.AllowedDomain), "@"))
+		allowedDomain = &domainName
+	}
+	emails := make([]string, 0, len(ci.Emails))
+	for _, email := range ci.Emails {
+		emails = append(emails, strings.ToLower(strings.TrimSpace(email)))
+	}
+
 	var id string
 	for {
 		id = generator.RandStr(constant.MaxInvitationCodeChar)
@@ -103,12 +147,16 @@ func (u *workspaceUsecase) CreateInvitation(
 	}
 
 	invitation := &domain.WorkspaceInvitation{
-		Id:          id,
-		WorkspaceId: workspaceId,
-		InviterId:   inviterId,
-		CreatedAt:   time.Now(),
-		ValidAt:     validAt,
-		ValidUntil:  validUntil,
+		Id:            id,
+		WorkspaceId:   workspaceId,
+		InviterId:     inviterId,
+		CreatedAt:     time.Now(),
+		ValidAt:       ci.ValidAt,
+		ValidUntil:    ci.ValidUntil,
+		Role:          role,
+		MaxUses:       ci.MaxUses,
+		AllowedDomain: allowedDomain,
+		Emails:        emails,
 	}
 
 	if err = u.workspaceRepository.CreateInvitation(invitation); err != nil {
@@ -132,6 +180,11 @@ func (u *workspaceUsecase) CreateParticipant(workspaceId int, userId string, rol
 		return errs.New(errs.ErrWorkspaceAlreadyJoin, "user id %s is already in workspace", userId)
 	}
 
+	maxParticipants, err := u.quotaUsecase.CheckParticipant(workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot add user id %s to workspace", userId, err)
+	}
+
 	participant := &domain.WorkspaceParticipant{
 		WorkspaceId: workspaceId,
 		UserId:      userId,
@@ -139,16 +192,30 @@ func (u *workspaceUsecase) CreateParticipant(workspaceId int, userId string, rol
 		Favorite:    false,
 	}
 
-	if err := u.workspaceRepository.CreateParticipant(participant); err != nil {
+	isCreated, err := u.workspaceRepository.CreateParticipant(participant, maxParticipants)
+	if err != nil {
 		return errs.New(errs.ErrCreateWorkspaceParticipant, "cannot create participant", err)
+	} else if !isCreated {
+		return errs.New(
+			errs.ErrParticipantQuota,
+			"workspace id %d has reached the limit of %d participants", workspaceId, maxParticipants,
+		)
 	}
 	return nil
 }
 
-func (u *workspaceUsecase) JoinByInvitation(
+// ValidateInvitation checks that the user can redeem the invitation without redeeming it
+func (u *workspaceUsecase) ValidateInvitation(
 	userId string,
 	invitationCode string,
-) (*domain.Workspace, error) {
+) (*domain.WorkspaceInvitation, error) {
+	isEmailVerified, err := u.userUsecase.IsEmailVerified(userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check email verification of user id %s while joining", userId, err)
+	} else if !isEmailVerified {
+		return nil, errs.New(errs.ErrEmailNotVerified, "user id %s must verify email before joining workspace", userId)
+	}
+
 	invitation, err := u.GetInvitation(invitationCode)
 	if err != nil {
 		return nil, errs.New(errs.SameCode, "cannot get invitation id %s while joining", invitationCode, err)
@@ -162,19 +229,61 @@ func (u *workspaceUsecase) JoinByInvitation(
 	if invitation.ValidUntil.Before(time.Now()) {
 		return nil, errs.New(errs.ErrInvitationInvalidDate, "invitation id %s is expired", invitationCode)
 	}
+	if invitation.MaxUses != nil && invitation.Uses >= *invitation.MaxUses {
+		return nil, errs.New(errs.ErrInvitationExhausted, "invitation id %s has no use left", invitationCode)
+	}
 
-	err = u.CreateParticipant(invitation.WorkspaceId, userId, domain.MemberRole)
-	if errs.HasCode(err, errs.ErrWorkspaceAlreadyJoin) {
+	user, err := u.userUsecase.Get(userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get user id %s while joining", userId, err)
+	} else if user == nil {
+		return nil, errs.New(errs.ErrUserNotFound, "user id %s not found while joining", userId)
+	} else if !invitation.IsAllowed(user.Email) {
+		return nil, errs.New(errs.ErrInvitationEmail, "email of user id %s is not allowed by invitation id %s", userId, invitationCode)
+	}
+
+	isUserAlreadyJoined, err := u.HasUser(userId, invitation.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot check if user id %s is in workspace while joining", userId, err)
+	} else if isUserAlreadyJoined {
 		return nil, errs.New(errs.ErrWorkspaceAlreadyJoin, "user id %s is already in workspace", userId)
-	} else if err != nil {
-		return nil, errs.New(errs.SameCode, "cannot create participant while joining", err)
 	}
 
-	workspace, err := u.Get(invitation.WorkspaceId, userId)
+	return invitation, nil
+}
+
+// RedeemInvitation takes a use of the invitation and adds the user to its workspace
+func (u *workspaceUsecase) RedeemInvitation(userId string, invitation *domain.WorkspaceInvitation) error {
+	// The use is taken before joining so concurrent joins cannot go over the limit
+	isUsed, err := u.workspaceRepository.UseInvitation(invitation.Id)
 	if err != nil {
-		return nil, errs.New(errs.SameCode, "cannot get workspace while joining", err)
+		return errs.New(errs.ErrInvitationRedemption, "cannot use invitation id %s", invitation.Id, err)
+	} else if !isUsed {
+		return errs.New(errs.ErrInvitationExhausted, "invitation id %s has no use left", invitation.Id)
 	}
-	return workspace, nil
+
+	err = u.CreateParticipant(invitation.WorkspaceId, userId, invitation.Role)
+	if err != nil {
+		if err := u.workspaceRepository.ReleaseInvitation(invitation.Id); err != nil {
+			return errs.New(errs.ErrInvitationRedemption, "cannot release invitation id %s", invitation.Id, err)
+		}
+		if errs.HasCode(err, errs.ErrWorkspaceAlreadyJoin) {
+			return errs.New(errs.ErrWorkspaceAlreadyJoin, "user id %s is already in workspace", userId)
+		}
+		return errs.New(errs.SameCode, "cannot create participant while joining", err)
+	}
+
+	if err := u.workspaceRepository.CreateRedemption(&domain.InvitationRedemption{
+		Id:           generator.GetId(),
+		InvitationId: invitation.Id,
+		WorkspaceId:  invitation.WorkspaceId,
+		UserId:       userId,
+		Role:         invitation.Role,
+		RedeemedAt:   time.Now(),
+	}); err != nil {
+		return errs.New(errs.ErrInvitationRedemption, "cannot log redemption of invitation id %s", invitation.Id, err)
+	}
+	return nil
 }
 
 func (u *workspaceUsecase) HasUser(userId string, workspaceId int) (bool, error) {
@@ -227,8 +336,8 @@ func (u *workspaceUsecase) GetRole(
 	return userRole, nil
 }
 
-func (u *workspaceUsecase) GetScoreboard(workspaceId int) ([]domain.WorkspaceRank, error) {
-	scoreboard, err := u.workspaceRepository.GetScoreboard(workspaceId)
+func (u *workspaceUsecase) GetScoreboard(workspaceId int, groupId *int) ([]domain.WorkspaceRank, error) {
+	scoreboard, err := u.workspaceRepository.GetScoreboard(workspaceId, groupId)
 	if err != nil {
 		return nil, errs.New(errs.ErrGetScoreboard, "cannot get scoreboard", err)
 	}
@@ -243,7 +352,14 @@ func (u *workspaceUsecase) GetInvitation(id string) (*domain.WorkspaceInvitation
 	return invitation, nil
 }
 
-func (u *workspaceUsecase) GetInvitations(workspaceId int) ([]domain.WorkspaceInvitation, error) {
+func (u *workspaceUsecase) GetInvitations(userId string, workspaceId int) ([]domain.WorkspaceInvitation, error) {
+	isAuthorized, err := u.CheckPermission(userId, workspaceId, domain.ManageInvitationPerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get permission of user id %s while getting invitations", userId, err)
+	} else if !isAuthorized {
+		return nil, errs.New(errs.ErrInvitationNoPerm, "user id %s has no permission to get invitations", userId)
+	}
+
 	invitations, err := u.workspaceRepository.GetInvitations(workspaceId)
 	if err != nil {
 		return nil, errs.New(errs.ErrGetInvitation, "cannot get invitations in workspace id %d", workspaceId, err)
@@ -251,6 +367,8 @@ func (u *workspaceUsecase) GetInvitations(workspaceId int) ([]domain.WorkspaceIn
 	return invitations, nil
 }
 
+// CheckPerm tells whether the user is staff of the workspace, actions covered
+// by a permission are checked with CheckPermission instead
 func (u *workspaceUsecase) CheckPerm(userId string, workspaceId int) (bool, error) {
 	userRole, err := u.GetRole(userId, workspaceId)
 	if err != nil {
@@ -259,6 +377,44 @@ func (u *workspaceUsecase) CheckPerm(userId string, workspaceId int) (bool, erro
 	return ((userRole != nil) && (*userRole == domain.AdminRole || *userRole == domain.OwnerRole)), nil
 }
 
+func (u *workspaceUsecase) ListRedemption(userId string, workspaceId int) ([]domain.InvitationRedemption, error) {
+	isAuthorized, err := u.CheckPermission(userId, workspaceId, domain.ManageInvitationPerm)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get permission of user id %s while listing redemption", userId, err)
+	} else if !isAuthorized {
+		return nil, errs.New(errs.ErrInvitationNoPerm, "user id %s has no permission to list redemption", userId)
+	}
+
+	redemptions, err := u.workspaceRepository.ListRedemption(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrInvitationRedemption, "cannot list redemption in workspace id %d", workspaceId, err)
+	}
+	return redemptions, nil
+}
+
+// GetPermissions returns the permissions of the participant, nil if the user is not in the workspace
+func (u *workspaceUsecase) GetPermissions(userId string, workspaceId int) (domain.WorkspacePermissions, error) {
+	role, err := u.customRoleRepository.GetParticipantRole(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetRole, "cannot get permission of user id %s", userId, err)
+	} else if role == nil {
+		return nil, nil
+	}
+	return role.Permissions(), nil
+}
+
+func (u *workspaceUsecase) CheckPermission(
+	userId string,
+	workspaceId int,
+	permission domain.WorkspacePermission,
+) (bool, error) {
+	permissions, err := u.GetPermissions(userId, workspaceId)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot get workspace permission for checking perms", err)
+	}
+	return permissions.Has(permission), nil
+}
+
 func (u *workspaceUsecase) CheckPermRole(userId string, workspaceId int, roles []domain.WorkspaceRole) (bool, error) {
 	userRole, err := u.GetRole(userId, workspaceId)
 	if err != nil {
@@ -281,6 +437,14 @@ func (u *workspaceUsecase) List(userId string) ([]domain.Workspace, error) {
 	return workspaces, nil
 }
 
+func (u *workspaceUsecase) ListDiscoverable(userId string) ([]domain.RawWorkspace, error) {
+	workspaces, err := u.workspaceRepository.ListDiscoverable(userId)
+	if err != nil {
+		return nil, errs.New(errs.ErrListWorkspace, "cannot list discoverable workspace", err)
+	}
+	return workspaces, nil
+}
+
 func (u *workspaceUsecase) ListParticipant(workspaceId int) ([]domain.WorkspaceParticipant, error) {
 	participants, err := u.workspaceRepository.ListParticipant(workspaceId)
 	if err != nil {
@@ -290,7 +454,7 @@ func (u *workspaceUsecase) ListParticipant(workspaceId int) ([]domain.WorkspaceP
 }
 
 func (u *workspaceUsecase) Update(userId string, workspaceId int, uw *domain.UpdateWorkspace) error {
-	isAuthorized, err := u.CheckPermRole(userId, workspaceId, []domain.WorkspaceRole{domain.OwnerRole, domain.AdminRole})
+	isAuthorized, err := u.CheckPermission(userId, workspaceId, domain.ManageWorkspacePerm)
 	if err != nil {
 		return errs.New(errs.SameCode, "cannot get workspace role while updating workspace", err)
 	}
@@ -308,11 +472,14 @@ func (u *workspaceUsecase) Update(userId string, workspaceId int, uw *domain.Upd
 	}
 
 	if uw.Profile != nil {
-		if workspace.ProfileUrl == constant.DefaultProfileUrl {
-			workspace.ProfileUrl = fmt.Sprintf("/workspaces/%d/profile", workspaceId)
+		img, err := decodeProfile(uw.Profile)
+		if err != nil {
+			return errs.New(errs.SameCode, "cannot update profile of workspace id %d", workspaceId, err)
 		}
-		if err := u.seaweedfs.Upload(uw.Profile, 0, workspace.ProfileUrl); err != nil {
-			return errs.New(errs.ErrUpdateWorkspace, "cannot upload profile of workspace id %d while updating workspace", workspaceId, err)
+		// Workspaces created before generated profiles still point to the shared default one
+		workspace.ProfileUrl = getWorkspaceProfileUrl(workspaceId)
+		if err := uploadProfile(u.seaweedfs, img, workspace.ProfileUrl); err != nil {
+			return errs.New(errs.SameCode, "cannot upload profile of workspace id %d while updating workspace", workspaceId, err)
 		}
 	}
 	if uw.Archive != nil {
@@ -326,6 +493,12 @@ func (u *workspaceUsecase) Update(userId string, workspaceId int, uw *domain.Upd
 		}
 		workspace.IsArchived = *uw.Archive
 	}
+	if uw.JoinApproval != nil {
+		workspace.IsJoinApproval = *uw.JoinApproval
+	}
+	if uw.Discoverable != nil {
+		workspace.IsDiscoverable = *uw.Discoverable
+	}
 
 	if err := u.workspaceRepository.Update(userId, workspace); err != nil {
 		return errs.New(errs.ErrUpdateWorkspace, "cannot update workspace id %d", workspaceId, err)
@@ -378,7 +551,7 @@ func (u *workspaceUsecase) UpdateParticipant(
 			return errs.New(errs.ErrInvalidRole, "invalid role")
 		}
 		if up.Role == domain.OwnerRole {
-			return errs.New(errs.ErrInvalidRole, "cannot update role to owner")
+			return errs.New(errs.ErrInvalidRole, "cannot update role to owner, ownership must be transferred")
 		}
 	}
 
@@ -460,3 +633,7 @@ func (u *workspaceUsecase) DeleteParticipant(workspaceId int, removerUserId stri
 	}
 	return nil
 }
+
+func getWorkspaceProfileUrl(id int) string {
+	return fmt.Sprintf("/workspaces/%d/profile", id)
+}